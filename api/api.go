@@ -0,0 +1,13 @@
+// Package api 提供对外发布的 OpenAPI 文档，请求模型由文档生成到 define 包；
+// gRPC 接口定义位于 proto 目录，生成到 fabric2v1 包
+package api
+
+import _ "embed"
+
+//go:generate go run ./gen -spec openapi.yaml -out ../define/requests.gen.go
+//go:generate protoc -I proto -I ../third_party/googleapis --go_out=.. --go_opt=module=github.com/qctc/fabric2-api-server --go-grpc_out=.. --go-grpc_opt=module=github.com/qctc/fabric2-api-server fabric2/v1/fabric2.proto
+
+// Spec OpenAPI 3 文档原文
+//
+//go:embed openapi.yaml
+var Spec []byte
@@ -0,0 +1,364 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: fabric2/v1/fabric2.proto
+
+package fabric2v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// Fabric2APIClient is the client API for Fabric2API service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type Fabric2APIClient interface {
+	// InvokeContract 调用合约并提交交易，等待交易上链后返回
+	InvokeContract(ctx context.Context, in *InvokeContractRequest, opts ...grpc.CallOption) (*ContractResult, error)
+	// QueryContract 查询合约，不提交交易
+	QueryContract(ctx context.Context, in *QueryContractRequest, opts ...grpc.CallOption) (*ContractResult, error)
+	// ListContracts 获取通道上已提交的合约
+	ListContracts(ctx context.Context, in *ListContractsRequest, opts ...grpc.CallOption) (*ListContractsResponse, error)
+	// GetContractInfo 获取合约定义
+	GetContractInfo(ctx context.Context, in *GetContractInfoRequest, opts ...grpc.CallOption) (*structpb.Struct, error)
+	// GetBlock 按区块号获取区块头
+	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*BlockInfo, error)
+	// GetTransaction 按交易 ID 获取交易
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*TransactionInfo, error)
+	// StreamEvents 推送事件，无需消息队列；last_event_id 不为空时从该游标之后继续推送
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (Fabric2API_StreamEventsClient, error)
+}
+
+type fabric2APIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFabric2APIClient(cc grpc.ClientConnInterface) Fabric2APIClient {
+	return &fabric2APIClient{cc}
+}
+
+func (c *fabric2APIClient) InvokeContract(ctx context.Context, in *InvokeContractRequest, opts ...grpc.CallOption) (*ContractResult, error) {
+	out := new(ContractResult)
+	err := c.cc.Invoke(ctx, "/fabric2.v1.Fabric2API/InvokeContract", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabric2APIClient) QueryContract(ctx context.Context, in *QueryContractRequest, opts ...grpc.CallOption) (*ContractResult, error) {
+	out := new(ContractResult)
+	err := c.cc.Invoke(ctx, "/fabric2.v1.Fabric2API/QueryContract", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabric2APIClient) ListContracts(ctx context.Context, in *ListContractsRequest, opts ...grpc.CallOption) (*ListContractsResponse, error) {
+	out := new(ListContractsResponse)
+	err := c.cc.Invoke(ctx, "/fabric2.v1.Fabric2API/ListContracts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabric2APIClient) GetContractInfo(ctx context.Context, in *GetContractInfoRequest, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	err := c.cc.Invoke(ctx, "/fabric2.v1.Fabric2API/GetContractInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabric2APIClient) GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*BlockInfo, error) {
+	out := new(BlockInfo)
+	err := c.cc.Invoke(ctx, "/fabric2.v1.Fabric2API/GetBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabric2APIClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*TransactionInfo, error) {
+	out := new(TransactionInfo)
+	err := c.cc.Invoke(ctx, "/fabric2.v1.Fabric2API/GetTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabric2APIClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (Fabric2API_StreamEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Fabric2API_ServiceDesc.Streams[0], "/fabric2.v1.Fabric2API/StreamEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fabric2APIStreamEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Fabric2API_StreamEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type fabric2APIStreamEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *fabric2APIStreamEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Fabric2APIServer is the server API for Fabric2API service.
+// All implementations must embed UnimplementedFabric2APIServer
+// for forward compatibility
+type Fabric2APIServer interface {
+	// InvokeContract 调用合约并提交交易，等待交易上链后返回
+	InvokeContract(context.Context, *InvokeContractRequest) (*ContractResult, error)
+	// QueryContract 查询合约，不提交交易
+	QueryContract(context.Context, *QueryContractRequest) (*ContractResult, error)
+	// ListContracts 获取通道上已提交的合约
+	ListContracts(context.Context, *ListContractsRequest) (*ListContractsResponse, error)
+	// GetContractInfo 获取合约定义
+	GetContractInfo(context.Context, *GetContractInfoRequest) (*structpb.Struct, error)
+	// GetBlock 按区块号获取区块头
+	GetBlock(context.Context, *GetBlockRequest) (*BlockInfo, error)
+	// GetTransaction 按交易 ID 获取交易
+	GetTransaction(context.Context, *GetTransactionRequest) (*TransactionInfo, error)
+	// StreamEvents 推送事件，无需消息队列；last_event_id 不为空时从该游标之后继续推送
+	StreamEvents(*StreamEventsRequest, Fabric2API_StreamEventsServer) error
+	mustEmbedUnimplementedFabric2APIServer()
+}
+
+// UnimplementedFabric2APIServer must be embedded to have forward compatible implementations.
+type UnimplementedFabric2APIServer struct {
+}
+
+func (UnimplementedFabric2APIServer) InvokeContract(context.Context, *InvokeContractRequest) (*ContractResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InvokeContract not implemented")
+}
+func (UnimplementedFabric2APIServer) QueryContract(context.Context, *QueryContractRequest) (*ContractResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryContract not implemented")
+}
+func (UnimplementedFabric2APIServer) ListContracts(context.Context, *ListContractsRequest) (*ListContractsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListContracts not implemented")
+}
+func (UnimplementedFabric2APIServer) GetContractInfo(context.Context, *GetContractInfoRequest) (*structpb.Struct, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContractInfo not implemented")
+}
+func (UnimplementedFabric2APIServer) GetBlock(context.Context, *GetBlockRequest) (*BlockInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (UnimplementedFabric2APIServer) GetTransaction(context.Context, *GetTransactionRequest) (*TransactionInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedFabric2APIServer) StreamEvents(*StreamEventsRequest, Fabric2API_StreamEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedFabric2APIServer) mustEmbedUnimplementedFabric2APIServer() {}
+
+// UnsafeFabric2APIServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to Fabric2APIServer will
+// result in compilation errors.
+type UnsafeFabric2APIServer interface {
+	mustEmbedUnimplementedFabric2APIServer()
+}
+
+func RegisterFabric2APIServer(s grpc.ServiceRegistrar, srv Fabric2APIServer) {
+	s.RegisterService(&Fabric2API_ServiceDesc, srv)
+}
+
+func _Fabric2API_InvokeContract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvokeContractRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fabric2APIServer).InvokeContract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabric2.v1.Fabric2API/InvokeContract",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fabric2APIServer).InvokeContract(ctx, req.(*InvokeContractRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric2API_QueryContract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryContractRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fabric2APIServer).QueryContract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabric2.v1.Fabric2API/QueryContract",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fabric2APIServer).QueryContract(ctx, req.(*QueryContractRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric2API_ListContracts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListContractsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fabric2APIServer).ListContracts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabric2.v1.Fabric2API/ListContracts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fabric2APIServer).ListContracts(ctx, req.(*ListContractsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric2API_GetContractInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetContractInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fabric2APIServer).GetContractInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabric2.v1.Fabric2API/GetContractInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fabric2APIServer).GetContractInfo(ctx, req.(*GetContractInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric2API_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fabric2APIServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabric2.v1.Fabric2API/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fabric2APIServer).GetBlock(ctx, req.(*GetBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric2API_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fabric2APIServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabric2.v1.Fabric2API/GetTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fabric2APIServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric2API_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(Fabric2APIServer).StreamEvents(m, &fabric2APIStreamEventsServer{stream})
+}
+
+type Fabric2API_StreamEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type fabric2APIStreamEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *fabric2APIStreamEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Fabric2API_ServiceDesc is the grpc.ServiceDesc for Fabric2API service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Fabric2API_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fabric2.v1.Fabric2API",
+	HandlerType: (*Fabric2APIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "InvokeContract",
+			Handler:    _Fabric2API_InvokeContract_Handler,
+		},
+		{
+			MethodName: "QueryContract",
+			Handler:    _Fabric2API_QueryContract_Handler,
+		},
+		{
+			MethodName: "ListContracts",
+			Handler:    _Fabric2API_ListContracts_Handler,
+		},
+		{
+			MethodName: "GetContractInfo",
+			Handler:    _Fabric2API_GetContractInfo_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _Fabric2API_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _Fabric2API_GetTransaction_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _Fabric2API_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "fabric2/v1/fabric2.proto",
+}
@@ -0,0 +1,168 @@
+// gen 根据 OpenAPI 文档中标记了 x-go-model 的 schema 生成 define 包中的请求模型
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const header = `// Code generated by api/gen from api/openapi.yaml; DO NOT EDIT.
+
+package define
+`
+
+func main() {
+	specFile := flag.String("spec", "openapi.yaml", "OpenAPI 文档路径")
+	outFile := flag.String("out", "", "生成文件路径")
+	flag.Parse()
+
+	data, err := os.ReadFile(*specFile)
+	if err != nil {
+		log.Fatalf("读取文档失败: %v", err)
+	}
+	src, err := generate(data)
+	if err != nil {
+		log.Fatalf("生成模型失败: %v", err)
+	}
+	if err := os.WriteFile(*outFile, src, 0o644); err != nil {
+		log.Fatalf("写入文件失败: %v", err)
+	}
+}
+
+// generate 按 schema 在文档中的顺序生成结构体，字段顺序与 properties 一致
+func generate(data []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	schemas := lookup(lookup(root.Content[0], "components"), "schemas")
+	if schemas == nil {
+		return nil, fmt.Errorf("components.schemas not found")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		name, schema := schemas.Content[i].Value, schemas.Content[i+1]
+		if model := lookup(schema, "x-go-model"); model == nil || model.Value != "true" {
+			continue
+		}
+		if err := writeStruct(&buf, schemas, name, schema); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+	return format.Source(buf.Bytes())
+}
+
+func writeStruct(buf *bytes.Buffer, schemas *yaml.Node, name string, schema *yaml.Node) error {
+	buf.WriteString("\n")
+	if desc := lookup(schema, "description"); desc != nil {
+		fmt.Fprintf(buf, "// %s %s\n", name, strings.TrimSpace(desc.Value))
+	}
+	fmt.Fprintf(buf, "type %s struct {\n", name)
+	properties := lookup(schema, "properties")
+	if properties == nil {
+		return fmt.Errorf("properties not found")
+	}
+	for i := 0; i+1 < len(properties.Content); i += 2 {
+		jsonName, property := properties.Content[i].Value, properties.Content[i+1]
+		goType, err := resolveType(schemas, property)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", jsonName, err)
+		}
+		fieldName := exported(jsonName)
+		if goName := lookup(property, "x-go-name"); goName != nil {
+			fieldName = goName.Value
+		}
+		fmt.Fprintf(buf, "\t%s %s `json:%q`", fieldName, goType, jsonName)
+		if desc := lookup(property, "description"); desc != nil {
+			fmt.Fprintf(buf, " // %s", strings.TrimSpace(desc.Value))
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	return nil
+}
+
+// resolveType 将 schema 类型映射为 Go 类型，$ref 指向标记了 x-go-model 的 schema 时使用生成的结构体，
+// 其他 schema 按其基础类型处理
+func resolveType(schemas, schema *yaml.Node) (string, error) {
+	if ref := lookup(schema, "$ref"); ref != nil {
+		name := strings.TrimPrefix(ref.Value, "#/components/schemas/")
+		target := lookup(schemas, name)
+		if target == nil {
+			return "", fmt.Errorf("unresolved reference %s", ref.Value)
+		}
+		if model := lookup(target, "x-go-model"); model != nil && model.Value == "true" {
+			return name, nil
+		}
+		return resolveType(schemas, target)
+	}
+	typ := lookup(schema, "type")
+	if typ == nil {
+		return "", fmt.Errorf("type not specified")
+	}
+	switch typ.Value {
+	case "string":
+		// base64 编码的二进制内容，encoding/json 直接解码为 []byte
+		if f := lookup(schema, "format"); f != nil && f.Value == "byte" {
+			return "[]byte", nil
+		}
+		return "string", nil
+	case "boolean":
+		return "bool", nil
+	case "integer":
+		if f := lookup(schema, "format"); f != nil && (f.Value == "uint64" || f.Value == "int64") {
+			return f.Value, nil
+		}
+		return "int", nil
+	case "array":
+		items := lookup(schema, "items")
+		if items == nil {
+			return "", fmt.Errorf("array items not specified")
+		}
+		elem, err := resolveType(schemas, items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case "object":
+		// 以 additionalProperties 描述值类型的对象生成为 map
+		values := lookup(schema, "additionalProperties")
+		if values == nil || values.Kind != yaml.MappingNode {
+			return "", fmt.Errorf("object additionalProperties not specified")
+		}
+		elem, err := resolveType(schemas, values)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + elem, nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", typ.Value)
+	}
+}
+
+// lookup 查找映射节点中的键
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// exported 将 JSON 字段名转换为导出的 Go 字段名
+func exported(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
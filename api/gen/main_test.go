@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// TestGeneratedModelsUpToDate 修改 openapi.yaml 后需执行 go generate ./api 重新生成模型
+func TestGeneratedModelsUpToDate(t *testing.T) {
+	spec, err := os.ReadFile("../openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := generate(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../../define/requests.gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("define/requests.gen.go is out of date, run go generate ./api")
+	}
+}
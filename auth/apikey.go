@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+// APIKeyHeader 携带 API Key 的请求头
+const APIKeyHeader = "X-API-Key"
+
+type apiKey struct {
+	name   string
+	digest []byte
+}
+
+// APIKeyAuthenticator 按 Key 的 SHA-256 摘要校验静态 API Key
+type APIKeyAuthenticator struct {
+	keys []apiKey
+}
+
+// NewAPIKeyAuthenticator 从配置加载 API Key 摘要
+func NewAPIKeyAuthenticator(configs []define.APIKeyConfig) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{}
+	for _, c := range configs {
+		if c.Name == "" {
+			return nil, errors.New("api key name is required")
+		}
+		digest, err := hex.DecodeString(c.SHA256)
+		if err != nil || len(digest) != sha256.Size {
+			return nil, fmt.Errorf("api key %s: sha256 must be a hex encoded SHA-256 digest", c.Name)
+		}
+		a.keys = append(a.keys, apiKey{name: c.Name, digest: digest})
+	}
+	return a, nil
+}
+
+// Authenticate 请求未携带 API Key 时返回 nil, nil
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		if scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "ApiKey") {
+			key = strings.TrimSpace(value)
+		}
+	}
+	if key == "" {
+		return nil, nil
+	}
+	digest := sha256.Sum256([]byte(key))
+	// 逐个比较全部 Key，避免通过响应时间推断匹配位置
+	var name string
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare(digest[:], k.digest) == 1 {
+			name = k.name
+		}
+	}
+	if name == "" {
+		return nil, errors.New("invalid api key")
+	}
+	return &Principal{Method: MethodAPIKey, Name: name}, nil
+}
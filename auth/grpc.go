@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"gitee.com/china_uni/tjfoc-gm/tls/gmcredentials"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor 认证 gRPC 调用方，授权由各方法取得请求参数后调用 Authorize 完成
+func (g *Guard) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := g.authenticateGRPC(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 同 UnaryServerInterceptor，用于服务端流式方法
+func (g *Guard) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := g.authenticateGRPC(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (g *Guard) authenticateGRPC(ctx context.Context) (context.Context, error) {
+	if g == nil {
+		return ctx, nil
+	}
+	principal, err := g.authenticate(grpcRequest(ctx))
+	if err != nil {
+		addr := ""
+		if p, ok := peer.FromContext(ctx); ok {
+			addr = p.Addr.String()
+		}
+		slog.WarnContext(ctx, "authentication failed", "remote_addr", addr, "error", err)
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return g.withPrincipal(ctx, principal), nil
+}
+
+// grpcRequest 将 gRPC 元数据、对端地址与 TLS 状态转换为 http.Request，以复用各认证方式
+func grpcRequest(ctx context.Context) *http.Request {
+	r := (&http.Request{Header: http.Header{}}).WithContext(ctx)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, values := range md {
+			for _, v := range values {
+				r.Header.Add(k, v)
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+		switch info := p.AuthInfo.(type) {
+		case credentials.TLSInfo:
+			state := info.State
+			r.TLS = &state
+		case gmcredentials.TLSInfo:
+			raw := make([][]byte, 0, len(info.State.PeerCertificates))
+			for _, cert := range info.State.PeerCertificates {
+				raw = append(raw, cert.Raw)
+			}
+			r = r.WithContext(WithPeerCertificates(ctx, raw))
+		}
+	}
+	return r
+}
+
+// serverStream 替换流的上下文以携带调用方
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
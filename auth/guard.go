@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/logging"
+	"github.com/qctc/fabric2-api-server/service"
+	"github.com/qctc/fabric2-api-server/subscription"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// ErrAccessDenied 授权策略不允许访问
+var ErrAccessDenied = errors.New("access denied")
+
+// Authenticator 认证方式，请求未携带该方式的凭证时返回 nil, nil
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Guard 接口认证与授权，为 nil 时表示未启用
+type Guard struct {
+	authenticators []Authenticator
+	policy         *Policy
+}
+
+// New 按配置构造认证与授权，未启用时返回 nil
+func New(cfg define.AuthConfig) (*Guard, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	g := &Guard{}
+	if len(cfg.APIKeys) > 0 {
+		a, err := NewAPIKeyAuthenticator(cfg.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		g.authenticators = append(g.authenticators, a)
+	}
+	if cfg.JWT.JWKSFile != "" {
+		a, err := NewJWTAuthenticator(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		g.authenticators = append(g.authenticators, a)
+	}
+	if cfg.MTLS.Enabled {
+		a, err := NewCertAuthenticator(cfg.MTLS)
+		if err != nil {
+			return nil, err
+		}
+		g.authenticators = append(g.authenticators, a)
+	}
+	if len(g.authenticators) == 0 {
+		return nil, errors.New("auth is enabled but no authentication method is configured")
+	}
+	policy, err := NewPolicy(cfg.Policies)
+	if err != nil {
+		return nil, err
+	}
+	g.policy = policy
+	return g, nil
+}
+
+// Require 认证调用方并按资源授权，授权通过后才会进入处理函数（及 SDK 初始化）
+func (g *Guard) Require(action Action, next http.HandlerFunc) http.Handler {
+	if g == nil {
+		return next
+	}
+	return g.Authenticated(func(w http.ResponseWriter, r *http.Request) {
+		res, err := resourceFromRequest(action, r)
+		if err != nil {
+			utils.BadRequest(w, "Invalid request body")
+			return
+		}
+		if err := Authorize(r.Context(), res); err != nil {
+			utils.Error(w, http.StatusForbidden, err.Error(), nil)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// Authenticated 只认证调用方，授权由处理函数在取得请求参数后调用 Authorize 完成
+func (g *Guard) Authenticated(next http.HandlerFunc) http.Handler {
+	if g == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := g.authenticate(r)
+		if err != nil {
+			unauthorized(w, r, err)
+			return
+		}
+		next(w, r.WithContext(g.withPrincipal(r.Context(), principal)))
+	})
+}
+
+// Optional 认证请求携带的凭证，未携带凭证的请求作为匿名调用方进入处理函数，Authorize 拒绝匿名调用方
+func (g *Guard) Optional(next http.HandlerFunc) http.Handler {
+	if g == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := g.authenticate(r)
+		switch {
+		case errors.Is(err, errAuthenticationRequired):
+			next(w, r.WithContext(context.WithValue(r.Context(), guardKey{}, g)))
+		case err != nil:
+			unauthorized(w, r, err)
+		default:
+			next(w, r.WithContext(g.withPrincipal(r.Context(), principal)))
+		}
+	})
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	slog.WarnContext(r.Context(), "authentication failed", "remote_addr", r.RemoteAddr, "error", err)
+	w.Header().Set("WWW-Authenticate", `Bearer realm="fabric2-api-server"`)
+	utils.Error(w, http.StatusUnauthorized, err.Error(), nil)
+}
+
+// errAuthenticationRequired 请求未携带任何认证方式的凭证
+var errAuthenticationRequired = errors.New("authentication required")
+
+// authenticate 使用第一个匹配到凭证的认证方式，凭证无效时直接拒绝
+func (g *Guard) authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range g.authenticators {
+		principal, err := a.Authenticate(r)
+		if err != nil {
+			return nil, err
+		}
+		if principal != nil {
+			return principal, nil
+		}
+	}
+	return nil, errAuthenticationRequired
+}
+
+type guardKey struct{}
+
+// withPrincipal 在上下文中记录调用方与认证配置，调用方同时加入请求日志字段
+func (g *Guard) withPrincipal(ctx context.Context, principal *Principal) context.Context {
+	logging.AddFields(ctx, slog.String("caller", principal.Subject()))
+	ctx = WithPrincipal(ctx, principal)
+	return context.WithValue(ctx, guardKey{}, g)
+}
+
+// Authorize 按上下文中的调用方与授权策略校验资源访问，未启用认证时直接放行
+func Authorize(ctx context.Context, res Resource) error {
+	g, _ := ctx.Value(guardKey{}).(*Guard)
+	if g == nil {
+		return nil
+	}
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return ErrAccessDenied
+	}
+	if !g.policy.Allow(principal, res) {
+		slog.WarnContext(ctx, "access denied", "caller", principal.Subject(), "action", res.Action,
+			"profile", res.Profile, "channels", res.Channels, "chaincode", res.Chaincode, "function", res.Function)
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+// Caller 返回已认证调用方的标识，未认证时返回空字符串
+func Caller(r *http.Request) string {
+	return CallerFromContext(r.Context())
+}
+
+// CallerFromContext 同 Caller，用于 gRPC 等不经过 http.Request 的调用
+func CallerFromContext(ctx context.Context) string {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return principal.Subject()
+	}
+	return ""
+}
+
+// EventStreamResource 事件流请求访问的资源
+func EventStreamResource(req define.EventStreamRequest) Resource {
+	return ProfileResource(ActionSubscribe, req.SdkConfig, req.SdkId, req.ChaincodeName, "")
+}
+
+// requestFields 各接口请求中与授权相关的字段
+type requestFields struct {
+	SdkConfig     string `json:"sdkConfig"`
+	SdkId         string `json:"sdkId"`
+	ChaincodeName string `json:"chaincodeName"`
+	Method        string `json:"method"`
+	SubscribeId   string `json:"subscribeEventId"`
+}
+
+// resourceFromRequest 从请求体、查询参数或路径参数中提取资源，读取后恢复请求体供处理函数使用
+func resourceFromRequest(action Action, r *http.Request) (Resource, error) {
+	var fields requestFields
+	if err := utils.PeekJSON(r, &fields); err != nil {
+		return Resource{}, err
+	}
+	q := r.URL.Query()
+	if fields.SdkId == "" {
+		fields.SdkId = q.Get("sdkId")
+	}
+	if fields.ChaincodeName == "" {
+		fields.ChaincodeName = q.Get("chaincodeName")
+	}
+
+	subscribeId := mux.Vars(r)["id"]
+	if subscribeId == "" {
+		subscribeId = fields.SubscribeId
+	}
+	if subscribeId != "" {
+		return subscriptionResource(action, subscribeId), nil
+	}
+	return ProfileResource(action, fields.SdkConfig, fields.SdkId, fields.ChaincodeName, fields.Method), nil
+}
+
+// ProfileResource 按连接配置或 sdkId 确定资源所在的通道
+func ProfileResource(action Action, sdkConfig, sdkId, chaincode, function string) Resource {
+	res := Resource{Action: action, Chaincode: chaincode, Function: function}
+	switch {
+	case sdkConfig != "":
+		res.Profile = utils.SdkId(sdkConfig)
+		channels, err := service.ProfileChannels(sdkConfig)
+		if err == nil {
+			res.Channels = channels
+		}
+	case sdkId != "":
+		res.Profile = sdkId
+		if sdk := service.GetFabric2Service(sdkId); sdk != nil {
+			if channels, err := sdk.Channels(); err == nil {
+				res.Channels = channels
+			}
+		}
+	}
+	return res
+}
+
+// subscriptionResource 已有订阅对应的资源，订阅标识以 sdkId 开头
+func subscriptionResource(action Action, id string) Resource {
+	sdkId, _, _ := strings.Cut(id, ":")
+	res := Resource{Action: action, Profile: sdkId}
+	if sub, err := subscription.DefaultManager.Get(id); err == nil {
+		res.Chaincode = sub.Spec().ChaincodeName
+		res.Channels = []string{sub.ChannelId()}
+	}
+	return res
+}
@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitee.com/china_uni/tjfoc-gm/sm2"
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+const testProfile = `
+name: test
+channels:
+  mychannel:
+    peers: {}
+`
+
+func TestGuardRequire(t *testing.T) {
+	dir := t.TempDir()
+	signKey, jwksFile := writeJWKS(t, dir)
+	caFile, clientPEM := writeSM2Certs(t, dir)
+	digest := sha256.Sum256([]byte("secret"))
+
+	guard, err := New(define.AuthConfig{
+		Enabled: true,
+		APIKeys: []define.APIKeyConfig{{Name: "ops", SHA256: hex.EncodeToString(digest[:])}},
+		JWT:     define.JWTConfig{JWKSFile: jwksFile, Issuer: "test-idp"},
+		MTLS: define.MTLSConfig{
+			Enabled:          true,
+			ClientCAFile:     caFile,
+			ClientCertHeader: "X-Client-Cert",
+			TrustedProxies:   []string{"192.0.2.0/24"},
+		},
+		Policies: []define.PolicyConfig{
+			{Subjects: []string{"apikey:ops"}, Actions: []string{"*"}},
+			{Subjects: []string{"jwt:alice"}, Channels: []string{"mychannel"}, Functions: []string{"Query*"}, Actions: []string{"query"}},
+			{Subjects: []string{"cert:client1"}, Chaincodes: []string{"basic"}, Actions: []string{"query"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := guard.Require(ActionQuery, func(w http.ResponseWriter, r *http.Request) {
+		// 处理函数仍需能读取请求体
+		var req define.ContractQueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method == "" {
+			t.Errorf("request body not restored: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	aliceToken := signToken(t, signKey, jwt.MapClaims{"sub": "alice", "iss": "test-idp", "exp": time.Now().Add(time.Minute).Unix()})
+	expiredToken := signToken(t, signKey, jwt.MapClaims{"sub": "alice", "iss": "test-idp", "exp": time.Now().Add(-time.Hour).Unix()})
+
+	tests := []struct {
+		name       string
+		method     string
+		header     map[string]string
+		remoteAddr string
+		want       int
+	}{
+		{"no credentials", "QueryAsset", nil, "", http.StatusUnauthorized},
+		{"api key", "DeleteAsset", map[string]string{APIKeyHeader: "secret"}, "", http.StatusOK},
+		{"api key authorization scheme", "DeleteAsset", map[string]string{"Authorization": "ApiKey secret"}, "", http.StatusOK},
+		{"wrong api key", "QueryAsset", map[string]string{APIKeyHeader: "wrong"}, "", http.StatusUnauthorized},
+		{"jwt allowed function", "QueryAsset", map[string]string{"Authorization": "Bearer " + aliceToken}, "", http.StatusOK},
+		{"jwt denied function", "DeleteAsset", map[string]string{"Authorization": "Bearer " + aliceToken}, "", http.StatusForbidden},
+		{"expired jwt", "QueryAsset", map[string]string{"Authorization": "Bearer " + expiredToken}, "", http.StatusUnauthorized},
+		{"sm2 client certificate", "DeleteAsset", map[string]string{"X-Client-Cert": url.QueryEscape(clientPEM)}, "192.0.2.1:4000", http.StatusOK},
+		{"certificate from untrusted proxy", "DeleteAsset", map[string]string{"X-Client-Cert": url.QueryEscape(clientPEM)}, "198.51.100.1:4000", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(define.ContractQueryRequest{
+				SdkConfig:     testProfile,
+				ChaincodeName: "basic",
+				Method:        tt.method,
+			})
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/contract/call", strings.NewReader(string(body)))
+			if tt.remoteAddr != "" {
+				r.RemoteAddr = tt.remoteAddr
+			}
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGuardDisabled(t *testing.T) {
+	guard, err := New(define.AuthConfig{})
+	if err != nil || guard != nil {
+		t.Fatalf("New() = %v, %v, want nil guard", guard, err)
+	}
+	called := false
+	guard.Require(ActionInvoke, func(w http.ResponseWriter, r *http.Request) { called = true }).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+	if !called {
+		t.Error("handler not called when auth is disabled")
+	}
+}
+
+func TestGuardOptional(t *testing.T) {
+	digest := sha256.Sum256([]byte("secret"))
+	guard, err := New(define.AuthConfig{
+		Enabled:  true,
+		APIKeys:  []define.APIKeyConfig{{Name: "ops", SHA256: hex.EncodeToString(digest[:])}},
+		Policies: []define.PolicyConfig{{Subjects: []string{"apikey:ops"}, Actions: []string{"manage"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var authorized error
+	handler := guard.Optional(func(w http.ResponseWriter, r *http.Request) {
+		authorized = Authorize(r.Context(), Resource{Action: ActionManage})
+	})
+	for _, tt := range []struct {
+		name   string
+		apiKey string
+		code   int
+		denied bool
+	}{
+		{"anonymous", "", http.StatusOK, true},
+		{"authorized", "secret", http.StatusOK, false},
+		{"invalid key", "wrong", http.StatusUnauthorized, false},
+	} {
+		authorized = nil
+		r := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		if tt.apiKey != "" {
+			r.Header.Set(APIKeyHeader, tt.apiKey)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.code || errors.Is(authorized, ErrAccessDenied) != tt.denied {
+			t.Errorf("%s: status = %d, Authorize = %v", tt.name, w.Code, authorized)
+		}
+	}
+}
+
+func writeJWKS(t *testing.T, dir string) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc := func(b *big.Int) string { return base64.RawURLEncoding.EncodeToString(b.FillBytes(make([]byte, 32))) }
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{"kty": "EC", "kid": "k1", "crv": "P-256", "x": enc(key.X), "y": enc(key.Y)}},
+	})
+	file := filepath.Join(dir, "jwks.json")
+	if err := os.WriteFile(file, jwks, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return key, file
+}
+
+func signToken(t *testing.T, key *ecdsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// writeSM2Certs 生成 SM2 CA 与客户端证书，返回 CA 文件与客户端证书 PEM
+func writeSM2Certs(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	caKey, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SignatureAlgorithm:    x509.SM2WithSM3,
+	}
+	caPEM, err := x509.CreateCertificateToMem(ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(caPEM)
+	if ca, err = x509.ParseCertificate(block.Bytes); err != nil {
+		t.Fatal(err)
+	}
+
+	clientKey, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &x509.Certificate{
+		SerialNumber:       big.NewInt(2),
+		Subject:            pkix.Name{CommonName: "client1"},
+		NotBefore:          time.Now().Add(-time.Hour),
+		NotAfter:           time.Now().Add(time.Hour),
+		KeyUsage:           x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:        []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		SignatureAlgorithm: x509.SM2WithSM3,
+	}
+	clientPEM, err := x509.CreateCertificateToMem(client, ca, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caFile := filepath.Join(dir, "client-ca.pem")
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return caFile, string(clientPEM)
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+// CertAuthenticator 校验客户端证书，使用国密 x509 实现以同时支持 SM2 与 ECDSA/RSA 证书
+type CertAuthenticator struct {
+	roots          *x509.CertPool
+	header         string
+	trustedProxies []*net.IPNet
+}
+
+// NewCertAuthenticator 加载签发客户端证书的 CA
+func NewCertAuthenticator(cfg define.MTLSConfig) (*CertAuthenticator, error) {
+	caPEM, err := os.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client ca file: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", cfg.ClientCAFile)
+	}
+	a := &CertAuthenticator{roots: roots, header: cfg.ClientCertHeader}
+	for _, cidr := range cfg.TrustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		a.trustedProxies = append(a.trustedProxies, ipNet)
+	}
+	if a.header != "" && len(a.trustedProxies) == 0 {
+		return nil, errors.New("trustedProxies is required when clientCertHeader is set")
+	}
+	return a, nil
+}
+
+type peerCertificatesKey struct{}
+
+// WithPeerCertificates 记录连接上的客户端证书链（DER），供标准库 TLS 之外的监听器（如国密 TLS）使用
+func WithPeerCertificates(ctx context.Context, certs [][]byte) context.Context {
+	return context.WithValue(ctx, peerCertificatesKey{}, certs)
+}
+
+// Authenticate 请求未携带客户端证书时返回 nil, nil
+func (a *CertAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	chain, err := a.clientCertificates(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(chain) == 0 {
+		return nil, nil
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	leaf := chain[0]
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         a.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return nil, fmt.Errorf("invalid client certificate: %w", err)
+	}
+	if leaf.Subject.CommonName == "" {
+		return nil, errors.New("invalid client certificate: common name is empty")
+	}
+	return &Principal{Method: MethodCert, Name: leaf.Subject.CommonName}, nil
+}
+
+// clientCertificates 依次从国密 TLS 连接、标准库 TLS 连接与可信代理转发的请求头中读取客户端证书链
+func (a *CertAuthenticator) clientCertificates(r *http.Request) ([]*x509.Certificate, error) {
+	if raw, ok := r.Context().Value(peerCertificatesKey{}).([][]byte); ok && len(raw) > 0 {
+		return parseDERChain(raw)
+	}
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		raw := make([][]byte, 0, len(r.TLS.PeerCertificates))
+		for _, cert := range r.TLS.PeerCertificates {
+			raw = append(raw, cert.Raw)
+		}
+		return parseDERChain(raw)
+	}
+	if a.header == "" {
+		return nil, nil
+	}
+	value := r.Header.Get(a.header)
+	if value == "" {
+		return nil, nil
+	}
+	if !a.fromTrustedProxy(r) {
+		return nil, errors.New("client certificate header from untrusted address")
+	}
+	decoded, err := url.QueryUnescape(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client certificate header: %w", err)
+	}
+	var raw [][]byte
+	rest := []byte(decoded)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			raw = append(raw, block.Bytes)
+		}
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("invalid client certificate header: no certificate found")
+	}
+	return parseDERChain(raw)
+}
+
+func (a *CertAuthenticator) fromTrustedProxy(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range a.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseDERChain(raw [][]byte) ([]*x509.Certificate, error) {
+	chain := make([]*x509.Certificate, 0, len(raw))
+	for _, der := range raw {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		chain = append(chain, cert)
+	}
+	return chain, nil
+}
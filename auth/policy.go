@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+// Action 接口操作类型
+type Action string
+
+const (
+	// ActionQuery 查询合约
+	ActionQuery Action = "query"
+	// ActionInvoke 调用合约并提交交易
+	ActionInvoke Action = "invoke"
+	// ActionRead 读取账本、合约定义与测试连接
+	ActionRead Action = "read"
+	// ActionSubscribe 订阅事件与事件流
+	ActionSubscribe Action = "subscribe"
+	// ActionManage 管理已有订阅
+	ActionManage Action = "manage"
+)
+
+var actions = map[Action]bool{
+	ActionQuery:     true,
+	ActionInvoke:    true,
+	ActionRead:      true,
+	ActionSubscribe: true,
+	ActionManage:    true,
+}
+
+// Resource 请求访问的资源
+type Resource struct {
+	Action    Action
+	Profile   string
+	Channels  []string
+	Chaincode string
+	Function  string
+}
+
+// Rule 单条授权规则，各维度为空表示不限制；限制了某个维度时，请求必须带有该维度且全部匹配
+type Rule struct {
+	Subjects   []string
+	Profiles   []string
+	Channels   []string
+	Chaincodes []string
+	Functions  []string
+	Actions    []string
+}
+
+// Policy 授权策略，默认拒绝，任一规则匹配即允许
+type Policy struct {
+	Rules []Rule
+}
+
+// NewPolicy 从配置构造授权策略并校验规则
+func NewPolicy(configs []define.PolicyConfig) (*Policy, error) {
+	policy := &Policy{}
+	for i, c := range configs {
+		rule := Rule{
+			Subjects:   c.Subjects,
+			Profiles:   c.Profiles,
+			Channels:   c.Channels,
+			Chaincodes: c.Chaincodes,
+			Functions:  c.Functions,
+			Actions:    c.Actions,
+		}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("policy %d: %w", i, err)
+		}
+		policy.Rules = append(policy.Rules, rule)
+	}
+	return policy, nil
+}
+
+func (r Rule) validate() error {
+	if len(r.Subjects) == 0 {
+		return errors.New("subjects is required")
+	}
+	for _, a := range r.Actions {
+		if a != "*" && !actions[Action(a)] {
+			return fmt.Errorf("unknown action %q", a)
+		}
+	}
+	for _, patterns := range [][]string{r.Subjects, r.Profiles, r.Channels, r.Chaincodes, r.Functions} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q", p)
+			}
+		}
+	}
+	return nil
+}
+
+// Allow 判断调用方能否访问资源
+func (p *Policy) Allow(principal *Principal, res Resource) bool {
+	if principal == nil {
+		return false
+	}
+	for _, rule := range p.Rules {
+		if rule.allow(principal, res) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) allow(principal *Principal, res Resource) bool {
+	if !matchAny(r.Subjects, principal.Subject()) {
+		return false
+	}
+	if len(r.Actions) > 0 && !matchAny(r.Actions, string(res.Action)) {
+		return false
+	}
+	if len(r.Profiles) > 0 && !matchAny(r.Profiles, res.Profile) {
+		return false
+	}
+	if len(r.Channels) > 0 {
+		if len(res.Channels) == 0 {
+			return false
+		}
+		for _, ch := range res.Channels {
+			if !matchAny(r.Channels, ch) {
+				return false
+			}
+		}
+	}
+	if len(r.Chaincodes) > 0 && !matchAny(r.Chaincodes, res.Chaincode) {
+		return false
+	}
+	if len(r.Functions) > 0 && !matchAny(r.Functions, res.Function) {
+		return false
+	}
+	return true
+}
+
+// matchAny 值为空时不匹配任何模式，避免受限的维度被缺失的值绕过
+func matchAny(patterns []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+func TestPolicyAllow(t *testing.T) {
+	policy, err := NewPolicy([]define.PolicyConfig{
+		{Subjects: []string{"apikey:ops"}, Actions: []string{"*"}},
+		{
+			Subjects:   []string{"jwt:app-*"},
+			Channels:   []string{"mychannel"},
+			Chaincodes: []string{"basic"},
+			Functions:  []string{"Query*"},
+			Actions:    []string{"query"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &Principal{Method: MethodJWT, Name: "app-1"}
+	tests := []struct {
+		name      string
+		principal *Principal
+		res       Resource
+		want      bool
+	}{
+		{"unrestricted rule", &Principal{Method: MethodAPIKey, Name: "ops"}, Resource{Action: ActionManage}, true},
+		{"matching rule", app, Resource{Action: ActionQuery, Channels: []string{"mychannel"}, Chaincode: "basic", Function: "QueryAsset"}, true},
+		{"other action", app, Resource{Action: ActionInvoke, Channels: []string{"mychannel"}, Chaincode: "basic", Function: "QueryAsset"}, false},
+		{"other function", app, Resource{Action: ActionQuery, Channels: []string{"mychannel"}, Chaincode: "basic", Function: "DeleteAsset"}, false},
+		{"profile with other channel", app, Resource{Action: ActionQuery, Channels: []string{"mychannel", "other"}, Chaincode: "basic", Function: "QueryAsset"}, false},
+		{"unknown channels", app, Resource{Action: ActionQuery, Chaincode: "basic", Function: "QueryAsset"}, false},
+		{"unknown subject", &Principal{Method: MethodCert, Name: "app-1"}, Resource{Action: ActionQuery}, false},
+		{"no principal", nil, Resource{Action: ActionQuery}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Allow(tt.principal, tt.res); got != tt.want {
+				t.Errorf("Allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPolicyValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config define.PolicyConfig
+	}{
+		{"missing subjects", define.PolicyConfig{Actions: []string{"query"}}},
+		{"unknown action", define.PolicyConfig{Subjects: []string{"apikey:ops"}, Actions: []string{"delete"}}},
+		{"invalid pattern", define.PolicyConfig{Subjects: []string{"apikey:["}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPolicy([]define.PolicyConfig{tt.config}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package auth
+
+import "context"
+
+// 认证方式
+const (
+	MethodAPIKey = "apikey"
+	MethodJWT    = "jwt"
+	MethodCert   = "cert"
+)
+
+// Principal 已认证的调用方
+type Principal struct {
+	// Method 认证方式
+	Method string
+	// Name 认证方式内的调用方名称：API Key 名称、JWT 主体或证书 CN
+	Name string
+}
+
+// Subject 授权规则中引用调用方的标识，格式为 <method>:<name>
+func (p *Principal) Subject() string {
+	return p.Method + ":" + p.Name
+}
+
+type principalKey struct{}
+
+// WithPrincipal 将调用方写入上下文
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext 从上下文读取调用方，未启用认证时返回 nil
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,253 @@
+// Package certmon 监控证书到期时间。检查连接池中各连接配置引用的用户签名证书、TLS 客户端证书与 Peer、Orderer、CA 的
+// TLS CA 证书以及身份存储中的证书（X.509 与 SM2），到期时间导出为 Prometheus 指标，距到期不足阈值时记录警告或错误日志。
+package certmon
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"gitee.com/china_uni/tjfoc-gm/sm2"
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/identity"
+	"github.com/qctc/fabric2-api-server/metrics"
+	"github.com/qctc/fabric2-api-server/service"
+)
+
+// 未配置时的默认值
+const (
+	defaultInterval = time.Hour
+	defaultWarning  = 30 * 24 * time.Hour
+	defaultCritical = 7 * 24 * time.Hour
+)
+
+// KindIdentityStore 身份存储中的证书，其余类型见 service.CertKind*
+const KindIdentityStore = "identityStore"
+
+// Status 证书状态
+type Status string
+
+const (
+	StatusOK          Status = "ok"
+	StatusWarning     Status = "warning"     // 距到期不足 certificates.warning
+	StatusCritical    Status = "critical"    // 距到期不足 certificates.critical
+	StatusExpired     Status = "expired"     // 已过期
+	StatusNotYetValid Status = "notYetValid" // 尚未生效（notBefore 晚于当前时间）
+	StatusInvalid     Status = "invalid"     // 证书文件无法读取或解析
+)
+
+// Certificate 证书信息，Profile 为空表示身份存储中的证书
+type Certificate struct {
+	Profile   string     `json:"profile,omitempty"`
+	Kind      string     `json:"kind"`
+	Owner     string     `json:"owner"`
+	Source    string     `json:"source,omitempty"` // 证书文件路径，内嵌在连接配置中时为 pem
+	Subject   string     `json:"subject,omitempty"`
+	Issuer    string     `json:"issuer,omitempty"`
+	Serial    string     `json:"serial,omitempty"` // 十六进制序列号
+	KeyType   string     `json:"keyType,omitempty"`
+	NotBefore *time.Time `json:"notBefore,omitempty"`
+	NotAfter  *time.Time `json:"notAfter,omitempty"`
+	Status    Status     `json:"status"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Monitor 证书到期检查
+type Monitor struct {
+	cfg define.CertificatesConfig
+	now func() time.Time
+	// refs 返回待检查的证书，profile 不为空时只返回该连接配置的证书，不包含身份存储
+	refs func(profile string) []ref
+}
+
+// ref 待检查的证书
+type ref struct {
+	profile string
+	service.CertificateRef
+}
+
+// New 按配置创建证书检查，未配置的阈值使用默认值
+func New(cfg define.CertificatesConfig) *Monitor {
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Warning <= 0 {
+		cfg.Warning = defaultWarning
+	}
+	if cfg.Critical <= 0 {
+		cfg.Critical = defaultCritical
+	}
+	return &Monitor{cfg: cfg, now: time.Now, refs: collect}
+}
+
+// Scan 检查证书，按到期时间升序返回，无法解析的证书排在最前。profile 不为空时只检查该连接配置
+func (m *Monitor) Scan(profile string) []Certificate {
+	now := m.now()
+	result := []Certificate{}
+	for _, r := range m.refs(profile) {
+		result = append(result, m.check(r, now)...)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i].NotAfter, result[j].NotAfter
+		switch {
+		case a == nil || b == nil:
+			return a == nil && b != nil
+		default:
+			return a.Before(*b)
+		}
+	})
+	return result
+}
+
+// Run 定期检查全部证书并更新指标，直到 ctx 取消；certificates.interval 为负数时只在启动时检查一次
+func (m *Monitor) Run(ctx context.Context) {
+	m.report(ctx)
+	if m.cfg.Interval < 0 {
+		return
+	}
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.report(ctx)
+		}
+	}
+}
+
+// report 导出到期时间指标，并为需要关注的证书记录日志
+func (m *Monitor) report(ctx context.Context) {
+	certs := m.Scan("")
+	metrics.CertificateExpiry.Reset()
+	for _, c := range certs {
+		if c.NotAfter != nil {
+			metrics.CertificateExpiry.WithLabelValues(c.Profile, c.Kind, c.Owner, c.Subject).Set(float64(c.NotAfter.Unix()))
+		}
+		if c.Status == StatusOK {
+			continue
+		}
+		attrs := []any{"profile", c.Profile, "kind", c.Kind, "owner", c.Owner, "source", c.Source, "status", c.Status}
+		if c.NotAfter != nil {
+			attrs = append(attrs, "subject", c.Subject, "notAfter", c.NotAfter.Format(time.RFC3339))
+		}
+		if c.Error != "" {
+			attrs = append(attrs, "error", c.Error)
+		}
+		level := slog.LevelWarn
+		if c.Status == StatusCritical || c.Status == StatusExpired || c.Status == StatusNotYetValid {
+			level = slog.LevelError
+		}
+		slog.Log(ctx, level, "certificate expiry check", attrs...)
+	}
+}
+
+// check 解析证书并判断状态，一个 PEM 中的多个证书分别返回
+func (m *Monitor) check(r ref, now time.Time) []Certificate {
+	base := Certificate{Profile: r.profile, Kind: r.Kind, Owner: r.Owner, Source: r.Source}
+	invalid := func(err error) []Certificate {
+		base.Status, base.Error = StatusInvalid, err.Error()
+		return []Certificate{base}
+	}
+	if r.Err != nil {
+		return invalid(r.Err)
+	}
+
+	var result []Certificate
+	rest := r.PEM
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c := base
+		// 国密 x509 同时支持 SM2、ECDSA 与 RSA 证书
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			c.Status, c.Error = StatusInvalid, fmt.Sprintf("invalid certificate: %v", err)
+			result = append(result, c)
+			continue
+		}
+		notBefore, notAfter := cert.NotBefore.UTC(), cert.NotAfter.UTC()
+		c.Subject = cert.Subject.String()
+		c.Issuer = cert.Issuer.String()
+		c.Serial = cert.SerialNumber.Text(16)
+		c.KeyType = keyType(cert.PublicKey)
+		c.NotBefore, c.NotAfter = &notBefore, &notAfter
+		c.Status = m.status(notBefore, notAfter, now)
+		result = append(result, c)
+	}
+	if len(result) == 0 {
+		return invalid(errors.New("no PEM encoded CERTIFICATE found"))
+	}
+	return result
+}
+
+// status 按证书有效期与距到期的时长判断证书状态
+func (m *Monitor) status(notBefore, notAfter, now time.Time) Status {
+	switch remaining := notAfter.Sub(now); {
+	case remaining <= 0:
+		return StatusExpired
+	case now.Before(notBefore):
+		return StatusNotYetValid
+	case remaining <= m.cfg.Critical:
+		return StatusCritical
+	case remaining <= m.cfg.Warning:
+		return StatusWarning
+	default:
+		return StatusOK
+	}
+}
+
+func keyType(pub interface{}) string {
+	switch pub.(type) {
+	case *sm2.PublicKey:
+		return identity.KeyTypeSM2
+	case *ecdsa.PublicKey:
+		return identity.KeyTypeECDSA
+	case *rsa.PublicKey:
+		return "RSA"
+	default:
+		return fmt.Sprintf("%T", pub)
+	}
+}
+
+// collect 读取连接池中连接配置引用的证书，profile 为空时同时读取身份存储中的证书
+func collect(profile string) []ref {
+	var refs []ref
+	for _, sdk := range service.List() {
+		if profile != "" && sdk.Id() != profile {
+			continue
+		}
+		certs, err := sdk.CertificateRefs()
+		if err != nil {
+			refs = append(refs, ref{profile: sdk.Id(), CertificateRef: service.CertificateRef{Err: err}})
+			continue
+		}
+		for _, cert := range certs {
+			refs = append(refs, ref{profile: sdk.Id(), CertificateRef: cert})
+		}
+	}
+	if profile == "" {
+		for _, id := range identity.Default.List("") {
+			refs = append(refs, ref{CertificateRef: service.CertificateRef{
+				Kind:  KindIdentityStore,
+				Owner: id.MSPID + "/" + id.Name,
+				PEM:   []byte(id.Certificate),
+			}})
+		}
+	}
+	return refs
+}
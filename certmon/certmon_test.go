@@ -0,0 +1,132 @@
+package certmon
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"gitee.com/china_uni/tjfoc-gm/sm2"
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/identity"
+	"github.com/qctc/fabric2-api-server/service"
+)
+
+var now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestMonitor(refs ...ref) *Monitor {
+	m := New(define.CertificatesConfig{})
+	m.now = func() time.Time { return now }
+	m.refs = func(string) []ref { return refs }
+	return m
+}
+
+func TestScan(t *testing.T) {
+	sm2Cert := newCert(t, identity.KeyTypeSM2, "Admin@org1", now.Add(365*24*time.Hour))
+	ecdsaCert := newCert(t, identity.KeyTypeECDSA, "tlsca.org1", now.Add(10*24*time.Hour))
+	expired := newCert(t, identity.KeyTypeECDSA, "tlsca.orderer", now.Add(-time.Hour))
+	critical := newCert(t, identity.KeyTypeSM2, "client", now.Add(24*time.Hour))
+
+	m := newTestMonitor(
+		ref{profile: "p1", CertificateRef: service.CertificateRef{Kind: service.CertKindUser, Owner: "org1/Admin", Source: "pem", PEM: sm2Cert}},
+		// 一个 tlsCACerts 中可以包含多个证书
+		ref{profile: "p1", CertificateRef: service.CertificateRef{Kind: service.CertKindPeerTLSCA, Owner: "peer0", PEM: append(ecdsaCert, expired...)}},
+		ref{profile: "p1", CertificateRef: service.CertificateRef{Kind: service.CertKindClientTLS, Owner: "client", PEM: critical}},
+		ref{profile: "p1", CertificateRef: service.CertificateRef{Kind: service.CertKindOrdererTLSCA, Owner: "orderer0", Err: errors.New("certificate file /tmp/ca.pem not found")}},
+		ref{profile: "p1", CertificateRef: service.CertificateRef{Kind: service.CertKindCATLSCA, Owner: "ca.org1", PEM: []byte("not a certificate")}},
+	)
+	certs := m.Scan("")
+	want := []struct {
+		subject string
+		keyType string
+		status  Status
+	}{
+		{"", "", StatusInvalid},
+		{"", "", StatusInvalid},
+		{"CN=tlsca.orderer", identity.KeyTypeECDSA, StatusExpired},
+		{"CN=client", identity.KeyTypeSM2, StatusCritical},
+		{"CN=tlsca.org1", identity.KeyTypeECDSA, StatusWarning},
+		{"CN=Admin@org1", identity.KeyTypeSM2, StatusOK},
+	}
+	if len(certs) != len(want) {
+		t.Fatalf("certs = %+v", certs)
+	}
+	for i, w := range want {
+		c := certs[i]
+		if c.Subject != w.subject || c.KeyType != w.keyType || c.Status != w.status {
+			t.Errorf("certs[%d] = %+v, want %+v", i, c, w)
+		}
+		if c.Status == StatusInvalid && c.Error == "" {
+			t.Errorf("certs[%d] has no error", i)
+		}
+	}
+	if certs[5].Issuer != "CN=Admin@org1" || certs[5].Owner != "org1/Admin" || certs[5].Serial == "" {
+		t.Errorf("sm2 certificate = %+v", certs[5])
+	}
+}
+
+func TestStatusThresholds(t *testing.T) {
+	m := New(define.CertificatesConfig{Warning: 48 * time.Hour, Critical: 12 * time.Hour})
+	tests := []struct {
+		notBefore time.Time
+		notAfter  time.Time
+		want      Status
+	}{
+		{now.Add(-time.Hour), now.Add(72 * time.Hour), StatusOK},
+		{now.Add(-time.Hour), now.Add(48 * time.Hour), StatusWarning},
+		{now.Add(-time.Hour), now.Add(12 * time.Hour), StatusCritical},
+		{now.Add(-time.Hour), now, StatusExpired},
+		{now.Add(time.Hour), now.Add(72 * time.Hour), StatusNotYetValid},
+		{now.Add(time.Hour), now.Add(-time.Hour), StatusExpired},
+	}
+	for _, tt := range tests {
+		if got := m.status(tt.notBefore, tt.notAfter, now); got != tt.want {
+			t.Errorf("status(%s, %s) = %s, want %s", tt.notBefore, tt.notAfter, got, tt.want)
+		}
+	}
+}
+
+// newCert 生成自签名证书（PEM）
+func newCert(t *testing.T, keyType, cn string, notAfter time.Time) []byte {
+	t.Helper()
+	if keyType == identity.KeyTypeSM2 {
+		key, err := sm2.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl := &x509.Certificate{
+			SerialNumber:       big.NewInt(time.Now().UnixNano()),
+			Subject:            pkix.Name{CommonName: cn},
+			NotBefore:          now.Add(-24 * time.Hour),
+			NotAfter:           notAfter,
+			SignatureAlgorithm: x509.SM2WithSM3,
+		}
+		certPEM, err := x509.CreateCertificateToMem(tmpl, tmpl, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return certPEM
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &stdx509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    now.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := stdx509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -0,0 +1,139 @@
+// Package config 读取服务配置：配置文件路径由 --config 参数或 FABRIC2_CONFIG 环境变量指定，
+// 环境变量可覆盖任意配置项，读取后校验配置，运行中收到 SIGHUP 或配置文件变化时重新加载。
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/qctc/fabric2-api-server/define"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix 覆盖配置项的环境变量前缀
+const EnvPrefix = "FABRIC2_"
+
+// PathEnv 指定配置文件路径的环境变量
+const PathEnv = EnvPrefix + "CONFIG"
+
+// DefaultPath 未指定时的配置文件路径
+const DefaultPath = "./config.yaml"
+
+// Path 返回配置文件路径：命令行参数优先，其次为 FABRIC2_CONFIG，最后为 DefaultPath
+func Path(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if p := os.Getenv(PathEnv); p != "" {
+		return p
+	}
+	return DefaultPath
+}
+
+// Load 读取配置文件，以环境变量覆盖后校验。配置文件中出现未知的配置项时报错，避免拼写错误被忽略
+func Load(path string) (*define.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if err := ApplyEnv(cfg, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := Validate(cfg); err != nil {
+		return nil, fmt.Errorf("invalid config %s:\n%w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse 解析 YAML 配置，不校验
+func Parse(data []byte) (*define.Config, error) {
+	cfg := &define.Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ApplyEnv 以环境变量覆盖配置项。变量名为 FABRIC2_ 加上以下划线连接的各级配置项名称的大写蛇形形式，
+// 如 server.grpcPort 对应 FABRIC2_SERVER_GRPC_PORT，mq.userName 对应 FABRIC2_MQ_USER_NAME。
+// 字符串列表以逗号分隔，其他类型按 YAML 解析，如 FABRIC2_AUTH_API_KEYS='[{name: ops, sha256: ...}]'
+func ApplyEnv(cfg *define.Config, lookup func(string) (string, bool)) error {
+	return applyEnv(reflect.ValueOf(cfg).Elem(), strings.TrimSuffix(EnvPrefix, "_"), lookup)
+}
+
+func applyEnv(v reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name := prefix + "_" + EnvName(tag)
+		fv := v.Field(i)
+		if value, ok := lookup(name); ok {
+			if err := setValue(fv, value); err != nil {
+				return fmt.Errorf("environment variable %s: %w", name, err)
+			}
+			continue
+		}
+		if fv.Kind() == reflect.Struct {
+			if err := applyEnv(fv, name, lookup); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setValue(v reflect.Value, value string) error {
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+		return nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+		return nil
+	}
+	ptr := reflect.New(v.Type())
+	if err := yaml.Unmarshal([]byte(value), ptr.Interface()); err != nil {
+		return err
+	}
+	v.Set(ptr.Elem())
+	return nil
+}
+
+// EnvName 将配置项名称转换为环境变量名称的一段，如 grpcPort 转换为 GRPC_PORT，clientCAFile 转换为 CLIENT_CA_FILE
+func EnvName(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+func TestEnvName(t *testing.T) {
+	cases := map[string]string{
+		"port":                  "PORT",
+		"grpcPort":              "GRPC_PORT",
+		"userName":              "USER_NAME",
+		"clientCAFile":          "CLIENT_CA_FILE",
+		"apiKeys":               "API_KEYS",
+		"sha256":                "SHA256",
+		"jwksFile":              "JWKS_FILE",
+		"reconnectInitialDelay": "RECONNECT_INITIAL_DELAY",
+		"maxInFlight":           "MAX_IN_FLIGHT",
+	}
+	for key, want := range cases {
+		if got := EnvName(key); got != want {
+			t.Errorf("EnvName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"FABRIC2_SERVER_GRPC_PORT":             "9091",
+		"FABRIC2_SERVER_REQUEST_TIMEOUT":       "15s",
+		"FABRIC2_MQ_USER_NAME":                 "producer",
+		"FABRIC2_MQ_PASSWORD":                  "secret",
+		"FABRIC2_RATE_LIMIT_ENABLED":           "true",
+		"FABRIC2_RATE_LIMIT_CALLER_RATE":       "2.5",
+		"FABRIC2_AUTH_MTLS_TRUSTED_PROXIES":    "10.0.0.0/8, 127.0.0.1/32",
+		"FABRIC2_AUTH_API_KEYS":                "[{name: ops, sha256: abc}]",
+		"FABRIC2_TRACING_SAMPLE_RATIO":         "0.5",
+		"FABRIC2_SUBSCRIPTION_CHECKPOINT_FILE": "/data/subs.json",
+		"FABRIC2_HEALTH_SUBSCRIPTION_LAG_WARN": "10",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	cfg := &define.Config{}
+	cfg.Server.Port = 9090
+	if err := ApplyEnv(cfg, lookup); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Port != 9090 || cfg.Server.GRPCPort != 9091 || cfg.Server.RequestTimeout != 15*time.Second {
+		t.Errorf("server = %+v", cfg.Server)
+	}
+	if cfg.MQ.UserName != "producer" || cfg.MQ.Password != "secret" {
+		t.Errorf("mq = %+v", cfg.MQ)
+	}
+	if !cfg.RateLimit.Enabled || cfg.RateLimit.Caller.Rate != 2.5 {
+		t.Errorf("rateLimit = %+v", cfg.RateLimit)
+	}
+	if got := strings.Join(cfg.Auth.MTLS.TrustedProxies, "|"); got != "10.0.0.0/8|127.0.0.1/32" {
+		t.Errorf("trustedProxies = %q", got)
+	}
+	if len(cfg.Auth.APIKeys) != 1 || cfg.Auth.APIKeys[0].Name != "ops" {
+		t.Errorf("apiKeys = %+v", cfg.Auth.APIKeys)
+	}
+	if cfg.Tracing.SampleRatio != 0.5 || cfg.Subscription.CheckpointFile != "/data/subs.json" || cfg.Health.SubscriptionLagWarn != 10 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	bad := func(name string) (string, bool) {
+		if name == "FABRIC2_SERVER_PORT" {
+			return "not-a-number", true
+		}
+		return "", false
+	}
+	if err := ApplyEnv(&define.Config{}, bad); err == nil || !strings.Contains(err.Error(), "FABRIC2_SERVER_PORT") {
+		t.Errorf("invalid value error = %v", err)
+	}
+}
+
+func TestParseUnknownField(t *testing.T) {
+	if _, err := Parse([]byte("server:\n  prot: 9090\n")); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &define.Config{}
+	cfg.Server.Port = 9090
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("minimal config: %v", err)
+	}
+
+	cfg.Server.GRPCPort = 9090
+	cfg.Server.RequestTimeout = time.Minute
+	cfg.Server.MaxRequestTimeout = time.Second
+	cfg.ChainType = "fabric1"
+	cfg.MQ = define.MQConfig{Type: "kafka", Host: "mq"}
+	cfg.Auth.APIKeys = []define.APIKeyConfig{{Name: "ops", SHA256: "abc"}}
+	cfg.RateLimit.Caller.Rate = -1
+	cfg.Log.Level = "verbose"
+	cfg.Tracing = define.TracingConfig{Enabled: true, SampleRatio: 2}
+	cfg.Idempotency.Retention = -time.Hour
+	cfg.Server.TLS = define.ServerTLSConfig{Mode: "gmtls", EncCertFile: "enc.pem", ClientAuth: "require"}
+	cfg.IdentityStore = define.IdentityStoreConfig{Enabled: true, Cipher: "des"}
+	cfg.HSM = define.HSMConfig{Enabled: true, SM2KeyType: 0x80000001}
+	cfg.UserIdentities = define.UserIdentitiesConfig{Enabled: true}
+	cfg.Certificates = define.CertificatesConfig{Warning: time.Hour, Critical: 2 * time.Hour}
+	err := Validate(cfg)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, path := range []string{
+		"server.grpcPort", "server.requestTimeout", "chainType", "mq.type", "mq.port", "mq.topic",
+		"auth.apiKeys[0].sha256", "rateLimit.caller", "log.level", "tracing.endpoint", "tracing.sampleRatio",
+		"idempotency.retention", "server.tls.encKeyFile", "server.tls.certFile", "server.tls.clientCAFile",
+		"identityStore.file", "identityStore.masterKey", "identityStore.cipher",
+		"hsm.library", "hsm.label", "hsm.sm2Mechanism", "userIdentities.enabled",
+		"certificates.critical",
+	} {
+		if !strings.Contains(err.Error(), path+":") {
+			t.Errorf("missing error for %s in:\n%v", path, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if _, err := Load("../config.yaml"); err != nil {
+		t.Fatalf("repository config.yaml: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  port: 9090\nlog:\n  level: info\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FABRIC2_LOG_LEVEL", "debug")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Log.Level != "debug" {
+		t.Errorf("log.level = %q, want override from environment", cfg.Log.Level)
+	}
+
+	t.Setenv("FABRIC2_SERVER_PORT", "0")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "server.port") {
+		t.Errorf("Load with invalid override = %v", err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv(PathEnv, "")
+	if got := Path(""); got != DefaultPath {
+		t.Errorf("Path() = %q", got)
+	}
+	t.Setenv(PathEnv, "/etc/fabric2/config.yaml")
+	if got := Path(""); got != "/etc/fabric2/config.yaml" {
+		t.Errorf("Path() from env = %q", got)
+	}
+	if got := Path("/tmp/c.yaml"); got != "/tmp/c.yaml" {
+		t.Errorf("Path(flag) = %q", got)
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	old := &define.Config{}
+	old.Server.Port = 9090
+	updated := *old
+	updated.Log.Level = "debug"
+	updated.RateLimit.Enabled = true
+	updated.MQ.Host = "mq"
+	if got := RestartRequired(old, &updated); len(got) != 0 {
+		t.Errorf("reloadable changes reported as restart required: %v", got)
+	}
+	updated.Server.Port = 9091
+	updated.Auth.Enabled = true
+	if got := strings.Join(RestartRequired(old, &updated), ","); got != "server,auth" {
+		t.Errorf("RestartRequired = %q", got)
+	}
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/logging"
+)
+
+// Validate 校验配置，返回全部问题，每条以配置项路径开头
+func Validate(cfg *define.Config) error {
+	var errs []error
+	add := func(path, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+	nonNegative := func(path string, d time.Duration) {
+		if d < 0 {
+			add(path, "must not be negative")
+		}
+	}
+
+	server := cfg.Server
+	if server.Port <= 0 || server.Port > 65535 {
+		add("server.port", "must be between 1 and 65535, got %d", server.Port)
+	}
+	if server.GRPCPort < 0 || server.GRPCPort > 65535 {
+		add("server.grpcPort", "must be between 0 and 65535, got %d", server.GRPCPort)
+	} else if server.GRPCPort != 0 && server.GRPCPort == server.Port {
+		add("server.grpcPort", "must differ from server.port")
+	}
+	nonNegative("server.shutdownTimeout", server.ShutdownTimeout)
+	nonNegative("server.requestTimeout", server.RequestTimeout)
+	nonNegative("server.maxRequestTimeout", server.MaxRequestTimeout)
+	if server.MaxRequestTimeout > 0 && server.RequestTimeout > server.MaxRequestTimeout {
+		add("server.requestTimeout", "must not exceed server.maxRequestTimeout")
+	}
+
+	validateTLS(server.TLS, add)
+
+	if cfg.ChainType != "" && cfg.ChainType != "fabric2" {
+		add("chainType", "unsupported chain type %q, only fabric2 is supported", cfg.ChainType)
+	}
+
+	mq := cfg.MQ
+	if mq.Type != "" && mq.Type != "rocketmq" {
+		add("mq.type", "unsupported message queue %q, only rocketmq is supported", mq.Type)
+	}
+	if mq.Host != "" {
+		if mq.Port <= 0 || mq.Port > 65535 {
+			add("mq.port", "must be between 1 and 65535, got %d", mq.Port)
+		}
+		if mq.Topic == "" {
+			add("mq.topic", "is required when mq.host is set")
+		}
+	}
+
+	sub := cfg.Subscription
+	nonNegative("subscription.reconnectInitialDelay", sub.ReconnectInitialDelay)
+	nonNegative("subscription.reconnectMaxDelay", sub.ReconnectMaxDelay)
+	if sub.ReconnectMaxAttempts < 0 {
+		add("subscription.reconnectMaxAttempts", "must not be negative")
+	}
+
+	for i, key := range cfg.Auth.APIKeys {
+		path := fmt.Sprintf("auth.apiKeys[%d]", i)
+		if key.Name == "" {
+			add(path+".name", "is required")
+		}
+		if b, err := hex.DecodeString(key.SHA256); err != nil || len(b) != 32 {
+			add(path+".sha256", "must be a hex encoded SHA-256 digest (64 characters)")
+		}
+	}
+	nonNegative("auth.jwt.leeway", cfg.Auth.JWT.Leeway)
+	if cfg.Auth.MTLS.Enabled && cfg.Auth.MTLS.ClientCAFile == "" {
+		add("auth.mtls.clientCAFile", "is required when mtls is enabled")
+	}
+
+	rl := cfg.RateLimit
+	nonNegative("rateLimit.queueTimeout", rl.QueueTimeout)
+	for name, limit := range map[string]define.LimitConfig{"caller": rl.Caller, "profile": rl.Profile, "chaincode": rl.Chaincode} {
+		if limit.Rate < 0 || limit.Burst < 0 || limit.MaxInFlight < 0 {
+			add("rateLimit."+name, "rate, burst and maxInFlight must not be negative")
+		}
+	}
+
+	if _, err := logging.ParseLevel(cfg.Log.Level); err != nil {
+		add("log.level", "must be debug, info, warn or error, got %q", cfg.Log.Level)
+	}
+
+	if cfg.Tracing.Enabled && cfg.Tracing.Endpoint == "" {
+		add("tracing.endpoint", "is required when tracing is enabled")
+	}
+	if r := cfg.Tracing.SampleRatio; r < 0 || r > 1 {
+		add("tracing.sampleRatio", "must be between 0 and 1, got %v", r)
+	}
+
+	nonNegative("health.timeout", cfg.Health.Timeout)
+	if h := cfg.Health; h.SubscriptionLagWarn > 0 && h.SubscriptionLagFail > 0 && h.SubscriptionLagWarn > h.SubscriptionLagFail {
+		add("health.subscriptionLagWarn", "must not exceed health.subscriptionLagFail")
+	}
+
+	nonNegative("idempotency.retention", cfg.Idempotency.Retention)
+
+	nonNegative("certificates.warning", cfg.Certificates.Warning)
+	nonNegative("certificates.critical", cfg.Certificates.Critical)
+	if c := cfg.Certificates; c.Warning > 0 && c.Critical > 0 && c.Critical > c.Warning {
+		add("certificates.critical", "must not exceed certificates.warning")
+	}
+
+	if store := cfg.IdentityStore; store.Enabled {
+		if store.File == "" {
+			add("identityStore.file", "is required when identityStore is enabled")
+		}
+		if store.MasterKey == "" {
+			add("identityStore.masterKey", "is required when identityStore is enabled")
+		}
+		if store.Cipher != "" && store.Cipher != "sm4" && store.Cipher != "aes" {
+			add("identityStore.cipher", "must be sm4 or aes, got %q", store.Cipher)
+		}
+	}
+
+	if h := cfg.HSM; h.Enabled {
+		if h.Library == "" {
+			add("hsm.library", "is required when hsm is enabled")
+		}
+		if h.Label == "" {
+			add("hsm.label", "is required when hsm is enabled")
+		}
+		if (h.SM2KeyType == 0) != (h.SM2Mechanism == 0) {
+			add("hsm.sm2Mechanism", "sm2KeyType and sm2Mechanism must be set together")
+		}
+	}
+
+	if u := cfg.UserIdentities; u.Enabled {
+		if !cfg.Auth.Enabled {
+			add("userIdentities.enabled", "requires auth to be enabled")
+		}
+		if u.AutoEnroll && !cfg.IdentityStore.Enabled && !cfg.HSM.Enabled {
+			add("userIdentities.autoEnroll", "requires identityStore or hsm to keep enrolled private keys")
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateTLS 校验监听器的 TLS 配置，证书文件在启动时加载
+func validateTLS(t define.ServerTLSConfig, add func(path, format string, args ...interface{})) {
+	switch t.Mode {
+	case "", "none":
+		return
+	case "tls":
+		if t.EncCertFile != "" || t.EncKeyFile != "" {
+			add("server.tls.encCertFile", "is only used with mode gmtls")
+		}
+		if t.MinVersion != "" && t.MinVersion != "1.2" && t.MinVersion != "1.3" {
+			add("server.tls.minVersion", "must be 1.2 or 1.3, got %q", t.MinVersion)
+		}
+	case "gmtls":
+		if (t.EncCertFile == "") != (t.EncKeyFile == "") {
+			add("server.tls.encKeyFile", "encCertFile and encKeyFile must be set together")
+		}
+		if t.MinVersion != "" {
+			add("server.tls.minVersion", "is not used with mode gmtls")
+		}
+	default:
+		add("server.tls.mode", "must be none, tls or gmtls, got %q", t.Mode)
+		return
+	}
+	if t.CertFile == "" || t.KeyFile == "" {
+		add("server.tls.certFile", "certFile and keyFile are required when tls is enabled")
+	}
+	switch t.ClientAuth {
+	case "", "none":
+	case "request", "require":
+		if t.ClientCAFile == "" {
+			add("server.tls.clientCAFile", "is required when clientAuth is %s", t.ClientAuth)
+		}
+	default:
+		add("server.tls.clientAuth", "must be none, request or require, got %q", t.ClientAuth)
+	}
+}
+
+// reloadable 重新加载时无需重启即可生效的配置项
+var reloadable = map[string]bool{
+	"log":       true,
+	"rateLimit": true,
+	"mq":        true,
+}
+
+// RestartRequired 返回两份配置中有变化、但需要重启才能生效的配置段
+func RestartRequired(old, new *define.Config) []string {
+	var sections []string
+	ov, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem()
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("yaml")
+		if reloadable[name] {
+			continue
+		}
+		if !reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			sections = append(sections, name)
+		}
+	}
+	return sections
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+// pollInterval 检查配置文件内容是否变化的间隔。按内容摘要而不是修改时间判断，
+// 容器中以 ConfigMap 挂载的配置通过替换符号链接更新，修改时间不可靠
+const pollInterval = 5 * time.Second
+
+// Watch 在收到 SIGHUP 或配置文件内容变化时重新加载配置并调用 apply，直到 ctx 结束。
+// 新配置读取或校验失败时记录日志并继续使用当前配置
+func Watch(ctx context.Context, path string, apply func(*define.Config)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	last := fileDigest(path)
+	reload := func(reason string) {
+		cfg, err := Load(path)
+		if err != nil {
+			slog.Error("重新加载配置失败，继续使用当前配置", "file", path, "reason", reason, "error", err)
+			return
+		}
+		slog.Info("重新加载配置", "file", path, "reason", reason)
+		apply(cfg)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			last = fileDigest(path)
+			reload("SIGHUP")
+		case <-ticker.C:
+			digest := fileDigest(path)
+			if digest == last {
+				continue
+			}
+			last = digest
+			reload("file changed")
+		}
+	}
+}
+
+// fileDigest 配置文件内容摘要，读取失败时为零值
+func fileDigest(path string) [sha256.Size]byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}
+	}
+	return sha256.Sum256(data)
+}
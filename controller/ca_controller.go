@@ -0,0 +1,414 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	mspclient "github.com/hyperledger/fabric-sdk-go/pkg/client/msp"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/identity"
+	"github.com/qctc/fabric2-api-server/service"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// GetCAInfo 获取 CA 名称、证书链与版本
+func GetCAInfo(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "get ca info start")
+	var req define.CARequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	info, err := ca.Info()
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	utils.Success(w, info)
+}
+
+// RegisterCAUser 注册用户，返回登记密码
+func RegisterCAUser(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "register ca user start")
+	var req define.CARegisterRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	secret, err := ca.Register(&mspclient.RegistrationRequest{
+		Name:           req.Name,
+		Type:           req.Type,
+		MaxEnrollments: req.MaxEnrollments,
+		Affiliation:    req.Affiliation,
+		Attributes:     caAttributes(req.Attributes),
+		Secret:         req.Secret,
+	})
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "ca user registered", "name", req.Name, "type", req.Type, "affiliation", req.Affiliation)
+	utils.Success(w, map[string]string{"name": req.Name, "secret": secret})
+}
+
+// EnrollCAUser 登记用户，证书与私钥保存到身份存储或 HSM
+func EnrollCAUser(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "enroll ca user start")
+	var req define.CAEnrollRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	opts := []mspclient.EnrollmentOption{
+		mspclient.WithSecret(req.Secret),
+		mspclient.WithProfile(req.Profile),
+		mspclient.WithType(req.Type),
+		mspclient.WithLabel(req.Label),
+	}
+	if len(req.AttributeRequests) > 0 {
+		opts = append(opts, mspclient.WithAttributeRequests(caAttributeRequests(req.AttributeRequests)))
+	}
+	result, err := ca.Enroll(req.Name, opts...)
+	if err != nil {
+		caError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "ca user enrolled", "mspId", result.MSPID, "name", result.Name, "keyStorage", result.KeyStorage)
+	utils.Success(w, result)
+}
+
+// ReenrollCAUser 重新登记用户，获取新的证书与私钥
+func ReenrollCAUser(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "reenroll ca user start")
+	var req define.CAReenrollRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	opts := []mspclient.EnrollmentOption{
+		mspclient.WithProfile(req.Profile),
+		mspclient.WithLabel(req.Label),
+	}
+	if len(req.AttributeRequests) > 0 {
+		opts = append(opts, mspclient.WithAttributeRequests(caAttributeRequests(req.AttributeRequests)))
+	}
+	result, err := ca.Reenroll(req.Name, opts...)
+	if err != nil {
+		caError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "ca user reenrolled", "mspId", result.MSPID, "name", result.Name, "keyStorage", result.KeyStorage)
+	utils.Success(w, result)
+}
+
+// RevokeCAUser 吊销证书，genCRL 为 true 时返回证书吊销列表
+func RevokeCAUser(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "revoke ca user start")
+	var req define.CARevokeRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.Name == "" && (req.Serial == "" || req.AKI == "") {
+		utils.BadRequest(w, "name or serial and aki are required")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	result, err := ca.Revoke(&mspclient.RevocationRequest{
+		Name:   req.Name,
+		Serial: req.Serial,
+		AKI:    req.AKI,
+		Reason: req.Reason,
+		GenCRL: req.GenCRL,
+	})
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "ca certificates revoked", "name", req.Name, "serial", req.Serial, "reason", req.Reason, "count", len(result.RevokedCerts))
+	utils.Success(w, result)
+}
+
+// ListCAIdentities 获取 CA 中的全部身份
+func ListCAIdentities(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "list ca identities start")
+	var req define.CARequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	list, err := ca.Identities()
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	utils.Success(w, list)
+}
+
+// GetCAIdentity 获取 CA 中的身份
+func GetCAIdentity(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "get ca identity start")
+	var req define.CAIdentityRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	id, err := ca.Identity(req.ID)
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	utils.Success(w, id)
+}
+
+// CreateCAIdentity 在 CA 中创建身份，返回登记密码
+func CreateCAIdentity(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "create ca identity start")
+	var req define.CAIdentityRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	id, secret, err := ca.CreateIdentity(caIdentityRequest(&req))
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "ca identity created", "id", id.ID, "type", id.Type, "affiliation", id.Affiliation)
+	utils.Success(w, map[string]interface{}{"identity": id, "secret": secret})
+}
+
+// ModifyCAIdentity 修改 CA 中的身份
+func ModifyCAIdentity(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "modify ca identity start")
+	var req define.CAIdentityRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	id, err := ca.ModifyIdentity(caIdentityRequest(&req))
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "ca identity modified", "id", id.ID)
+	utils.Success(w, id)
+}
+
+// RemoveCAIdentity 删除 CA 中的身份
+func RemoveCAIdentity(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "remove ca identity start")
+	var req define.CAIdentityRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	id, err := ca.RemoveIdentity(&mspclient.RemoveIdentityRequest{ID: req.ID, Force: req.Force})
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "ca identity removed", "id", req.ID)
+	utils.Success(w, id)
+}
+
+// ListCAAffiliations 获取 CA 中的全部机构
+func ListCAAffiliations(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "list ca affiliations start")
+	var req define.CARequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	result, err := ca.Affiliations()
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	utils.Success(w, result)
+}
+
+// GetCAAffiliation 获取机构及其下级机构与身份
+func GetCAAffiliation(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "get ca affiliation start")
+	var req define.CAAffiliationRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	result, err := ca.Affiliation(req.Name)
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	utils.Success(w, result)
+}
+
+// AddCAAffiliation 添加机构
+func AddCAAffiliation(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "add ca affiliation start")
+	var req define.CAAffiliationRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	result, err := ca.AddAffiliation(&mspclient.AffiliationRequest{Name: req.Name, Force: req.Force})
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "ca affiliation added", "name", req.Name)
+	utils.Success(w, result)
+}
+
+// ModifyCAAffiliation 重命名机构
+func ModifyCAAffiliation(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "modify ca affiliation start")
+	var req define.CAAffiliationRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.NewName == "" {
+		utils.BadRequest(w, "newName is required")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	result, err := ca.ModifyAffiliation(&mspclient.ModifyAffiliationRequest{
+		AffiliationRequest: mspclient.AffiliationRequest{Name: req.Name, Force: req.Force},
+		NewName:            req.NewName,
+	})
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "ca affiliation modified", "name", req.Name, "newName", req.NewName)
+	utils.Success(w, result)
+}
+
+// RemoveCAAffiliation 删除机构
+func RemoveCAAffiliation(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "remove ca affiliation start")
+	var req define.CAAffiliationRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	ca, ok := caClient(w, req.SdkConfig, req.IsGm, req.IsSM3, req.OrgName, req.CaID)
+	if !ok {
+		return
+	}
+	result, err := ca.RemoveAffiliation(&mspclient.AffiliationRequest{Name: req.Name, Force: req.Force})
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "ca affiliation removed", "name", req.Name)
+	utils.Success(w, result)
+}
+
+// caClient 按连接配置创建 CA 客户端，失败时写入错误响应
+func caClient(w http.ResponseWriter, sdkConfig string, gm, sm3 bool, orgName, caID string) (*service.CAClient, bool) {
+	err, sdk := utils.InitializeSDKBySdkId(sdkConfig, gm, sm3)
+	if err != nil {
+		utils.InvalidProfile(w, err)
+		return nil, false
+	}
+	ca, err := sdk.CAClient(orgName, caID)
+	if err != nil {
+		utils.InvalidProfile(w, err)
+		return nil, false
+	}
+	return ca, true
+}
+
+// caError 保存登记结果的身份存储错误按身份接口的规则返回，其他错误按 Fabric 错误分类
+func caError(w http.ResponseWriter, err error) {
+	if errors.Is(err, identity.ErrDisabled) || errors.Is(err, identity.ErrInvalid) {
+		identityError(w, err)
+		return
+	}
+	utils.FabricError(w, err)
+}
+
+func caAttributes(attrs []define.CAAttribute) []mspclient.Attribute {
+	result := make([]mspclient.Attribute, 0, len(attrs))
+	for _, attr := range attrs {
+		result = append(result, mspclient.Attribute{Name: attr.Name, Value: attr.Value, ECert: attr.ECert})
+	}
+	return result
+}
+
+func caAttributeRequests(attrs []define.CAAttributeRequest) []*mspclient.AttributeRequest {
+	result := make([]*mspclient.AttributeRequest, 0, len(attrs))
+	for _, attr := range attrs {
+		result = append(result, &mspclient.AttributeRequest{Name: attr.Name, Optional: attr.Optional})
+	}
+	return result
+}
+
+func caIdentityRequest(req *define.CAIdentityRequest) *mspclient.IdentityRequest {
+	return &mspclient.IdentityRequest{
+		ID:             req.ID,
+		Affiliation:    req.Affiliation,
+		Attributes:     caAttributes(req.Attributes),
+		Type:           req.Type,
+		MaxEnrollments: req.MaxEnrollments,
+		Secret:         req.Secret,
+	}
+}
@@ -0,0 +1,16 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/qctc/fabric2-api-server/certmon"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// ListCertificates 获取连接配置引用的证书与身份存储中证书的到期时间
+func ListCertificates(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "list certificates start")
+	utils.Success(w, certmon.New(define.GlobalConfig.Certificates).Scan(r.URL.Query().Get("profile")))
+}
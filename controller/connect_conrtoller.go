@@ -1,31 +1,30 @@
 package controller
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/qctc/fabric2-api-server/define"
 	"github.com/qctc/fabric2-api-server/utils"
-	"log"
+	"log/slog"
 	"net/http"
 )
 
 func TestConnection(w http.ResponseWriter, r *http.Request) {
 	// 使用已有方法测试连接
-	log.Printf("test connection start --------")
+	slog.DebugContext(r.Context(), "test connection start")
 	var req define.SdkConfigRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		utils.BadRequest(w, "Invalid request body")
 		return
 	}
-	log.Printf("test connection req ----------%+v\n", req)
 	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
 	if err != nil {
-		fmt.Printf("sdk Initialize error --------%s", err)
-		utils.BadRequest(w, fmt.Sprintf("sdk Initialize error %s", err))
+		slog.WarnContext(r.Context(), "test connection: sdk initialize failed", "error", err)
+		utils.InvalidProfile(w, err)
 		return
 	}
-	if _, err := sdk.GetContractList(); err != nil {
-		utils.Error(w, http.StatusInternalServerError, "Blockchain connection test failed", err)
+	if _, err := sdk.GetContractList(r.Context()); err != nil {
+		slog.WarnContext(r.Context(), "test connection failed", "error", err)
+		utils.FabricError(w, err)
 		return
 	}
 
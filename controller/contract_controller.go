@@ -1,33 +1,36 @@
 package controller
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/apache/rocketmq-clients/golang/v5"
-	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/qctc/fabric2-api-server/auth"
 	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/idempotency"
+	"github.com/qctc/fabric2-api-server/logging"
+	"github.com/qctc/fabric2-api-server/service"
+	"github.com/qctc/fabric2-api-server/subscription"
 	"github.com/qctc/fabric2-api-server/utils"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 )
 
 func GetContractList(w http.ResponseWriter, r *http.Request) {
-	log.Printf("get contract list start --------")
+	slog.DebugContext(r.Context(), "get contract list start")
 	var req define.SdkConfigRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		utils.BadRequest(w, "Invalid request body")
 		return
 	}
 	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
 	if err != nil {
-		utils.BadRequest(w, fmt.Sprintf("sdk Initialize error %s", err))
+		utils.InvalidProfile(w, err)
 		return
 	}
 
-	contracts, err := sdk.GetContractList()
+	contracts, err := sdk.GetContractList(r.Context())
 	if err != nil {
-		utils.InternalServerError(w, err)
+		utils.FabricError(w, err)
 		return
 	}
 
@@ -35,21 +38,21 @@ func GetContractList(w http.ResponseWriter, r *http.Request) {
 }
 
 func GetContractInfo(w http.ResponseWriter, r *http.Request) {
-	log.Printf("get contract info start --------")
+	slog.DebugContext(r.Context(), "get contract info start")
 	var req define.ContractListRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		utils.BadRequest(w, "Invalid request body")
 		return
 	}
 	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
 	if err != nil {
-		utils.BadRequest(w, fmt.Sprintf("sdk Initialize error %s", err))
+		utils.InvalidProfile(w, err)
 		return
 	}
 
-	info, err := sdk.GetContractInfo(req.ChaincodeName)
+	info, err := sdk.GetContractInfo(r.Context(), req.ChaincodeName)
 	if err != nil {
-		utils.InternalServerError(w, err)
+		utils.FabricError(w, err)
 		return
 	}
 
@@ -57,15 +60,26 @@ func GetContractInfo(w http.ResponseWriter, r *http.Request) {
 }
 
 func InvokeContract(w http.ResponseWriter, r *http.Request) {
-	log.Printf("invoke contract start --------")
+	slog.DebugContext(r.Context(), "invoke contract start")
 	var req define.ContractInvokeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		utils.BadRequest(w, "Invalid request body")
 		return
 	}
+	key, err := idempotency.RequestKey(r.Header.Get(idempotency.Header), req.IdempotencyKey)
+	if err != nil {
+		utils.BadRequest(w, err.Error())
+		return
+	}
 	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
 	if err != nil {
-		utils.BadRequest(w, fmt.Sprintf("sdk Initialize error %s", err))
+		utils.InvalidProfile(w, err)
+		return
+	}
+	// 启用调用方身份时以调用方的 Fabric 身份签名，须在幂等处理之前确定，登记失败不保存结果
+	ctx, err := sdk.CallerContext(r.Context(), auth.Caller(r))
+	if err != nil {
+		callerIdentityError(w, err)
 		return
 	}
 	// 将 args 转为 [][]byte
@@ -76,35 +90,73 @@ func InvokeContract(w http.ResponseWriter, r *http.Request) {
 	//var args [][]byte
 	//arg, _ := json.Marshal(req.Args)
 	//args = append(args, arg)
-	resp, txId, err := sdk.InvokeContract(req.ChaincodeName, req.Method, args)
+	if key != "" {
+		logging.AddFields(r.Context(), slog.String("idempotency_key", key))
+	}
+	submitCtx, cancel := idempotency.Default.Detach(ctx, key)
+	defer cancel()
+	outcome, replayed, err := idempotency.Default.Do(
+		idempotency.Key(auth.Caller(r), utils.SdkId(req.SdkConfig), key),
+		idempotency.Fingerprint(req.ChaincodeName, req.Method, req.Args),
+		func() *idempotency.Outcome {
+			resp, txId, err := sdk.InvokeContract(submitCtx, req.ChaincodeName, req.Method, args)
+			if err != nil {
+				return idempotency.Failure(err, string(txId))
+			}
+			utils.LogTxId(r.Context(), string(txId))
+			// 交易已提交，查询区块高度失败时高度留空，不影响保存的提交结果
+			height, err := sdk.GetBlockByTxID(submitCtx, string(txId))
+			if err != nil {
+				slog.WarnContext(r.Context(), "query block height of committed transaction failed", "txId", txId, "error", err)
+			}
+			return idempotency.Success(resp, string(txId), height)
+		})
 	if err != nil {
-		utils.InternalServerError(w, err)
+		code := utils.CodeIdempotencyInProgress
+		if errors.Is(err, idempotency.ErrKeyReused) {
+			code = utils.CodeIdempotencyKeyReused
+		}
+		utils.ErrorWithInfo(w, utils.NewErrorInfo(code), err.Error(), nil)
 		return
 	}
-	height, err := sdk.GetBlockByTxID(string(txId))
-	if err != nil {
-		utils.InternalServerError(w, err)
+	if replayed {
+		slog.InfoContext(r.Context(), "idempotent replay", "txId", outcome.TxId)
+		w.Header().Set(idempotency.ReplayedHeader, "true")
+	}
+	if outcome.Error != nil {
+		// 交易已发送排序但结果未知时返回交易 ID，客户端可据此查询交易状态
+		var data interface{}
+		if outcome.TxId != "" {
+			data = map[string]interface{}{"txHash": outcome.TxId}
+		}
+		utils.ErrorWithInfo(w, outcome.Error, outcome.Message, data)
 		return
 	}
 	// 解析 TransactionEnvelope.Payload（是一个 []byte）
 
 	utils.Success(w, map[string]interface{}{
-		"payload": string(resp),
-		"txHash":  string(txId),
-		"height":  height,
+		"payload": outcome.Payload,
+		"txHash":  outcome.TxId,
+		"height":  outcome.Height,
 	})
 }
 
 func QueryContract(w http.ResponseWriter, r *http.Request) {
-	log.Printf("query contract start --------")
+	slog.DebugContext(r.Context(), "query contract start")
 	var req define.ContractQueryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		utils.BadRequest(w, "Invalid request body")
 		return
 	}
 	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
 	if err != nil {
-		utils.BadRequest(w, fmt.Sprintf("sdk Initialize error %s", err))
+		utils.InvalidProfile(w, err)
+		return
+	}
+
+	ctx, err := sdk.CallerContext(r.Context(), auth.Caller(r))
+	if err != nil {
+		callerIdentityError(w, err)
 		return
 	}
 
@@ -114,15 +166,16 @@ func QueryContract(w http.ResponseWriter, r *http.Request) {
 		args[i] = []byte(arg)
 	}
 
-	resp, txId, err := sdk.QueryContract(req.ChaincodeName, req.Method, args)
+	resp, txId, err := sdk.QueryContract(ctx, req.ChaincodeName, req.Method, args)
 	if err != nil {
-		utils.InternalServerError(w, err)
+		utils.FabricError(w, err)
 		return
 	}
+	utils.LogTxId(r.Context(), string(txId))
 
-	block, err := sdk.GetBlockInfo("latest")
+	block, err := sdk.GetBlockInfo(r.Context(), "latest")
 	if err != nil {
-		utils.InternalServerError(w, err)
+		utils.FabricError(w, err)
 		return
 	}
 	// 解析 TransactionEnvelope.Payload（是一个 []byte）
@@ -135,141 +188,73 @@ func QueryContract(w http.ResponseWriter, r *http.Request) {
 }
 
 func SubscribeContractEvent(w http.ResponseWriter, r *http.Request) {
-	log.Printf("subscribe contract event start --------")
+	slog.DebugContext(r.Context(), "subscribe contract event start")
 	var req define.ContractEventSubscribeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		utils.BadRequest(w, "Invalid request body")
 		return
 	}
-	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
-	if err != nil {
-		utils.BadRequest(w, fmt.Sprintf("sdk Initialize error %s", err))
-		return
-	}
-
-	sdkId := fmt.Sprintf("%x", utils.MD5Hash(req.SdkConfig))
-	const subscriptionKeyFormat = "%s:%s:%s"
-	key := fmt.Sprintf(subscriptionKeyFormat, sdkId, req.ChaincodeName, req.EventName)
-	if define.EventSubscriptions[key] != nil {
-		utils.Success(w, map[string]interface{}{
-			"subscribeId": key,
-		})
+	if err := subscription.ValidateSpec(subscription.SubscribeSpec(req), req.FromBlock); err != nil {
+		utils.BadRequest(w, err.Error())
 		return
 	}
-
-	regID, eventCh, chainId, err := sdk.SubscribeEvent(req.ChaincodeName, req.EventName)
+	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
 	if err != nil {
-		utils.InternalServerError(w, err)
+		utils.InvalidProfile(w, err)
 		return
 	}
 
-	// 使用 sync.Map 替代 Mutex + map
-	define.SubscriptionMutex.Lock()
-	define.EventSubscriptions[key] = regID
-	define.SubscriptionMutex.Unlock()
-
-	log.Printf("Subscribed to event: %s on chaincode: %s with key: %s", req.EventName, req.ChaincodeName, key)
-
-	// 获取历史区块并发送事件
-	blocks, err := sdk.GetBlocks(req.FromBlock)
+	sdkId := utils.SdkId(req.SdkConfig)
+	sub, err := subscription.DefaultManager.Subscribe(sdkId, sdk, req, subscription.DefaultSink)
 	if err != nil {
-		log.Printf("Failed to fetch blocks starting from %d: %v", req.FromBlock, err)
-		utils.InternalServerError(w, err)
+		utils.FabricError(w, err)
 		return
 	}
-	for _, block := range blocks {
-		err := sendEventToRocketMQ(req.EventName, req.ChaincodeName, req.ChainName, block, chainId, define.GlobalProducer)
-		if err != nil {
-			continue
-		}
-	}
+
 	utils.Success(w, map[string]interface{}{
-		"subscribeId": key,
+		"subscribeId": sub.Id(),
 	})
-	// 启动监听协程，并通过 context 管理生命周期
-	ctx, cancel := context.WithCancel(context.Background())
-	define.SubscriptionContext.Store(key, cancel) // 存储 cancel 用于后续取消
-
-	go func(ctx context.Context) {
-		defer log.Printf("Stopped listener for subscription: %s", key)
-		for {
-			select {
-			case event := <-eventCh:
-				if event == nil {
-					continue
-				}
-				err = sendEventToRocketMQ(req.EventName, req.ChaincodeName, req.ChainName, event.Block, chainId, define.GlobalProducer)
-				if err != nil {
-					continue
-				}
-			case <-ctx.Done():
-				return
-			}
-		}
-	}(ctx)
 }
+
 func UnsubscribeContractEvent(w http.ResponseWriter, r *http.Request) {
-	log.Printf("unsubscribe contract event start --------")
+	slog.DebugContext(r.Context(), "unsubscribe contract event start")
 	var req define.ContractEventUnSubscribeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		utils.BadRequest(w, "Invalid request body")
 		return
 	}
-	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
-	if err != nil {
-		utils.BadRequest(w, fmt.Sprintf("sdk Initialize error %s", err))
-		return
-	}
-	// 获取 regID
-	define.SubscriptionMutex.RLocker().Lock()
-	regID, exists := define.EventSubscriptions[req.SubscribeId]
-	define.SubscriptionMutex.RUnlock()
-	if !exists {
-		utils.BadRequest(w, "subscription not found")
-		return
-	}
-	err = sdk.UnsubscribeEvent(regID)
-	if err != nil {
-		utils.InternalServerError(w, err)
+	if err := subscription.DefaultManager.Unsubscribe(req.SubscribeId); err != nil {
+		utils.Error(w, http.StatusNotFound, err.Error(), nil)
 		return
 	}
-	// 删除缓存
-	define.SubscriptionMutex.RLocker().Lock()
-	delete(define.EventSubscriptions, req.SubscribeId)
-	define.SubscriptionMutex.RUnlock()
-
-	if cancel, ok := define.SubscriptionContext.Load(req.SubscribeId); ok {
-		cancel.(context.CancelFunc)()
-	}
-	define.SubscriptionContext.Delete(req.SubscribeId)
 
-	//err = define.GlobalProducer.GracefulStop()
-	//if err != nil {
-	//	utils.InternalServerError(w, err)
-	//	return
-	//}
 	utils.Success(w, map[string]interface{}{
 		"subscribeId": req.SubscribeId,
 	})
-
 }
 
 func GetBlockInfo(w http.ResponseWriter, r *http.Request) {
-	log.Printf("get block info start --------")
+	slog.DebugContext(r.Context(), "get block info start")
 	var req define.GetBlockRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		utils.BadRequest(w, "Invalid request body")
 		return
 	}
+	if req.BlockNumber != "latest" {
+		if _, err := strconv.ParseUint(req.BlockNumber, 10, 64); err != nil {
+			utils.BadRequest(w, fmt.Sprintf("invalid blockNumber %q", req.BlockNumber))
+			return
+		}
+	}
 	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
 	if err != nil {
-		utils.BadRequest(w, fmt.Sprintf("sdk Initialize error %s", err))
+		utils.InvalidProfile(w, err)
 		return
 	}
 
-	block, err := sdk.GetBlockInfo(req.BlockNumber)
+	block, err := sdk.GetBlockInfo(r.Context(), req.BlockNumber)
 	if err != nil {
-		utils.InternalServerError(w, err)
+		utils.FabricError(w, err)
 		return
 	}
 
@@ -283,50 +268,32 @@ func GetBlockInfo(w http.ResponseWriter, r *http.Request) {
 }
 
 func GetTransactionInfo(w http.ResponseWriter, r *http.Request) {
-	log.Printf("get transaction info start --------")
+	slog.DebugContext(r.Context(), "get transaction info start")
 	var req define.GetTxRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		utils.BadRequest(w, "Invalid request body")
 		return
 	}
 	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
 	if err != nil {
-		utils.BadRequest(w, fmt.Sprintf("sdk Initialize error %s", err))
+		utils.InvalidProfile(w, err)
 		return
 	}
 
-	tx, err := sdk.GetTransactionInfo(req.TxId)
+	tx, err := sdk.GetTransactionInfo(r.Context(), req.TxId)
 	if err != nil {
-		utils.InternalServerError(w, err)
+		utils.FabricError(w, err)
 		return
 	}
 
 	utils.Success(w, tx)
 }
 
-// 封装发送事件到 RocketMQ 的逻辑
-func sendEventToRocketMQ(eventName, chaincodeName, chainName string, block *common.Block, chainId string, producer golang.Producer) error {
-	eventBytes, err := utils.GetEventByte(block, chainName, chaincodeName, chainId)
-	if err != nil {
-		log.Printf("Failed to get event byte: %v", err)
-		return err
-	}
-	for _, v := range eventBytes {
-		log.Printf("======== eventName is %s, byte is %s\n", v.EventName, v.EventByte)
-		if v.EventName == eventName && v.ChaincodeId == chaincodeName {
-			message := &golang.Message{
-				Topic: define.GlobalConfig.MQ.Topic,
-				Body:  v.EventByte,
-			}
-			_, err := producer.Send(context.TODO(), message)
-			if err != nil {
-				log.Printf("Failed to send message to RocketMQ: %v", err)
-				return err
-			} else {
-				log.Printf("Event sent to RocketMQ")
-			}
-		}
+// callerIdentityError 调用方没有 Fabric 身份且不能自动登记时拒绝访问，其他错误按登记接口的规则返回
+func callerIdentityError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrCallerIdentity) {
+		utils.ErrorWithInfo(w, utils.NewErrorInfo(utils.CodeAccessDenied), err.Error(), nil)
+		return
 	}
-
-	return nil
+	caError(w, err)
 }
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/qctc/fabric2-api-server/auth"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/health"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// Healthz 存活检查，进程能够处理请求即返回 200
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	utils.Success(w, map[string]string{"status": string(health.StatusOK)})
+}
+
+// Readyz 就绪检查，返回各项依赖的检查结果；任一检查失败时返回 503。检查结果在 health.cacheTTL 内复用，
+// 匿名调用方只返回各项检查的名称与状态，具有 manage 权限的调用方返回完整结果
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	report := health.New(define.GlobalConfig.Health).Cached(r.Context())
+	if err := auth.Authorize(r.Context(), auth.Resource{Action: auth.ActionManage}); err != nil {
+		report = report.Summary()
+	}
+	if report.Status == health.StatusFail {
+		utils.ResponseJSON(w, http.StatusServiceUnavailable, "not ready", report)
+		return
+	}
+	utils.Success(w, report)
+}
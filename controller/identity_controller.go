@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/identity"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// ListIdentities 获取身份存储中的身份，不含私钥
+func ListIdentities(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "list identities start")
+	if identity.Default == nil {
+		identityError(w, identity.ErrDisabled)
+		return
+	}
+	utils.Success(w, identity.Default.List(r.URL.Query().Get("mspId")))
+}
+
+// PutIdentity 导入身份，私钥以主密钥加密后保存
+func PutIdentity(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "put identity start")
+	var req define.IdentityPutRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	id, err := identity.Default.Put(req.MspId, req.Name, []byte(req.Certificate), []byte(req.PrivateKey))
+	if err != nil {
+		identityError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "identity stored", "mspId", id.MSPID, "name", id.Name, "ski", id.SKI)
+	utils.Success(w, id)
+}
+
+// GetIdentity 获取单个身份，不含私钥
+func GetIdentity(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "get identity start")
+	vars := mux.Vars(r)
+	id, err := identity.Default.Get(vars["mspId"], vars["name"])
+	if err != nil {
+		identityError(w, err)
+		return
+	}
+	utils.Success(w, id)
+}
+
+// DeleteIdentity 删除身份
+func DeleteIdentity(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "delete identity start")
+	vars := mux.Vars(r)
+	if err := identity.Default.Delete(vars["mspId"], vars["name"]); err != nil {
+		identityError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "identity deleted", "mspId", vars["mspId"], "name", vars["name"])
+	utils.Success(w, nil)
+}
+
+// identityError 身份不存在或未启用存储返回 404，参数错误返回 400，写入存储文件失败返回 500
+func identityError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, identity.ErrNotFound), errors.Is(err, identity.ErrDisabled):
+		utils.Error(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, identity.ErrInvalid):
+		utils.BadRequest(w, err.Error())
+	default:
+		utils.InternalServerError(w, err)
+	}
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/qctc/fabric2-api-server/auth"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/service"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// CreateProposal 离线签名：以调用方证书创建未签名的合约调用提案
+func CreateProposal(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "create proposal start")
+	var req define.ContractProposalRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
+	if err != nil {
+		utils.InvalidProfile(w, err)
+		return
+	}
+	args := make([][]byte, len(req.Args))
+	for i, arg := range req.Args {
+		args[i] = []byte(arg)
+	}
+	proposal, err := sdk.CreateProposal(r.Context(), req.MspId, []byte(req.Certificate), req.ChaincodeName, req.Method, args)
+	if err != nil {
+		offlineError(w, err)
+		return
+	}
+	utils.LogTxId(r.Context(), proposal.TxHash)
+	utils.Success(w, proposal)
+}
+
+// EndorseProposal 离线签名：校验提案签名并收集背书，返回未签名的交易
+func EndorseProposal(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "endorse proposal start")
+	var req define.SignedProposalRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	chaincodeName, function, err := service.ProposalInvocation(req.Proposal)
+	if err != nil {
+		offlineError(w, err)
+		return
+	}
+	if !authorizeInvocation(w, r, req.SdkConfig, chaincodeName, function) {
+		return
+	}
+	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
+	if err != nil {
+		utils.InvalidProfile(w, err)
+		return
+	}
+	tx, err := sdk.EndorseProposal(r.Context(), req.Proposal, req.Signature)
+	if err != nil {
+		offlineError(w, err)
+		return
+	}
+	utils.LogTxId(r.Context(), tx.TxHash)
+	utils.Success(w, tx)
+}
+
+// SubmitTransaction 离线签名：校验交易签名，发送排序并等待提交
+func SubmitTransaction(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "submit transaction start")
+	var req define.SignedTransactionRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	chaincodeName, function, err := service.TransactionInvocation(req.Payload)
+	if err != nil {
+		offlineError(w, err)
+		return
+	}
+	if !authorizeInvocation(w, r, req.SdkConfig, chaincodeName, function) {
+		return
+	}
+	err, sdk := utils.InitializeSDKBySdkId(req.SdkConfig, req.IsGm, req.IsSM3)
+	if err != nil {
+		utils.InvalidProfile(w, err)
+		return
+	}
+	result, txId, err := sdk.SubmitTransaction(r.Context(), req.Payload, req.Signature)
+	if txId != "" {
+		utils.LogTxId(r.Context(), string(txId))
+	}
+	if err != nil {
+		// 交易已发送排序但结果未知时返回交易 ID，客户端可据此查询交易状态
+		if txId != "" {
+			utils.ErrorWithInfo(w, utils.ClassifyError(err), err.Error(), map[string]interface{}{"txHash": txId})
+			return
+		}
+		offlineError(w, err)
+		return
+	}
+	utils.Success(w, result)
+}
+
+// authorizeInvocation 请求体中没有合约名称与方法，按提案或交易中解析出的调用授权
+func authorizeInvocation(w http.ResponseWriter, r *http.Request, sdkConfig, chaincodeName, function string) bool {
+	utils.LogRequestFields(r.Context(), sdkConfig, "", chaincodeName, function)
+	if err := auth.Authorize(r.Context(), auth.ProfileResource(auth.ActionInvoke, sdkConfig, "", chaincodeName, function)); err != nil {
+		utils.Error(w, http.StatusForbidden, err.Error(), nil)
+		return false
+	}
+	return true
+}
+
+// offlineError 提案、交易或签名不合法时返回 400，其余按 Fabric 错误分类
+func offlineError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrInvalidTransaction) {
+		utils.BadRequest(w, err.Error())
+		return
+	}
+	utils.FabricError(w, err)
+}
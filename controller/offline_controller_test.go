@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/qctc/fabric2-api-server/auth"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/service"
+)
+
+// 测试提案没有通道头，授权通过的请求在服务校验提案时失败，不访问网络
+const offlineProfile = `
+name: test
+channels:
+  mychannel:
+    peers: {}
+`
+
+func TestOfflineInvocation(t *testing.T) {
+	proposal := newProposal(t, "basic", "Transfer")
+	chaincodeName, function, err := service.ProposalInvocation(proposal)
+	if err != nil || chaincodeName != "basic" || function != "Transfer" {
+		t.Errorf("ProposalInvocation = %q, %q, %v", chaincodeName, function, err)
+	}
+	chaincodeName, function, err = service.TransactionInvocation(newTransaction(t, proposal))
+	if err != nil || chaincodeName != "basic" || function != "Transfer" {
+		t.Errorf("TransactionInvocation = %q, %q, %v", chaincodeName, function, err)
+	}
+	if _, _, err := service.ProposalInvocation([]byte("garbage")); err == nil {
+		t.Error("ProposalInvocation accepted garbage")
+	}
+	if _, _, err := service.ProposalInvocation(newProposal(t, "basic", "")); err == nil {
+		t.Error("ProposalInvocation accepted proposal without function")
+	}
+	// 没有背书的交易不能提交
+	payload, _ := proto.Marshal(&common.Payload{Data: mustMarshal(t, &pb.Transaction{})})
+	if _, _, err := service.TransactionInvocation(payload); err == nil {
+		t.Error("TransactionInvocation accepted transaction without actions")
+	}
+}
+
+func TestOfflineAuthorization(t *testing.T) {
+	digest := sha256.Sum256([]byte("secret"))
+	guard, err := auth.New(define.AuthConfig{
+		Enabled: true,
+		APIKeys: []define.APIKeyConfig{{Name: "app", SHA256: hex.EncodeToString(digest[:])}},
+		Policies: []define.PolicyConfig{
+			{Subjects: []string{"apikey:app"}, Chaincodes: []string{"basic"}, Functions: []string{"Transfer"}, Actions: []string{"invoke"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	endorse := guard.Authenticated(EndorseProposal)
+	submit := guard.Authenticated(SubmitTransaction)
+
+	allowed, denied := newProposal(t, "basic", "Transfer"), newProposal(t, "basic", "Delete")
+	other := newProposal(t, "other", "Transfer")
+	tests := []struct {
+		name    string
+		handler http.Handler
+		body    interface{}
+		want    int
+	}{
+		{"endorse allowed", endorse, define.SignedProposalRequest{SdkConfig: offlineProfile, Proposal: allowed, Signature: []byte("sig")}, 0},
+		{"endorse denied function", endorse, define.SignedProposalRequest{SdkConfig: offlineProfile, Proposal: denied, Signature: []byte("sig")}, http.StatusForbidden},
+		{"endorse denied chaincode", endorse, define.SignedProposalRequest{SdkConfig: offlineProfile, Proposal: other, Signature: []byte("sig")}, http.StatusForbidden},
+		{"endorse invalid proposal", endorse, define.SignedProposalRequest{SdkConfig: offlineProfile, Proposal: []byte("garbage"), Signature: []byte("sig")}, http.StatusBadRequest},
+		{"submit allowed", submit, define.SignedTransactionRequest{SdkConfig: offlineProfile, Payload: newTransaction(t, allowed), Signature: []byte("sig")}, 0},
+		{"submit denied function", submit, define.SignedTransactionRequest{SdkConfig: offlineProfile, Payload: newTransaction(t, denied), Signature: []byte("sig")}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+			r.Header.Set(auth.APIKeyHeader, "secret")
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, r)
+			switch {
+			case tt.want != 0 && w.Code != tt.want:
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			case tt.want == 0 && (w.Code == http.StatusForbidden || strings.Contains(w.Body.String(), auth.ErrAccessDenied.Error())):
+				t.Errorf("authorized request was denied: %d %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// newProposal 生成调用 chaincodeName 的 function 方法的提案，只包含授权需要的字段
+func newProposal(t *testing.T, chaincodeName, function string) []byte {
+	t.Helper()
+	var args [][]byte
+	if function != "" {
+		args = [][]byte{[]byte(function), []byte("a")}
+	}
+	input := mustMarshal(t, &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		ChaincodeId: &pb.ChaincodeID{Name: chaincodeName},
+		Input:       &pb.ChaincodeInput{Args: args},
+	}})
+	return mustMarshal(t, &pb.Proposal{Payload: mustMarshal(t, &pb.ChaincodeProposalPayload{Input: input})})
+}
+
+// newTransaction 生成包含一个背书的交易负载
+func newTransaction(t *testing.T, proposal []byte) []byte {
+	t.Helper()
+	p := &pb.Proposal{}
+	if err := proto.Unmarshal(proposal, p); err != nil {
+		t.Fatal(err)
+	}
+	action := mustMarshal(t, &pb.ChaincodeActionPayload{
+		ChaincodeProposalPayload: p.Payload,
+		Action:                   &pb.ChaincodeEndorsedAction{Endorsements: []*pb.Endorsement{{Endorser: []byte("peer0")}}},
+	})
+	tx := mustMarshal(t, &pb.Transaction{Actions: []*pb.TransactionAction{{Payload: action}}})
+	return mustMarshal(t, &common.Payload{Header: &common.Header{}, Data: tx})
+}
+
+func mustMarshal(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+	data, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
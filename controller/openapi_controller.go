@@ -0,0 +1,14 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/qctc/fabric2-api-server/api"
+)
+
+// GetOpenAPI 返回接口的 OpenAPI 文档
+func GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(api.Spec)
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/identity"
+	"github.com/qctc/fabric2-api-server/profile"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// CreateProfile 由简化的描述生成连接配置并加入连接池
+func CreateProfile(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "create profile start")
+	var req define.ProfileRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	spec := &profile.Spec{Channel: req.Channel, Organization: req.Organization}
+	for _, org := range req.Organizations {
+		spec.Organizations = append(spec.Organizations, profileOrganization(org))
+	}
+	if req.ClientTLS.Certificate != "" || req.ClientTLS.PrivateKey != "" {
+		spec.ClientTLS = &profile.KeyPair{Certificate: req.ClientTLS.Certificate, PrivateKey: req.ClientTLS.PrivateKey}
+	}
+	registerProfile(w, r, spec, req.IsGm, req.IsSM3)
+}
+
+// ImportProfile 由 cryptogen 或 Fabric CA 生成的 organizations 目录归档生成连接配置并加入连接池
+func ImportProfile(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "import profile start")
+	var req define.ProfileImportRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	spec, err := profile.FromArchive(bytes.NewReader(req.Archive), profile.ArchiveOptions{
+		Channel:      req.Channel,
+		Organization: req.Organization,
+		MSPIDs:       req.MspIds,
+		Endpoints:    req.Endpoints,
+		ServerNames:  req.ServerNames,
+	})
+	if err != nil {
+		profileError(w, err)
+		return
+	}
+	registerProfile(w, r, spec, req.IsGm, req.IsSM3)
+}
+
+// DiagnoseProfile 逐步诊断连接配置，连接配置的问题在诊断报告中返回
+func DiagnoseProfile(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "diagnose profile start")
+	var req define.ProfileDiagnoseRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	report := profile.Diagnose(r.Context(), req.SdkConfig, profile.DiagnoseOptions{
+		GM:        req.IsGm,
+		SM3:       req.IsSM3,
+		LintOnly:  req.LintOnly,
+		Chaincode: req.ChaincodeName,
+	})
+	slog.InfoContext(r.Context(), "profile diagnosed", "profile", report.SdkId, "status", report.Status)
+	utils.Success(w, report)
+}
+
+func registerProfile(w http.ResponseWriter, r *http.Request, spec *profile.Spec, gm, sm3 bool) {
+	result, err := profile.Register(spec, gm, sm3)
+	if err != nil {
+		slog.WarnContext(r.Context(), "register profile failed", "error", err)
+		profileError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "profile registered", "profile", result.SdkId, "channel", spec.Channel, "storedIdentities", len(result.StoredIdentities))
+	utils.Success(w, result)
+}
+
+func profileOrganization(org define.ProfileOrganization) profile.Organization {
+	o := profile.Organization{Name: org.Name, MSPID: org.MspId}
+	for _, n := range org.Peers {
+		o.Peers = append(o.Peers, profileNode(n))
+	}
+	for _, n := range org.Orderers {
+		o.Orderers = append(o.Orderers, profileNode(n))
+	}
+	for _, u := range org.Users {
+		o.Users = append(o.Users, profile.User{Name: u.Name, Certificate: u.Certificate, PrivateKey: u.PrivateKey})
+	}
+	return o
+}
+
+func profileNode(n define.ProfileNode) profile.Node {
+	return profile.Node{Name: n.Name, URL: n.Url, TLSCACert: n.TlsCACert, ServerName: n.ServerName}
+}
+
+// profileError 描述、归档或用户身份不合法返回 400，保存身份失败返回 500
+func profileError(w http.ResponseWriter, err error) {
+	if errors.Is(err, profile.ErrInvalid) || errors.Is(err, identity.ErrInvalid) {
+		utils.BadRequest(w, err.Error())
+		return
+	}
+	utils.InternalServerError(w, err)
+}
@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/qctc/fabric2-api-server/auth"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/service"
+	"github.com/qctc/fabric2-api-server/subscription"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// sseHeartbeatInterval SSE 心跳间隔，避免中间代理因空闲断开连接
+const sseHeartbeatInterval = 15 * time.Second
+
+var upgrader = websocket.Upgrader{CheckOrigin: checkOrigin}
+
+// checkOrigin 与 gorilla 默认的同源检查相同，另外接受 server.allowedOrigins 中的来源，
+// 避免其他站点的页面借用浏览器中的凭据建立 WebSocket 连接
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	if define.GlobalConfig == nil {
+		return false
+	}
+	for _, allowed := range define.GlobalConfig.Server.AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamsCtx 在服务关闭时取消，用于结束长连接事件流
+var streamsCtx, stopStreams = context.WithCancel(context.Background())
+
+// StopStreams 结束全部事件流连接，供服务关闭时调用
+func StopStreams() {
+	stopStreams()
+}
+
+// streamContext 返回在客户端断开或服务关闭时结束的上下文
+func streamContext(r *http.Request) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(r.Context())
+	stop := context.AfterFunc(streamsCtx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// StreamEventsSSE 通过 Server-Sent Events 推送事件，支持 Last-Event-ID 断点续传
+func StreamEventsSSE(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "stream events over sse start")
+	req := streamRequestFromQuery(r)
+	if r.Method == http.MethodPost {
+		if err := utils.DecodeJSON(r.Body, &req); err != nil {
+			utils.BadRequest(w, "Invalid request body")
+			return
+		}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.Error(w, http.StatusInternalServerError, "streaming unsupported", nil)
+		return
+	}
+	sdk, err := streamSDK(req)
+	if errors.Is(err, utils.ErrSDKNotInitialized) {
+		utils.Error(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.InvalidProfile(w, err)
+		return
+	}
+	ctx, cancel := streamContext(r)
+	defer cancel()
+	messages, err := subscription.OpenStream(ctx, sdk, req, lastEventId(r, req))
+	if errors.Is(err, subscription.ErrInvalidSpec) {
+		utils.BadRequest(w, err.Error())
+		return
+	}
+	if err != nil {
+		utils.FabricError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case m, ok := <-messages:
+			if !ok {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", m.Id, m.Event, m.Data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// StreamEventsWS 通过 WebSocket 推送事件；未在查询参数中携带 SDK 信息时，读取第一条消息作为请求参数
+func StreamEventsWS(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "stream events over websocket start")
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		slog.WarnContext(r.Context(), "websocket upgrade failed", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	req := streamRequestFromQuery(r)
+	if req.SdkId == "" && req.SdkConfig == "" {
+		_, body, err := conn.NextReader()
+		if err == nil {
+			err = utils.DecodeJSON(body, &req)
+		}
+		if err != nil {
+			closeWS(conn, websocket.CloseUnsupportedData, "Invalid request body")
+			return
+		}
+	}
+	// 请求参数可能来自第一条消息，需在此处授权并记录请求字段
+	utils.LogRequestFields(r.Context(), req.SdkConfig, req.SdkId, req.ChaincodeName, "")
+	if err := auth.Authorize(r.Context(), auth.EventStreamResource(req)); err != nil {
+		closeWS(conn, websocket.ClosePolicyViolation, err.Error())
+		return
+	}
+	sdk, err := streamSDK(req)
+	if err != nil {
+		closeWS(conn, websocket.ClosePolicyViolation, err.Error())
+		return
+	}
+
+	ctx, cancel := streamContext(r)
+	defer cancel()
+	messages, err := subscription.OpenStream(ctx, sdk, req, lastEventId(r, req))
+	if err != nil {
+		closeWS(conn, websocket.ClosePolicyViolation, err.Error())
+		return
+	}
+
+	// 读取客户端消息以感知连接关闭
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case m, ok := <-messages:
+			if !ok {
+				closeWS(conn, websocket.CloseGoingAway, "event stream closed")
+				return
+			}
+			if err := conn.WriteJSON(m); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			closeWS(conn, websocket.CloseGoingAway, "server shutting down")
+			return
+		}
+	}
+}
+
+func closeWS(conn *websocket.Conn, code int, text string) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(time.Second))
+}
+
+func streamRequestFromQuery(r *http.Request) define.EventStreamRequest {
+	q := r.URL.Query()
+	return define.EventStreamRequest{
+		SdkId:         q.Get("sdkId"),
+		Type:          q.Get("type"),
+		ChaincodeName: q.Get("chaincodeName"),
+		EventName:     q.Get("eventName"),
+		ChainName:     q.Get("chainName"),
+		FromBlock:     q.Get("fromBlock"),
+		TxId:          q.Get("txId"),
+		LastEventId:   q.Get("lastEventId"),
+	}
+}
+
+// streamSDK 事件流使用的 SDK
+func streamSDK(req define.EventStreamRequest) (*service.Fabric2Service, error) {
+	return utils.SDKForStream(req.SdkId, req.SdkConfig, req.IsGm, req.IsSM3)
+}
+
+func lastEventId(r *http.Request, req define.EventStreamRequest) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return req.LastEventId
+}
@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	prev := define.GlobalConfig
+	define.GlobalConfig = &define.Config{}
+	define.GlobalConfig.Server.AllowedOrigins = []string{"https://console.example.com/"}
+	defer func() { define.GlobalConfig = prev }()
+
+	for _, tc := range []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"http://api.example.com:9090", true},
+		{"https://console.example.com", true},
+		{"https://evil.example.com", false},
+		{"http://console.example.com", false},
+		{"://bad", false},
+	} {
+		r := httptest.NewRequest("GET", "http://api.example.com:9090/api/v1/events/ws", nil)
+		if tc.origin != "" {
+			r.Header.Set("Origin", tc.origin)
+		}
+		if got := checkOrigin(r); got != tc.want {
+			t.Errorf("checkOrigin(%q) = %v, want %v", tc.origin, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/qctc/fabric2-api-server/subscription"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// ListSubscriptions 获取全部事件订阅及其投递状态
+func ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "list subscriptions start")
+	utils.Success(w, subscription.DefaultManager.Infos(r.Context()))
+}
+
+// GetSubscription 获取单个事件订阅的投递状态
+func GetSubscription(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "get subscription start")
+	sub, ok := lookupSubscription(w, r)
+	if !ok {
+		return
+	}
+	utils.Success(w, sub.Info(r.Context()))
+}
+
+// PauseSubscription 暂停事件投递
+func PauseSubscription(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "pause subscription start")
+	sub, ok := lookupSubscription(w, r)
+	if !ok {
+		return
+	}
+	sub.Pause()
+	utils.Success(w, sub.Info(r.Context()))
+}
+
+// ResumeSubscription 恢复事件投递，并补发暂停期间的区块
+func ResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "resume subscription start")
+	sub, ok := lookupSubscription(w, r)
+	if !ok {
+		return
+	}
+	sub.Resume()
+	utils.Success(w, sub.Info(r.Context()))
+}
+
+func lookupSubscription(w http.ResponseWriter, r *http.Request) (*subscription.Subscription, bool) {
+	sub, err := subscription.DefaultManager.Get(mux.Vars(r)["id"])
+	if errors.Is(err, subscription.ErrNotFound) {
+		utils.Error(w, http.StatusNotFound, err.Error(), nil)
+		return nil, false
+	}
+	if err != nil {
+		utils.InternalServerError(w, err)
+		return nil, false
+	}
+	return sub, true
+}
@@ -1,108 +1,213 @@
 package define
 
 import (
-	"github.com/apache/rocketmq-clients/golang/v5"
-	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
-	"sync"
+	"time"
 )
 
 var (
-	GlobalConfig   *Config
-	GlobalProducer golang.Producer
-
-	EventSubscriptions  = make(map[string]fab.Registration) // key: uuid
-	SubscriptionMutex   = &sync.RWMutex{}
-	SubscriptionContext = sync.Map{}
+	GlobalConfig *Config
 )
 
 type MQConfig struct {
-	Type     string `yaml:"type"`     // 消息队列类型（如 rocketmq）
+	Type     string `yaml:"type"`     // 消息队列类型，目前只支持 rocketmq，为空时按 rocketmq 处理
 	Host     string `yaml:"host"`     // 主机地址
 	Port     int    `yaml:"port"`     // 端口
 	UserName string `yaml:"userName"` // 用户名
 	Password string `yaml:"password"` // 密码
 	Topic    string `yaml:"topic"`    // 主题
-	Group    string `yaml:"group"`    // 消费组
+	Group    string `yaml:"group"`    // 消费组，仅供下游消费者参考，服务本身只发送消息不使用该项
 }
 
-type Config struct {
-	Server struct {
-		Port int `yaml:"port"`
-	} `yaml:"server"`
-	ChainType string `yaml:"chainType"`
+// SubscriptionConfig 事件订阅重连配置，重连时由连接配置中 eventService 的节点选择策略决定连接的事件节点
+type SubscriptionConfig struct {
+	ReconnectInitialDelay time.Duration `yaml:"reconnectInitialDelay"` // 首次重连等待时间
+	ReconnectMaxDelay     time.Duration `yaml:"reconnectMaxDelay"`     // 重连最大等待时间
+	ReconnectMaxAttempts  int           `yaml:"reconnectMaxAttempts"`  // 最大连续重连次数，0 表示不限
+	CheckpointFile        string        `yaml:"checkpointFile"`        // 订阅断点文件，为空时不持久化
+}
 
-	MQ MQConfig `yaml:"mq"` // 添加 mq 的配置
+// AuthConfig 接口认证与授权配置，未启用时不校验调用方身份
+type AuthConfig struct {
+	Enabled  bool           `yaml:"enabled"`
+	APIKeys  []APIKeyConfig `yaml:"apiKeys"`  // 静态 API Key
+	JWT      JWTConfig      `yaml:"jwt"`      // JWT/OIDC 令牌
+	MTLS     MTLSConfig     `yaml:"mtls"`     // 客户端证书
+	Policies []PolicyConfig `yaml:"policies"` // 授权规则，任一规则允许即放行
+}
+
+// APIKeyConfig 静态 API Key，只保存 Key 的 SHA-256 摘要
+type APIKeyConfig struct {
+	Name   string `yaml:"name"`   // 调用方名称，授权规则中以 apikey:<name> 引用
+	SHA256 string `yaml:"sha256"` // Key 的 SHA-256 十六进制摘要
+}
+
+// JWTConfig JWT 校验配置，jwksFile 为空时不启用
+type JWTConfig struct {
+	JWKSFile     string        `yaml:"jwksFile"`     // 本地 JWKS 文件
+	Issuer       string        `yaml:"issuer"`       // 期望的 iss，为空时不校验
+	Audience     string        `yaml:"audience"`     // 期望的 aud，为空时不校验
+	SubjectClaim string        `yaml:"subjectClaim"` // 调用方标识所在的声明，默认 sub
+	Algorithms   []string      `yaml:"algorithms"`   // 允许的签名算法，为空时允许全部非对称算法
+	Leeway       time.Duration `yaml:"leeway"`       // 时间校验容差
+}
+
+// MTLSConfig 客户端证书认证配置，支持 SM2 证书
+type MTLSConfig struct {
+	Enabled          bool     `yaml:"enabled"`
+	ClientCAFile     string   `yaml:"clientCAFile"`     // 签发客户端证书的 CA 证书（PEM）
+	ClientCertHeader string   `yaml:"clientCertHeader"` // 由 TLS 终止代理转发客户端证书（URL 编码的 PEM）时使用的请求头
+	TrustedProxies   []string `yaml:"trustedProxies"`   // 允许转发客户端证书的代理地址（CIDR）
 }
 
-type SdkConfigRequest struct {
-	SdkConfig string `json:"sdkConfig"`
-	IsGm      bool   `yaml:"isGM"`
-	IsSM3     bool   `yaml:"isSM3"`
+// PolicyConfig 授权规则，各字段支持通配符 *，为空表示不限制（subjects 除外）
+type PolicyConfig struct {
+	Subjects   []string `yaml:"subjects"`   // 调用方，如 apikey:team-a、jwt:alice、cert:client1
+	Profiles   []string `yaml:"profiles"`   // 连接配置标识 sdkId
+	Channels   []string `yaml:"channels"`   // 通道
+	Chaincodes []string `yaml:"chaincodes"` // 合约
+	Functions  []string `yaml:"functions"`  // 合约方法
+	Actions    []string `yaml:"actions"`    // query、invoke、read、subscribe、manage
 }
 
-// ContractInvokeRequest 合约调用请求参数
-type ContractInvokeRequest struct {
-	SdkConfig     string   `json:"sdkConfig"`
-	IsGm          bool     `yaml:"isGM"`
-	IsSM3         bool     `yaml:"isSM3"`
-	ChaincodeName string   `json:"chaincodeName"`
-	Method        string   `json:"method"`
-	Args          []string `json:"args"`
+// RateLimitConfig 按调用方、连接配置与合约分别限制请求速率与并发，超出时排队，排队超时返回 429
+type RateLimitConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	QueueTimeout time.Duration `yaml:"queueTimeout"` // 等待令牌或并发名额的最长时间，0 表示不排队
+	Caller       LimitConfig   `yaml:"caller"`       // 每个调用方（认证身份，未启用认证时为客户端地址）
+	Profile      LimitConfig   `yaml:"profile"`      // 每个连接配置（sdkId），订阅补齐历史区块也计入
+	Chaincode    LimitConfig   `yaml:"chaincode"`    // 每个连接配置下的每个合约
 }
 
-// ContractQueryRequest 合约查询请求参数
-type ContractQueryRequest struct {
-	SdkConfig     string   `json:"sdkConfig"`
-	IsGm          bool     `yaml:"isGM"`
-	IsSM3         bool     `yaml:"isSM3"`
-	ChaincodeName string   `json:"ChaincodeName"`
-	Method        string   `json:"method"`
-	Args          []string `json:"args"` // 假设是字符串数组，后续可转为字节
+// LimitConfig 单个维度的配额，各项为 0 表示不限制
+type LimitConfig struct {
+	Rate        float64 `yaml:"rate"`        // 每秒请求数（令牌桶补充速率）
+	Burst       int     `yaml:"burst"`       // 令牌桶容量，默认为 rate 向上取整
+	MaxInFlight int     `yaml:"maxInFlight"` // 最大并发请求数
 }
 
-// ContractEventSubscribeRequest 合约事件订阅请求参数
-type ContractEventSubscribeRequest struct {
-	SdkConfig     string `json:"sdkConfig"`
-	IsGm          bool   `yaml:"isGM"`
-	IsSM3         bool   `yaml:"isSM3"`
-	ChaincodeName string `json:"chaincodeName"`
-	EventName     string `json:"eventName"`
-	ChainName     string `json:"chainName"`
-	FromBlock     string `json:"fromBlock"`
-	EndBlock      string `json:"endBlock"`
+// LogConfig 日志配置，日志以 JSON 格式输出到标准输出
+type LogConfig struct {
+	Level string `yaml:"level"` // debug、info、warn 或 error，默认 info
 }
 
-type ContractEventUnSubscribeRequest struct {
-	SdkConfig   string `json:"sdkConfig"`
-	IsGm        bool   `yaml:"isGM"`
-	IsSM3       bool   `yaml:"isSM3"`
-	SubscribeId string `json:"subscribeEventId"`
+// TracingConfig OpenTelemetry 链路追踪配置，通过 OTLP/HTTP 导出
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled"`
+	Endpoint    string  `yaml:"endpoint"`    // OTLP/HTTP 接收地址，如 localhost:4318
+	Insecure    bool    `yaml:"insecure"`    // 不使用 TLS 连接接收端
+	ServiceName string  `yaml:"serviceName"` // 默认 fabric2-api-server
+	SampleRatio float64 `yaml:"sampleRatio"` // 无上游采样决定时的采样比例，默认 1
 }
 
-type ContractListRequest struct {
-	SdkConfig     string `json:"sdkConfig"`
-	IsGm          bool   `yaml:"isGM"`
-	IsSM3         bool   `yaml:"isSM3"`
-	ChaincodeName string `json:"chaincodeName"`
+// HealthConfig 就绪检查（/readyz）配置
+type HealthConfig struct {
+	Timeout             time.Duration `yaml:"timeout"`             // 单次就绪检查的最长时间，默认 3s
+	CacheTTL            time.Duration `yaml:"cacheTTL"`            // 检查结果的缓存时间，缓存时间内的探测不再连接节点，默认 5s
+	SubscriptionLagWarn uint64        `yaml:"subscriptionLagWarn"` // 订阅落后的区块数超过该值时为 degraded，0 表示不检查
+	SubscriptionLagFail uint64        `yaml:"subscriptionLagFail"` // 订阅落后的区块数超过该值时为未就绪，0 表示不检查
 }
 
-type GetBlockRequest struct {
-	SdkConfig   string `json:"sdkConfig"`
-	IsGm        bool   `yaml:"isGM"`
-	IsSM3       bool   `yaml:"isSM3"`
-	BlockNumber string `json:"blockNumber"`
-	OnlyHeader  bool   `json:"onlyHeader"`
+// IdempotencyConfig 交易提交的幂等键配置，启用后以相同幂等键重试的请求返回首次提交的结果
+type IdempotencyConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Retention     time.Duration `yaml:"retention"`     // 幂等记录自首次使用起的保留时间，默认 24h
+	File          string        `yaml:"file"`          // 幂等记录文件，为空时只保存在内存中，进程重启后丢失
+	SubmitTimeout time.Duration `yaml:"submitTimeout"` // 带幂等键的提交不随请求取消，提交与查询区块高度的最长时间，默认 2m
 }
 
-type GetTxRequest struct {
-	SdkConfig   string `json:"sdkConfig"`
-	IsGm        bool   `yaml:"isGM"`
-	IsSM3       bool   `yaml:"isSM3"`
-	TxId        string `json:"txId"`
-	BlockNumber uint64 `json:"blockNumber"`
-	IsVerified  bool   `json:"isVerified"`
+// ServerTLSConfig 服务监听器的 TLS 配置，国密 TLS 使用 SM2 签名与加密双证书，SM4/SM3 加密套件
+type ServerTLSConfig struct {
+	Mode         string `yaml:"mode"`         // 空或 none 为明文，tls 为标准 TLS，gmtls 为国密 TLS
+	CertFile     string `yaml:"certFile"`     // 服务端证书（PEM），国密 TLS 时为签名证书
+	KeyFile      string `yaml:"keyFile"`      // 服务端私钥（PEM），国密 TLS 时为签名私钥
+	EncCertFile  string `yaml:"encCertFile"`  // 国密 TLS 加密证书，为空时签名证书兼作加密证书
+	EncKeyFile   string `yaml:"encKeyFile"`   // 国密 TLS 加密私钥
+	ClientCAFile string `yaml:"clientCAFile"` // 校验客户端证书的 CA 证书（PEM），国密 TLS 时为 SM2 CA
+	ClientAuth   string `yaml:"clientAuth"`   // none、request（提供时校验）或 require，配置了 clientCAFile 时默认 require
+	MinVersion   string `yaml:"minVersion"`   // 标准 TLS 的最低版本 1.2 或 1.3，默认 1.2
 }
 
+// IdentityStoreConfig 托管身份存储配置，私钥以主密钥加密后保存
+type IdentityStoreConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	File      string `yaml:"file"`      // 身份存储文件
+	Cipher    string `yaml:"cipher"`    // 私钥加密算法 sm4 或 aes，均为 GCM 模式，默认 sm4
+	MasterKey string `yaml:"masterKey"` // 主密钥（十六进制或 base64），sm4 为 16 字节，aes 为 16、24 或 32 字节；建议通过环境变量 FABRIC2_IDENTITY_STORE_MASTER_KEY 提供
+}
+
+// HSMConfig PKCS#11 硬件安全模块配置，启用后 SDK 按证书公钥的 SKI 在 HSM 中查找私钥并在 HSM 内签名
+type HSMConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Library    string `yaml:"library"`    // PKCS#11 动态库路径
+	Label      string `yaml:"label"`      // 令牌标签，按标签选择槽位
+	Pin        string `yaml:"pin"`        // 用户 PIN，建议通过环境变量 FABRIC2_HSM_PIN 提供
+	SoftVerify bool   `yaml:"softVerify"` // 验签在软件中完成，减少 HSM 调用
+	// 国密 HSM 的 SM2 密钥类型（CKK_*）与签名机制（CKM_*）由厂商定义，须同时配置，均为 0 时不支持 SM2 私钥。
+	// 签名机制的输入为按 SM2 规则计算的摘要 e = SM3(Z || M)，输出为 r || s
+	SM2KeyType   uint `yaml:"sm2KeyType"`
+	SM2Mechanism uint `yaml:"sm2Mechanism"`
+}
+
+// UserIdentitiesConfig 调用方身份配置，启用后合约调用与查询以调用方各自的 Fabric 身份签名，身份名称为 <认证方式>.<调用方名称>
+type UserIdentitiesConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	AutoEnroll  bool   `yaml:"autoEnroll"`  // 身份不存在时通过组织的 Fabric CA 注册并登记，否则须预先导入身份存储
+	CAID        string `yaml:"caId"`        // 连接配置 certificateAuthorities 中的 CA，为空时使用组织的第一个 CA
+	Type        string `yaml:"type"`        // 注册的身份类型，为空时使用 CA 的默认值 client
+	Affiliation string `yaml:"affiliation"` // 注册的机构，为空时使用 registrar 的机构
+}
+
+// CertificatesConfig 证书到期监控配置
+type CertificatesConfig struct {
+	Interval time.Duration `yaml:"interval"` // 检查连接池中连接配置与身份存储证书的间隔，默认 1h，负数表示不定期检查
+	Warning  time.Duration `yaml:"warning"`  // 距到期不足该时长时记录警告，默认 720h
+	Critical time.Duration `yaml:"critical"` // 距到期不足该时长时记录错误，默认 168h
+}
+
+type Config struct {
+	Server struct {
+		Port            int           `yaml:"port"`
+		ShutdownTimeout time.Duration `yaml:"shutdownTimeout"` // 优雅关闭的最长等待时间
+		GRPCPort        int           `yaml:"grpcPort"`        // gRPC 接口端口，0 表示不启用
+		// RequestTimeout 调用方未指定超时（X-Request-Timeout 请求头或 gRPC deadline）时单个请求的最长处理时间，0 表示不限制
+		RequestTimeout time.Duration `yaml:"requestTimeout"`
+		// MaxRequestTimeout 调用方可指定的最长超时，0 表示不限制
+		MaxRequestTimeout time.Duration `yaml:"maxRequestTimeout"`
+		// TLS HTTP 与 gRPC 监听器的传输加密，默认明文
+		TLS ServerTLSConfig `yaml:"tls"`
+		// AllowedOrigins WebSocket 事件流允许的跨站来源，如 https://console.example.com；
+		// 未列出的来源只接受同源请求与不带 Origin 请求头的非浏览器客户端
+		AllowedOrigins []string `yaml:"allowedOrigins"`
+	} `yaml:"server"`
+	ChainType string `yaml:"chainType"`
+
+	MQ MQConfig `yaml:"mq"` // 添加 mq 的配置
+
+	Subscription SubscriptionConfig `yaml:"subscription"` // 事件订阅配置
+
+	Auth AuthConfig `yaml:"auth"` // 接口认证与授权配置
+
+	RateLimit RateLimitConfig `yaml:"rateLimit"` // 限流与并发配额
+
+	Log LogConfig `yaml:"log"` // 日志配置
+
+	Tracing TracingConfig `yaml:"tracing"` // 链路追踪配置
+
+	Health HealthConfig `yaml:"health"` // 就绪检查配置
+
+	Idempotency IdempotencyConfig `yaml:"idempotency"` // 交易提交幂等配置
+
+	IdentityStore IdentityStoreConfig `yaml:"identityStore"` // 托管身份存储配置
+
+	HSM HSMConfig `yaml:"hsm"` // PKCS#11 硬件安全模块配置
+
+	UserIdentities UserIdentitiesConfig `yaml:"userIdentities"` // 调用方身份配置
+
+	Certificates CertificatesConfig `yaml:"certificates"` // 证书到期监控配置
+}
+
+// 请求参数模型由 api/openapi.yaml 生成，见 requests.gen.go
+
 type EventRes struct {
 	BlockHeight   uint64   `json:"block_height"`
 	ChainId       string   `json:"chain_id"`
@@ -113,6 +218,47 @@ type EventRes struct {
 	Topic         string   `json:"topic"`
 }
 
+// BlockEventRes 区块事件消息
+type BlockEventRes struct {
+	BlockHeight  uint64   `json:"block_height"`
+	ChainId      string   `json:"chain_id"`
+	DataHash     string   `json:"data_hash"`
+	PreviousHash string   `json:"previous_hash"`
+	TxCount      int      `json:"tx_count"`
+	TxIds        []string `json:"tx_ids"`
+}
+
+// TxStatusEventRes 交易状态事件消息
+type TxStatusEventRes struct {
+	BlockHeight    uint64 `json:"block_height"`
+	ChainId        string `json:"chain_id"`
+	TxId           string `json:"tx_id"`
+	ValidationCode string `json:"validation_code"`
+	Valid          bool   `json:"valid"`
+}
+
+// FilteredBlockEventRes 过滤区块事件消息
+type FilteredBlockEventRes struct {
+	BlockHeight uint64          `json:"block_height"`
+	ChainId     string          `json:"chain_id"`
+	Txs         []FilteredTxRes `json:"txs"`
+}
+
+// FilteredTxRes 过滤区块中的交易摘要
+type FilteredTxRes struct {
+	TxId            string                      `json:"tx_id"`
+	Type            string                      `json:"type"`
+	ValidationCode  string                      `json:"validation_code"`
+	Valid           bool                        `json:"valid"`
+	ChaincodeEvents []FilteredChaincodeEventRes `json:"chaincode_events,omitempty"`
+}
+
+// FilteredChaincodeEventRes 过滤区块中的合约事件，不包含事件负载
+type FilteredChaincodeEventRes struct {
+	ChaincodeName string `json:"chaincode_name"`
+	EventName     string `json:"event_name"`
+}
+
 type Event struct {
 	Action        string `json:"action"`        // 事件动作，例如 "setEvidence"
 	Creator       string `json:"creator"`       // 创建者
@@ -128,8 +274,11 @@ type EventData struct {
 	Payload     []string `json:"payload"`
 }
 
-type EventByteData struct {
-	EventName   string
-	ChaincodeId string
-	EventByte   []byte
+// TxData 区块内单笔交易的解析结果
+type TxData struct {
+	Index          int
+	TxId           string
+	ValidationCode int32
+	ValidationName string
+	Events         []EventData
 }
@@ -0,0 +1,296 @@
+// Code generated by api/gen from api/openapi.yaml; DO NOT EDIT.
+
+package define
+
+type SdkConfigRequest struct {
+	SdkConfig string `json:"sdkConfig"` // Fabric 连接配置（YAML）
+	IsGm      bool   `json:"isGM"`      // 是否使用国密 TLS
+	IsSM3     bool   `json:"isSM3"`     // 是否使用 SM3 哈希
+}
+
+// ProfileRequest 生成连接配置请求参数
+type ProfileRequest struct {
+	IsGm          bool                  `json:"isGM"`
+	IsSM3         bool                  `json:"isSM3"`
+	Channel       string                `json:"channel"`
+	Organization  string                `json:"organization"` // 客户端所属组织，为空时使用第一个有 Peer 的组织，合约调用默认以该组织的第一个用户签名
+	Organizations []ProfileOrganization `json:"organizations"`
+	ClientTLS     ProfileKeyPair        `json:"clientTLS"` // 双向 TLS 的客户端证书
+}
+
+// ProfileOrganization 组织，Orderer 组织只需 orderers
+type ProfileOrganization struct {
+	Name     string        `json:"name"`  // 连接配置中的组织名称，不区分大小写
+	MspId    string        `json:"mspId"` // 每个组织的 MSP ID 必须不同
+	Peers    []ProfileNode `json:"peers"`
+	Orderers []ProfileNode `json:"orderers"`
+	Users    []ProfileUser `json:"users"`
+}
+
+// ProfileNode Peer 或 Orderer 节点
+type ProfileNode struct {
+	Name       string `json:"name"`
+	Url        string `json:"url"`        // 节点地址，grpcs:// 需要 tlsCACert，grpc:// 为明文连接，省略协议时按是否提供 tlsCACert 补全
+	TlsCACert  string `json:"tlsCACert"`  // 节点的 TLS CA 证书（PEM）
+	ServerName string `json:"serverName"` // TLS 校验的主机名，为空时使用节点名称
+}
+
+// ProfileUser 组织用户
+type ProfileUser struct {
+	Name        string `json:"name"`
+	Certificate string `json:"certificate"` // 签名证书（PEM）
+	PrivateKey  string `json:"privateKey"`  // 未加密的私钥（PEM），为空时私钥由身份存储或 HSM 提供
+}
+
+// ProfileKeyPair 证书与私钥
+type ProfileKeyPair struct {
+	Certificate string `json:"certificate"` // 证书（PEM）
+	PrivateKey  string `json:"privateKey"`  // 未加密的私钥（PEM）
+}
+
+// ProfileImportRequest 由 organizations 目录归档生成连接配置请求参数
+type ProfileImportRequest struct {
+	IsGm         bool              `json:"isGM"`
+	IsSM3        bool              `json:"isSM3"`
+	Channel      string            `json:"channel"`
+	Archive      []byte            `json:"archive"`      // organizations 目录的 tar 或 tar.gz 归档
+	Organization string            `json:"organization"` // 客户端所属组织的名称或域名，为空时使用第一个 Peer 组织
+	MspIds       map[string]string `json:"mspIds"`       // 组织域名到 MSP ID，未指定时 Peer 组织为域名第一段首字母大写加 MSP（如 Org1MSP），唯一的 Orderer 组织为 OrdererMSP
+	Endpoints    map[string]string `json:"endpoints"`    // 节点名称到地址，未指定时为节点名称加默认端口（Peer 7051、Orderer 7050）
+	ServerNames  map[string]string `json:"serverNames"`  // 节点名称到 TLS 校验的主机名，通过 IP 地址或代理访问节点时需要指定
+}
+
+// ProfileDiagnoseRequest 诊断连接配置请求参数
+type ProfileDiagnoseRequest struct {
+	SdkConfig     string `json:"sdkConfig"`     // Fabric 连接配置（YAML）
+	IsGm          bool   `json:"isGM"`          // 是否使用国密 TLS
+	IsSM3         bool   `json:"isSM3"`         // 是否使用 SM3 哈希
+	ChaincodeName string `json:"chaincodeName"` // 检查该链码已在通道中提交，为空时只列出已提交的链码
+	LintOnly      bool   `json:"lintOnly"`      // 只检查连接配置与证书，不访问网络
+}
+
+// ContractInvokeRequest 合约调用请求参数
+type ContractInvokeRequest struct {
+	SdkConfig      string   `json:"sdkConfig"`
+	IsGm           bool     `json:"isGM"`
+	IsSM3          bool     `json:"isSM3"`
+	ChaincodeName  string   `json:"chaincodeName"`
+	Method         string   `json:"method"`
+	Args           []string `json:"args"`
+	IdempotencyKey string   `json:"idempotencyKey"`
+}
+
+// ContractQueryRequest 合约查询请求参数
+type ContractQueryRequest struct {
+	SdkConfig     string   `json:"sdkConfig"`
+	IsGm          bool     `json:"isGM"`
+	IsSM3         bool     `json:"isSM3"`
+	ChaincodeName string   `json:"chaincodeName"`
+	Method        string   `json:"method"`
+	Args          []string `json:"args"`
+}
+
+// ContractProposalRequest 离线签名提案请求参数
+type ContractProposalRequest struct {
+	SdkConfig     string   `json:"sdkConfig"`
+	IsGm          bool     `json:"isGM"`
+	IsSM3         bool     `json:"isSM3"`
+	ChaincodeName string   `json:"chaincodeName"`
+	Method        string   `json:"method"`
+	Args          []string `json:"args"`
+	MspId         string   `json:"mspId"`       // 调用方证书所属组织的 MSP ID，默认为连接配置 client.organization 的 MSP ID
+	Certificate   string   `json:"certificate"` // 调用方证书（PEM），ECDSA 或 SM2
+}
+
+// SignedProposalRequest 离线签名背书请求参数
+type SignedProposalRequest struct {
+	SdkConfig string `json:"sdkConfig"`
+	IsGm      bool   `json:"isGM"`
+	IsSM3     bool   `json:"isSM3"`
+	Proposal  []byte `json:"proposal"`  // 创建提案时返回的 proposal
+	Signature []byte `json:"signature"` // 对提案摘要的签名
+}
+
+// SignedTransactionRequest 离线签名提交请求参数
+type SignedTransactionRequest struct {
+	SdkConfig string `json:"sdkConfig"`
+	IsGm      bool   `json:"isGM"`
+	IsSM3     bool   `json:"isSM3"`
+	Payload   []byte `json:"payload"`   // 背书时返回的 payload
+	Signature []byte `json:"signature"` // 对交易摘要的签名
+}
+
+type ContractListRequest struct {
+	SdkConfig     string `json:"sdkConfig"`
+	IsGm          bool   `json:"isGM"`
+	IsSM3         bool   `json:"isSM3"`
+	ChaincodeName string `json:"chaincodeName"`
+}
+
+// ContractEventSubscribeRequest 事件订阅请求参数，type 为空时订阅合约事件，此时 chaincodeName 与 eventName 必填
+type ContractEventSubscribeRequest struct {
+	SdkConfig     string `json:"sdkConfig"`
+	IsGm          bool   `json:"isGM"`
+	IsSM3         bool   `json:"isSM3"`
+	Type          string `json:"type"`
+	TxId          string `json:"txId"`
+	ChaincodeName string `json:"chaincodeName"`
+	EventName     string `json:"eventName"`
+	ChainName     string `json:"chainName"`
+	FromBlock     string `json:"fromBlock"`
+	EndBlock      string `json:"endBlock"`
+}
+
+type ContractEventUnSubscribeRequest struct {
+	SdkConfig   string `json:"sdkConfig"`
+	IsGm        bool   `json:"isGM"`
+	IsSM3       bool   `json:"isSM3"`
+	SubscribeId string `json:"subscribeEventId"`
+}
+
+// EventStreamRequest 事件流请求参数，sdkId 可引用已初始化的 SDK 以替代 sdkConfig
+type EventStreamRequest struct {
+	SdkConfig     string `json:"sdkConfig"`
+	SdkId         string `json:"sdkId"`
+	IsGm          bool   `json:"isGM"`
+	IsSM3         bool   `json:"isSM3"`
+	Type          string `json:"type"`
+	ChaincodeName string `json:"chaincodeName"`
+	EventName     string `json:"eventName"`
+	ChainName     string `json:"chainName"`
+	FromBlock     string `json:"fromBlock"`
+	TxId          string `json:"txId"`
+	LastEventId   string `json:"lastEventId"`
+}
+
+type GetBlockRequest struct {
+	SdkConfig   string `json:"sdkConfig"`
+	IsGm        bool   `json:"isGM"`
+	IsSM3       bool   `json:"isSM3"`
+	BlockNumber string `json:"blockNumber"`
+	OnlyHeader  bool   `json:"onlyHeader"`
+}
+
+type GetTxRequest struct {
+	SdkConfig   string `json:"sdkConfig"`
+	IsGm        bool   `json:"isGM"`
+	IsSM3       bool   `json:"isSM3"`
+	TxId        string `json:"txId"`
+	BlockNumber uint64 `json:"blockNumber"`
+	IsVerified  bool   `json:"isVerified"`
+}
+
+// IdentityPutRequest 导入身份请求参数
+type IdentityPutRequest struct {
+	MspId       string `json:"mspId"`       // 身份所属组织的 MSP ID
+	Name        string `json:"name"`        // 身份名称，与连接配置中的用户名对应，不区分大小写
+	Certificate string `json:"certificate"` // 证书（PEM）
+	PrivateKey  string `json:"privateKey"`  // 未加密的私钥（PEM），保存时以主密钥加密
+}
+
+// CAAttribute 身份属性
+type CAAttribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	ECert bool   `json:"ecert"` // 是否默认写入登记证书
+}
+
+// CAAttributeRequest 登记时请求写入证书的属性
+type CAAttributeRequest struct {
+	Name     string `json:"name"`
+	Optional bool   `json:"optional"` // 为 true 时身份没有该属性也可以登记
+}
+
+// CARequest CA 请求参数
+type CARequest struct {
+	SdkConfig string `json:"sdkConfig"`
+	IsGm      bool   `json:"isGM"`
+	IsSM3     bool   `json:"isSM3"`
+	OrgName   string `json:"orgName"` // 组织名称，为空时使用连接配置的 client.organization
+	CaID      string `json:"caId"`    // 连接配置 certificateAuthorities 中的 CA，为空时使用组织的第一个 CA
+}
+
+// CARegisterRequest 注册用户请求参数
+type CARegisterRequest struct {
+	SdkConfig      string        `json:"sdkConfig"`
+	IsGm           bool          `json:"isGM"`
+	IsSM3          bool          `json:"isSM3"`
+	OrgName        string        `json:"orgName"`        // 组织名称，为空时使用连接配置的 client.organization
+	CaID           string        `json:"caId"`           // 连接配置 certificateAuthorities 中的 CA，为空时使用组织的第一个 CA
+	Name           string        `json:"name"`           // 登记 ID
+	Type           string        `json:"type"`           // 身份类型，如 client、peer、admin，默认为 client
+	Affiliation    string        `json:"affiliation"`    // 所属机构，如 org1.department1
+	MaxEnrollments int           `json:"maxEnrollments"` // 登记密码可使用的次数，0 表示使用 CA 的默认值
+	Secret         string        `json:"secret"`         // 登记密码，为空时由 CA 生成
+	Attributes     []CAAttribute `json:"attributes"`
+}
+
+// CAEnrollRequest 登记用户请求参数
+type CAEnrollRequest struct {
+	SdkConfig         string               `json:"sdkConfig"`
+	IsGm              bool                 `json:"isGM"`
+	IsSM3             bool                 `json:"isSM3"`
+	OrgName           string               `json:"orgName"`           // 组织名称，为空时使用连接配置的 client.organization
+	CaID              string               `json:"caId"`              // 连接配置 certificateAuthorities 中的 CA，为空时使用组织的第一个 CA
+	Name              string               `json:"name"`              // 登记 ID，同时作为保存的身份名称
+	Secret            string               `json:"secret"`            // 登记密码
+	Profile           string               `json:"profile"`           // CA 签发证书使用的配置，如 tls
+	Type              string               `json:"type"`              // 证书类型，默认为 x509
+	Label             string               `json:"label"`             // HSM 中 CA 签名密钥的标签
+	AttributeRequests []CAAttributeRequest `json:"attributeRequests"` // 写入证书的属性，为空时写入注册时 ecert 为 true 的属性
+}
+
+// CAReenrollRequest 重新登记请求参数
+type CAReenrollRequest struct {
+	SdkConfig         string               `json:"sdkConfig"`
+	IsGm              bool                 `json:"isGM"`
+	IsSM3             bool                 `json:"isSM3"`
+	OrgName           string               `json:"orgName"` // 组织名称，为空时使用连接配置的 client.organization
+	CaID              string               `json:"caId"`    // 连接配置 certificateAuthorities 中的 CA，为空时使用组织的第一个 CA
+	Name              string               `json:"name"`    // 已登记的身份名称
+	Profile           string               `json:"profile"`
+	Label             string               `json:"label"`
+	AttributeRequests []CAAttributeRequest `json:"attributeRequests"` // 写入证书的属性，为空时写入注册时 ecert 为 true 的属性
+}
+
+// CARevokeRequest 吊销证书请求参数，name 与 serial、aki 至少指定一组
+type CARevokeRequest struct {
+	SdkConfig string `json:"sdkConfig"`
+	IsGm      bool   `json:"isGM"`
+	IsSM3     bool   `json:"isSM3"`
+	OrgName   string `json:"orgName"` // 组织名称，为空时使用连接配置的 client.organization
+	CaID      string `json:"caId"`    // 连接配置 certificateAuthorities 中的 CA，为空时使用组织的第一个 CA
+	Name      string `json:"name"`    // 吊销该身份的全部证书
+	Serial    string `json:"serial"`  // 证书序列号（十六进制）
+	AKI       string `json:"aki"`     // 证书的颁发机构密钥标识（十六进制）
+	Reason    string `json:"reason"`  // 吊销原因，如 keycompromise、superseded
+	GenCRL    bool   `json:"genCRL"`  // 是否返回证书吊销列表
+}
+
+// CAIdentityRequest CA 身份管理请求参数，查询与删除只使用 id
+type CAIdentityRequest struct {
+	SdkConfig      string        `json:"sdkConfig"`
+	IsGm           bool          `json:"isGM"`
+	IsSM3          bool          `json:"isSM3"`
+	OrgName        string        `json:"orgName"` // 组织名称，为空时使用连接配置的 client.organization
+	CaID           string        `json:"caId"`    // 连接配置 certificateAuthorities 中的 CA，为空时使用组织的第一个 CA
+	ID             string        `json:"id"`      // 登记 ID
+	Type           string        `json:"type"`
+	Affiliation    string        `json:"affiliation"`
+	MaxEnrollments int           `json:"maxEnrollments"`
+	Secret         string        `json:"secret"`
+	Attributes     []CAAttribute `json:"attributes"`
+	Force          bool          `json:"force"` // 删除时是否强制删除，包括删除调用方自身
+}
+
+// CAAffiliationRequest CA 机构管理请求参数
+type CAAffiliationRequest struct {
+	SdkConfig string `json:"sdkConfig"`
+	IsGm      bool   `json:"isGM"`
+	IsSM3     bool   `json:"isSM3"`
+	OrgName   string `json:"orgName"` // 组织名称，为空时使用连接配置的 client.organization
+	CaID      string `json:"caId"`    // 连接配置 certificateAuthorities 中的 CA，为空时使用组织的第一个 CA
+	Name      string `json:"name"`    // 机构名称，如 org1.department1
+	NewName   string `json:"newName"` // 重命名后的机构名称，修改时必填
+	Force     bool   `json:"force"`   // 添加时创建不存在的上级机构；修改时同时修改机构下身份的机构；删除时同时删除下级机构与身份
+}
@@ -0,0 +1,141 @@
+package grpcserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/qctc/fabric2-api-server/idempotency"
+	"github.com/qctc/fabric2-api-server/identity"
+	"github.com/qctc/fabric2-api-server/ratelimit"
+	"github.com/qctc/fabric2-api-server/service"
+	"github.com/qctc/fabric2-api-server/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain 错误详情中的 ErrorInfo.Domain
+const errorDomain = "fabric2-api-server"
+
+// grpcCode 按错误信息对应的 HTTP 状态码选择 gRPC 状态码，与 REST 接口的分类保持一致
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusBadGateway:
+		return codes.Unknown
+	default:
+		return codes.Internal
+	}
+}
+
+// statusError 将错误信息转换为 gRPC 状态，错误码与 Fabric 状态放在 ErrorInfo 详情中
+func statusError(info *utils.ErrorInfo, message string, details ...*errdetails.BadRequest_FieldViolation) error {
+	return statusErrorWithMetadata(info, message, nil, details...)
+}
+
+// statusErrorWithMetadata 同 statusError，extra 加入 ErrorInfo 详情的 Metadata
+func statusErrorWithMetadata(info *utils.ErrorInfo, message string, extra map[string]string, details ...*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(grpcCode(info.Status), message)
+	metadata := map[string]string{"retryable": strconv.FormatBool(info.Retryable)}
+	for k, v := range extra {
+		metadata[k] = v
+	}
+	if info.Fabric != nil {
+		metadata["fabricGroup"] = info.Fabric.Group
+		metadata["fabricCode"] = strconv.Itoa(int(info.Fabric.Code))
+		metadata["fabricCodeName"] = info.Fabric.CodeName
+	}
+	if info.Chaincode != nil {
+		metadata["chaincodeStatus"] = strconv.Itoa(int(info.Chaincode.Status))
+		metadata["chaincodeMessage"] = info.Chaincode.Message
+	}
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   string(info.Code),
+		Domain:   errorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	if len(details) > 0 {
+		if st, err := withDetails.WithDetails(&errdetails.BadRequest{FieldViolations: details}); err == nil {
+			withDetails = st
+		}
+	}
+	return withDetails.Err()
+}
+
+// fabricError SDK 调用失败
+func fabricError(err error) error {
+	return statusError(utils.ClassifyError(err), err.Error())
+}
+
+// outcomeError 保存的提交失败结果，交易已发送排序但结果未知时在详情中给出交易 ID
+func outcomeError(outcome *idempotency.Outcome) error {
+	var extra map[string]string
+	if outcome.TxId != "" {
+		extra = map[string]string{"txHash": outcome.TxId}
+	}
+	return statusErrorWithMetadata(outcome.Error, outcome.Message, extra)
+}
+
+// invalidProfile SDK 初始化失败，与 REST 接口相同视为连接配置错误
+func invalidProfile(err error) error {
+	return statusError(utils.NewErrorInfo(utils.CodeInvalidProfile), "sdk Initialize error "+err.Error())
+}
+
+// callerIdentityError 与 REST 接口相同：调用方没有 Fabric 身份时拒绝访问，身份名称不能保存时视为请求错误，
+// 未启用身份存储时视为资源不存在
+func callerIdentityError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrCallerIdentity):
+		return statusError(utils.NewErrorInfo(utils.CodeAccessDenied), err.Error())
+	case errors.Is(err, identity.ErrInvalid):
+		return statusError(utils.NewErrorInfo(utils.CodeInvalidRequest), err.Error())
+	case errors.Is(err, identity.ErrDisabled):
+		return statusError(utils.NewErrorInfo(utils.CodeNotFound), err.Error())
+	default:
+		return fabricError(err)
+	}
+}
+
+// invalidArgument 请求参数校验失败，字段错误放在 BadRequest 详情中
+func invalidArgument(fields []utils.FieldError) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+	for _, f := range fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Reason})
+	}
+	return statusError(utils.NewErrorInfo(utils.CodeInvalidRequest), "Invalid request", violations...)
+}
+
+// limitError 超出限流配额时返回 ResourceExhausted 与建议的重试间隔，客户端断开时返回对应的上下文错误
+func limitError(err error) error {
+	var limitErr *ratelimit.LimitError
+	if !errors.As(err, &limitErr) {
+		return status.FromContextError(err).Err()
+	}
+	st, _ := status.FromError(statusError(utils.NewErrorInfo(utils.CodeRateLimited), err.Error()))
+	if withRetry, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(limitErr.RetryAfter)}); err == nil {
+		st = withRetry
+	}
+	return st.Err()
+}
@@ -0,0 +1,445 @@
+// Package grpcserver 提供与 REST 接口等价的 gRPC 接口，共用服务层、认证与限流
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/qctc/fabric2-api-server/api/fabric2v1"
+	"github.com/qctc/fabric2-api-server/auth"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/idempotency"
+	"github.com/qctc/fabric2-api-server/logging"
+	"github.com/qctc/fabric2-api-server/ratelimit"
+	"github.com/qctc/fabric2-api-server/service"
+	"github.com/qctc/fabric2-api-server/subscription"
+	"github.com/qctc/fabric2-api-server/tracing"
+	"github.com/qctc/fabric2-api-server/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// txIdPattern 与 OpenAPI 文档中的 TxId 一致
+var txIdPattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// Server 实现 fabric2v1.Fabric2APIServer
+type Server struct {
+	fabric2v1.UnimplementedFabric2APIServer
+
+	grpc *grpc.Server
+	// streamsCtx 在服务关闭时取消，用于结束事件流
+	streamsCtx  context.Context
+	stopStreams context.CancelFunc
+}
+
+// New 创建 gRPC 服务，guard 为 nil 时不认证
+func New(guard *auth.Guard, opts ...grpc.ServerOption) *Server {
+	s := &Server{}
+	s.streamsCtx, s.stopStreams = context.WithCancel(context.Background())
+	// 链路追踪在最外层，请求日志可以带上 trace_id；超时在认证之前设置，截止时间覆盖整个调用
+	var defaultTimeout, maxTimeout time.Duration
+	if define.GlobalConfig != nil {
+		defaultTimeout, maxTimeout = define.GlobalConfig.Server.RequestTimeout, define.GlobalConfig.Server.MaxRequestTimeout
+	}
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor(), logging.UnaryServerInterceptor(),
+			timeoutInterceptor(defaultTimeout, maxTimeout), guard.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor(), logging.StreamServerInterceptor(), guard.StreamServerInterceptor()),
+	)
+	s.grpc = grpc.NewServer(opts...)
+	fabric2v1.RegisterFabric2APIServer(s.grpc, s)
+	return s
+}
+
+// Serve 在监听器上提供服务，直到 Shutdown
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// Shutdown 结束事件流并等待进行中的调用完成，ctx 结束时强制关闭
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopStreams()
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		return ctx.Err()
+	}
+}
+
+func (s *Server) InvokeContract(ctx context.Context, req *fabric2v1.InvokeContractRequest) (*fabric2v1.ContractResult, error) {
+	slog.DebugContext(ctx, "invoke contract start")
+	if err := validate(required("sdkConfig", req.SdkConfig), required("chaincodeName", req.ChaincodeName), required("method", req.Method)); err != nil {
+		return nil, err
+	}
+	key, err := idempotency.RequestKey(metadataValue(ctx, idempotency.Header), req.IdempotencyKey)
+	if err != nil {
+		return nil, invalidArgument([]utils.FieldError{{Field: "idempotencyKey", Reason: err.Error()}})
+	}
+	release, err := admit(ctx, auth.ActionInvoke, req.SdkConfig, req.ChaincodeName, req.Method)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	sdk, err := initializeSDK(req.SdkConfig, req.IsGm, req.IsSm3)
+	if err != nil {
+		return nil, err
+	}
+	signCtx, err := sdk.CallerContext(ctx, auth.CallerFromContext(ctx))
+	if err != nil {
+		return nil, callerIdentityError(err)
+	}
+
+	if key != "" {
+		logging.AddFields(ctx, slog.String("idempotency_key", key))
+	}
+	submitCtx, cancel := idempotency.Default.Detach(signCtx, key)
+	defer cancel()
+	outcome, replayed, err := idempotency.Default.Do(
+		idempotency.Key(auth.CallerFromContext(ctx), utils.SdkId(req.SdkConfig), key),
+		idempotency.Fingerprint(req.ChaincodeName, req.Method, req.Args),
+		func() *idempotency.Outcome {
+			resp, txId, err := sdk.InvokeContract(submitCtx, req.ChaincodeName, req.Method, contractArgs(req.Args))
+			if err != nil {
+				return idempotency.Failure(err, string(txId))
+			}
+			utils.LogTxId(ctx, string(txId))
+			// 交易已提交，查询区块高度失败时高度留空，不影响保存的提交结果
+			height, err := sdk.GetBlockByTxID(submitCtx, string(txId))
+			if err != nil {
+				slog.WarnContext(ctx, "query block height of committed transaction failed", "txId", txId, "error", err)
+			}
+			return idempotency.Success(resp, string(txId), height)
+		})
+	if err != nil {
+		code := utils.CodeIdempotencyInProgress
+		if errors.Is(err, idempotency.ErrKeyReused) {
+			code = utils.CodeIdempotencyKeyReused
+		}
+		return nil, statusError(utils.NewErrorInfo(code), err.Error())
+	}
+	if replayed {
+		slog.InfoContext(ctx, "idempotent replay", "txId", outcome.TxId)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(idempotency.ReplayedHeader, "true"))
+	}
+	if outcome.Error != nil {
+		return nil, outcomeError(outcome)
+	}
+	return &fabric2v1.ContractResult{Payload: outcome.Payload, TxHash: outcome.TxId, Height: outcome.Height}, nil
+}
+
+func (s *Server) QueryContract(ctx context.Context, req *fabric2v1.QueryContractRequest) (*fabric2v1.ContractResult, error) {
+	slog.DebugContext(ctx, "query contract start")
+	if err := validate(required("sdkConfig", req.SdkConfig), required("chaincodeName", req.ChaincodeName), required("method", req.Method)); err != nil {
+		return nil, err
+	}
+	release, err := admit(ctx, auth.ActionQuery, req.SdkConfig, req.ChaincodeName, req.Method)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	sdk, err := initializeSDK(req.SdkConfig, req.IsGm, req.IsSm3)
+	if err != nil {
+		return nil, err
+	}
+
+	signCtx, err := sdk.CallerContext(ctx, auth.CallerFromContext(ctx))
+	if err != nil {
+		return nil, callerIdentityError(err)
+	}
+	resp, txId, err := sdk.QueryContract(signCtx, req.ChaincodeName, req.Method, contractArgs(req.Args))
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	utils.LogTxId(ctx, string(txId))
+	block, err := sdk.GetBlockInfo(ctx, "latest")
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	return &fabric2v1.ContractResult{Payload: string(resp), TxHash: string(txId), Height: block.GetHeader().GetNumber()}, nil
+}
+
+func (s *Server) ListContracts(ctx context.Context, req *fabric2v1.ListContractsRequest) (*fabric2v1.ListContractsResponse, error) {
+	slog.DebugContext(ctx, "get contract list start")
+	if err := validate(required("sdkConfig", req.SdkConfig)); err != nil {
+		return nil, err
+	}
+	release, err := admit(ctx, auth.ActionRead, req.SdkConfig, "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	sdk, err := initializeSDK(req.SdkConfig, req.IsGm, req.IsSm3)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts, err := sdk.GetContractList(ctx)
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	resp := &fabric2v1.ListContractsResponse{}
+	for _, c := range contracts {
+		resp.Contracts = append(resp.Contracts, &fabric2v1.Contract{Name: c.Name, Version: c.Version, Sequence: c.Sequence})
+	}
+	return resp, nil
+}
+
+func (s *Server) GetContractInfo(ctx context.Context, req *fabric2v1.GetContractInfoRequest) (*structpb.Struct, error) {
+	slog.DebugContext(ctx, "get contract info start")
+	if err := validate(required("sdkConfig", req.SdkConfig), required("chaincodeName", req.ChaincodeName)); err != nil {
+		return nil, err
+	}
+	release, err := admit(ctx, auth.ActionRead, req.SdkConfig, req.ChaincodeName, "")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	sdk, err := initializeSDK(req.SdkConfig, req.IsGm, req.IsSm3)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := sdk.GetContractInfo(ctx, req.ChaincodeName)
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	// 经 JSON 转换，与 REST 接口返回的字段保持一致
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	result := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, result); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return result, nil
+}
+
+func (s *Server) GetBlock(ctx context.Context, req *fabric2v1.GetBlockRequest) (*fabric2v1.BlockInfo, error) {
+	slog.DebugContext(ctx, "get block info start")
+	blockNumber := utils.FieldError{Field: "blockNumber", Reason: fmt.Sprintf("invalid blockNumber %q", req.BlockNumber)}
+	if _, err := strconv.ParseUint(req.BlockNumber, 10, 64); err == nil || req.BlockNumber == "latest" {
+		blockNumber = utils.FieldError{}
+	}
+	if err := validate(required("sdkConfig", req.SdkConfig), blockNumber); err != nil {
+		return nil, err
+	}
+	release, err := admit(ctx, auth.ActionRead, req.SdkConfig, "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	sdk, err := initializeSDK(req.SdkConfig, req.IsGm, req.IsSm3)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := sdk.GetBlockInfo(ctx, req.BlockNumber)
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	return &fabric2v1.BlockInfo{
+		BlockNumber:  block.GetHeader().GetNumber(),
+		PreviousHash: block.GetHeader().GetPreviousHash(),
+		DataHash:     block.GetHeader().GetDataHash(),
+	}, nil
+}
+
+func (s *Server) GetTransaction(ctx context.Context, req *fabric2v1.GetTransactionRequest) (*fabric2v1.TransactionInfo, error) {
+	slog.DebugContext(ctx, "get transaction info start")
+	txId := utils.FieldError{}
+	if !txIdPattern.MatchString(req.TxId) {
+		txId = utils.FieldError{Field: "txId", Reason: "must be a 64 character hex transaction id"}
+	}
+	if err := validate(required("sdkConfig", req.SdkConfig), txId); err != nil {
+		return nil, err
+	}
+	release, err := admit(ctx, auth.ActionRead, req.SdkConfig, "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	sdk, err := initializeSDK(req.SdkConfig, req.IsGm, req.IsSm3)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := sdk.GetTransactionInfo(ctx, req.TxId)
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	envelope, err := proto.Marshal(tx.GetTransactionEnvelope())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &fabric2v1.TransactionInfo{
+		TxId:                req.TxId,
+		ValidationCode:      tx.GetValidationCode(),
+		ValidationCodeName:  pb.TxValidationCode(tx.GetValidationCode()).String(),
+		TransactionEnvelope: envelope,
+	}, nil
+}
+
+func (s *Server) StreamEvents(req *fabric2v1.StreamEventsRequest, stream fabric2v1.Fabric2API_StreamEventsServer) error {
+	ctx := stream.Context()
+	slog.DebugContext(ctx, "stream events start")
+	sdkRequired := utils.FieldError{}
+	if req.SdkId == "" && req.SdkConfig == "" {
+		sdkRequired = utils.FieldError{Field: "sdkConfig", Reason: "sdkConfig or sdkId is required"}
+	}
+	if err := validate(sdkRequired); err != nil {
+		return err
+	}
+	streamReq := define.EventStreamRequest{
+		SdkConfig:     req.SdkConfig,
+		SdkId:         req.SdkId,
+		IsGm:          req.IsGm,
+		IsSM3:         req.IsSm3,
+		Type:          req.Type,
+		ChaincodeName: req.ChaincodeName,
+		EventName:     req.EventName,
+		ChainName:     req.ChainName,
+		FromBlock:     req.FromBlock,
+		TxId:          req.TxId,
+		LastEventId:   req.LastEventId,
+	}
+	if err := auth.Authorize(ctx, auth.EventStreamResource(streamReq)); err != nil {
+		return statusError(utils.NewErrorInfo(utils.CodeAccessDenied), err.Error())
+	}
+	utils.LogRequestFields(ctx, req.SdkConfig, req.SdkId, req.ChaincodeName, "")
+	profile := req.SdkId
+	if req.SdkConfig != "" {
+		profile = utils.SdkId(req.SdkConfig)
+	}
+	// 事件流只在建立时消耗令牌，补齐历史区块由订阅按连接配置排队
+	if err := ratelimit.Default.Take(ctx, ratelimit.Keys(caller(ctx), profile, req.ChaincodeName)...); err != nil {
+		return limitError(err)
+	}
+	sdk, err := utils.SDKForStream(req.SdkId, req.SdkConfig, req.IsGm, req.IsSm3)
+	if errors.Is(err, utils.ErrSDKNotInitialized) {
+		return statusError(utils.NewErrorInfo(utils.CodeNotFound), err.Error())
+	}
+	if err != nil {
+		return invalidProfile(err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(s.streamsCtx, cancel)
+	defer stop()
+	messages, err := subscription.OpenStream(ctx, sdk, streamReq, req.LastEventId)
+	if errors.Is(err, subscription.ErrInvalidSpec) {
+		return statusError(utils.NewErrorInfo(utils.CodeInvalidRequest), err.Error())
+	}
+	if err != nil {
+		return fabricError(err)
+	}
+
+	for m := range messages {
+		if err := stream.Send(&fabric2v1.Event{Id: m.Id, Event: m.Event, Data: string(m.Data)}); err != nil {
+			return err
+		}
+	}
+	// 服务关闭时返回 Unavailable，客户端可携带最后的事件 ID 重连
+	if s.streamsCtx.Err() != nil {
+		return status.Error(codes.Unavailable, "server shutting down")
+	}
+	if err := stream.Context().Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
+
+// admit 按 REST 接口相同的规则授权并限流，成功后调用方必须调用 release
+func admit(ctx context.Context, action auth.Action, sdkConfig, chaincode, function string) (func(), error) {
+	utils.LogRequestFields(ctx, sdkConfig, "", chaincode, function)
+	if err := auth.Authorize(ctx, auth.ProfileResource(action, sdkConfig, "", chaincode, function)); err != nil {
+		return nil, statusError(utils.NewErrorInfo(utils.CodeAccessDenied), err.Error())
+	}
+	release, err := ratelimit.Default.Acquire(ctx, ratelimit.Keys(caller(ctx), utils.SdkId(sdkConfig), chaincode)...)
+	if err != nil {
+		return nil, limitError(err)
+	}
+	return release, nil
+}
+
+// metadataValue 返回请求元数据中 key 的第一个值
+func metadataValue(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// caller 限流使用的调用方，未认证时按客户端地址区分
+func caller(ctx context.Context) string {
+	if name := auth.CallerFromContext(ctx); name != "" {
+		return name
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	return ""
+}
+
+func initializeSDK(sdkConfig string, gm, sm3 bool) (*service.Fabric2Service, error) {
+	err, sdk := utils.InitializeSDKBySdkId(sdkConfig, gm, sm3)
+	if err != nil {
+		return nil, invalidProfile(err)
+	}
+	return sdk, nil
+}
+
+// required 必填字段为空时返回字段错误
+func required(field, value string) utils.FieldError {
+	if value == "" {
+		return utils.FieldError{Field: field, Reason: "property \"" + field + "\" is missing"}
+	}
+	return utils.FieldError{}
+}
+
+// validate 汇总字段错误，忽略空的 FieldError
+func validate(results ...utils.FieldError) error {
+	var fields []utils.FieldError
+	for _, f := range results {
+		if f.Field != "" {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return invalidArgument(fields)
+}
+
+func contractArgs(args []string) [][]byte {
+	out := make([][]byte, len(args))
+	for i, arg := range args {
+		out[i] = []byte(arg)
+	}
+	return out
+}
@@ -0,0 +1,196 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/api/fabric2v1"
+	"github.com/qctc/fabric2-api-server/auth"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/ratelimit"
+	"github.com/qctc/fabric2-api-server/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T, guard *auth.Guard) fabric2v1.Fabric2APIClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := New(guard)
+	go s.Serve(lis)
+	t.Cleanup(func() { s.Shutdown(context.Background()) })
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return fabric2v1.NewFabric2APIClient(conn)
+}
+
+func TestAuthentication(t *testing.T) {
+	digest := sha256.Sum256([]byte("secret"))
+	guard, err := auth.New(define.AuthConfig{
+		Enabled:  true,
+		APIKeys:  []define.APIKeyConfig{{Name: "ops", SHA256: hex.EncodeToString(digest[:])}},
+		Policies: []define.PolicyConfig{{Subjects: []string{"apikey:ops"}, Actions: []string{"read"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, guard)
+	req := &fabric2v1.QueryContractRequest{SdkConfig: "name: test", ChaincodeName: "basic", Method: "Query"}
+
+	_, err = client.QueryContract(context.Background(), req)
+	if code := status.Code(err); code != codes.Unauthenticated {
+		t.Errorf("without api key: code = %s, want Unauthenticated", code)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret")
+	_, err = client.QueryContract(ctx, req)
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("action not allowed: code = %s, want PermissionDenied", code)
+	}
+	if reason := errorReason(err); reason != string(utils.CodeAccessDenied) {
+		t.Errorf("reason = %q", reason)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	client := newTestClient(t, nil)
+	_, err := client.GetBlock(context.Background(), &fabric2v1.GetBlockRequest{BlockNumber: "first"})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Fatalf("code = %s, want InvalidArgument", code)
+	}
+	var fields []string
+	for _, d := range status.Convert(err).Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				fields = append(fields, v.Field)
+			}
+		}
+	}
+	if len(fields) != 2 || fields[0] != "sdkConfig" || fields[1] != "blockNumber" {
+		t.Errorf("field violations = %v", fields)
+	}
+
+	// 服务端流在第一次 Recv 时返回错误
+	stream, err := client.StreamEvents(context.Background(), &fabric2v1.StreamEventsRequest{Type: "block"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf("stream without sdk: code = %s, want InvalidArgument", code)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	limiter, err := ratelimit.New(define.RateLimitConfig{
+		Enabled: true,
+		Caller:  define.LimitConfig{Rate: 0.5, Burst: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ratelimit.Default = limiter
+	t.Cleanup(func() { ratelimit.Default = nil })
+
+	client := newTestClient(t, nil)
+	// 第一个请求消耗令牌后因连接配置无效失败，第二个请求被限流
+	req := &fabric2v1.ListContractsRequest{SdkConfig: "not a profile"}
+	_, err = client.ListContracts(context.Background(), req)
+	if code := status.Code(err); code != codes.InvalidArgument || errorReason(err) != string(utils.CodeInvalidProfile) {
+		t.Fatalf("first request: %v", err)
+	}
+	_, err = client.ListContracts(context.Background(), req)
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Fatalf("code = %s, want ResourceExhausted", code)
+	}
+	var retry *errdetails.RetryInfo
+	for _, d := range status.Convert(err).Details() {
+		if r, ok := d.(*errdetails.RetryInfo); ok {
+			retry = r
+		}
+	}
+	if retry == nil || retry.RetryDelay.AsDuration() <= 0 {
+		t.Errorf("RetryInfo = %v", retry)
+	}
+}
+
+func TestGRPCCode(t *testing.T) {
+	tests := []struct {
+		code utils.ErrorCode
+		want codes.Code
+	}{
+		{utils.CodeInvalidRequest, codes.InvalidArgument},
+		{utils.CodeUnauthenticated, codes.Unauthenticated},
+		{utils.CodeChaincodeError, codes.FailedPrecondition},
+		{utils.CodeMVCCReadConflict, codes.Aborted},
+		{utils.CodeRateLimited, codes.ResourceExhausted},
+		{utils.CodeTimeout, codes.DeadlineExceeded},
+		{utils.CodePeerUnreachable, codes.Unavailable},
+		{utils.CodeFabricError, codes.Unknown},
+		{utils.CodeInternalError, codes.Internal},
+	}
+	for _, tt := range tests {
+		info := utils.NewErrorInfo(tt.code)
+		if got := grpcCode(info.Status); got != tt.want {
+			t.Errorf("%s (HTTP %d): code = %s, want %s", tt.code, info.Status, got, tt.want)
+		}
+	}
+	if grpcCode(http.StatusTeapot) != codes.Internal {
+		t.Error("unknown HTTP status should map to Internal")
+	}
+}
+
+func errorReason(err error) string {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestTimeoutInterceptor(t *testing.T) {
+	interceptor := timeoutInterceptor(time.Minute, 5*time.Minute)
+	remaining := func(ctx context.Context) time.Duration {
+		var got time.Duration
+		_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				t.Fatal("call without deadline")
+			}
+			got = time.Until(deadline)
+			return nil, nil
+		})
+		return got
+	}
+
+	if got := remaining(context.Background()); got <= 5*time.Second || got > time.Minute {
+		t.Errorf("default deadline remaining = %v", got)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if got := remaining(ctx); got > 5*time.Second {
+		t.Errorf("client deadline remaining = %v", got)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if got := remaining(ctx); got > 5*time.Minute {
+		t.Errorf("capped deadline remaining = %v", got)
+	}
+}
@@ -0,0 +1,26 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/middleware"
+	"google.golang.org/grpc"
+)
+
+// timeoutInterceptor 调用方未设置 deadline 时使用默认超时，设置的 deadline 不超过 max；
+// 事件流为长连接，不设置超时
+func timeoutInterceptor(def, max time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var requested time.Duration
+		if deadline, ok := ctx.Deadline(); ok {
+			requested = time.Until(deadline)
+		}
+		if timeout := middleware.EffectiveTimeout(requested, def, max); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,331 @@
+// Package health 提供存活（/healthz）与就绪（/readyz）检查。就绪检查包括消息队列、连接池中各连接配置的
+// Peer 与 Orderer 可达性以及事件订阅的健康状态与投递延迟，任一检查失败时实例应被移出负载均衡。
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/service"
+	"github.com/qctc/fabric2-api-server/subscription"
+)
+
+// defaultTimeout 未配置时单次就绪检查的最长时间
+const defaultTimeout = 3 * time.Second
+
+// defaultCacheTTL 未配置时就绪检查结果的缓存时间
+const defaultCacheTTL = 5 * time.Second
+
+// Status 检查结果
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded" // 部分依赖异常，仍可提供服务
+	StatusFail     Status = "fail"     // 无法提供服务，就绪检查返回 503
+)
+
+// severity 用于合并多个检查结果
+func (s Status) severity() int {
+	switch s {
+	case StatusFail:
+		return 2
+	case StatusDegraded:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func worse(a, b Status) Status {
+	if b.severity() > a.severity() {
+		return b
+	}
+	return a
+}
+
+// Check 单项检查结果
+type Check struct {
+	Name    string      `json:"name"`
+	Status  Status      `json:"status"`
+	Message string      `json:"message,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Report 就绪检查报告，Status 为各项检查中最差的结果
+type Report struct {
+	Status    Status    `json:"status"`
+	Checks    []Check   `json:"checks"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Summary 只保留整体状态与各项检查的名称和状态，不包含节点地址、错误信息与订阅详情
+func (r Report) Summary() Report {
+	checks := make([]Check, len(r.Checks))
+	for i, check := range r.Checks {
+		checks[i] = Check{Name: check.Name, Status: check.Status}
+	}
+	return Report{Status: r.Status, Checks: checks, Timestamp: r.Timestamp}
+}
+
+var shuttingDown atomic.Bool
+
+// SetShuttingDown 标记服务正在关闭，之后就绪检查失败，使实例先被移出负载均衡
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+// Checker 就绪检查
+type Checker struct {
+	cfg define.HealthConfig
+	// mqEndpoint 返回消息队列地址，为空表示未配置消息队列
+	mqEndpoint func() string
+	// mqReady 返回消息队列 Producer 是否已启动
+	mqReady func() bool
+	// dial 检查地址是否可以建立 TCP 连接
+	dial func(ctx context.Context, addr string) error
+	// cache 保存上一次检查结果，由 Cached 使用
+	cache *reportCache
+}
+
+// reportCache 就绪检查结果缓存
+type reportCache struct {
+	mu           sync.Mutex
+	report       Report
+	shuttingDown bool
+}
+
+// defaultCache 各请求创建的 Checker 共用的检查结果缓存
+var defaultCache = &reportCache{}
+
+// New 按配置创建就绪检查，消息队列地址与 Producer 取自订阅共用的投递目标，重新加载配置后随之变化
+func New(cfg define.HealthConfig) *Checker {
+	return &Checker{
+		cfg:        cfg,
+		mqEndpoint: subscription.DefaultSink.Endpoint,
+		mqReady:    subscription.DefaultSink.Ready,
+		dial:       dialTCP,
+		cache:      defaultCache,
+	}
+}
+
+// Cached 返回缓存时间内的上一次检查结果，过期或开始关闭后重新检查。并发的探测等待同一次检查，
+// 避免每次探测都连接全部节点；检查不随单个探测请求取消
+func (c *Checker) Cached(ctx context.Context) Report {
+	ttl := c.cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	stopping := shuttingDown.Load()
+	if !c.cache.report.Timestamp.IsZero() && time.Since(c.cache.report.Timestamp) < ttl && c.cache.shuttingDown == stopping {
+		return c.cache.report
+	}
+	c.cache.report = c.Ready(context.WithoutCancel(ctx))
+	c.cache.shuttingDown = stopping
+	return c.cache.report
+}
+
+// Ready 执行全部就绪检查，整体不超过配置的超时时间
+func (c *Checker) Ready(ctx context.Context) Report {
+	timeout := c.cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	checks := make([]Check, 4)
+	var wg sync.WaitGroup
+	for i, check := range []func(context.Context) Check{c.checkShutdown, c.checkMQ, c.checkProfiles, c.checkSubscriptions} {
+		wg.Add(1)
+		go func(i int, check func(context.Context) Check) {
+			defer wg.Done()
+			checks[i] = check(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: checks, Timestamp: time.Now()}
+	for _, check := range checks {
+		report.Status = worse(report.Status, check.Status)
+	}
+	return report
+}
+
+func (c *Checker) checkShutdown(context.Context) Check {
+	if shuttingDown.Load() {
+		return Check{Name: "server", Status: StatusFail, Message: "shutting down"}
+	}
+	return Check{Name: "server", Status: StatusOK}
+}
+
+func (c *Checker) checkMQ(ctx context.Context) Check {
+	check := Check{Name: "mq", Status: StatusOK}
+	endpoint := c.mqEndpoint()
+	if endpoint == "" {
+		check.Message = "not configured"
+		return check
+	}
+	check.Details = map[string]string{"endpoint": endpoint}
+	if !c.mqReady() {
+		check.Status, check.Message = StatusFail, "producer not started"
+		return check
+	}
+	if err := c.dial(ctx, endpoint); err != nil {
+		check.Status, check.Message = StatusFail, err.Error()
+	}
+	return check
+}
+
+// EndpointHealth 单个节点的可达性
+type EndpointHealth struct {
+	URL       string `json:"url"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ProfileHealth 连接配置的节点可达性
+type ProfileHealth struct {
+	Profile  string           `json:"profile"`
+	Status   Status           `json:"status"`
+	Error    string           `json:"error,omitempty"`
+	Peers    []EndpointHealth `json:"peers"`
+	Orderers []EndpointHealth `json:"orderers"`
+}
+
+// checkProfiles 检查连接池中每个连接配置的 Peer 与 Orderer：全部不可达时失败，部分不可达时为 degraded
+func (c *Checker) checkProfiles(ctx context.Context) Check {
+	sdks := service.List()
+	check := Check{Name: "profiles", Status: StatusOK}
+	if len(sdks) == 0 {
+		check.Message = "no profiles initialized"
+		return check
+	}
+
+	profiles := make([]ProfileHealth, len(sdks))
+	var wg sync.WaitGroup
+	for i, sdk := range sdks {
+		wg.Add(1)
+		go func(i int, sdk *service.Fabric2Service) {
+			defer wg.Done()
+			profiles[i] = c.checkProfile(ctx, sdk)
+		}(i, sdk)
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, p := range profiles {
+		check.Status = worse(check.Status, p.Status)
+		if p.Status == StatusFail {
+			failed = append(failed, p.Profile)
+		}
+	}
+	if len(failed) > 0 {
+		check.Message = "unreachable profiles: " + strings.Join(failed, ", ")
+	}
+	check.Details = profiles
+	return check
+}
+
+func (c *Checker) checkProfile(ctx context.Context, sdk *service.Fabric2Service) ProfileHealth {
+	p := ProfileHealth{Profile: sdk.Id(), Status: StatusOK}
+	peers, orderers, err := sdk.Endpoints()
+	if err != nil {
+		p.Status, p.Error = StatusFail, err.Error()
+		return p
+	}
+	p.Peers = c.dialAll(ctx, peers)
+	p.Orderers = c.dialAll(ctx, orderers)
+	for _, group := range [][]EndpointHealth{p.Peers, p.Orderers} {
+		reachable := 0
+		for _, e := range group {
+			if e.Reachable {
+				reachable++
+			}
+		}
+		switch {
+		case reachable == 0:
+			p.Status = StatusFail
+		case reachable < len(group):
+			p.Status = worse(p.Status, StatusDegraded)
+		}
+	}
+	return p
+}
+
+func (c *Checker) dialAll(ctx context.Context, urls []string) []EndpointHealth {
+	results := make([]EndpointHealth, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			results[i] = EndpointHealth{URL: url, Reachable: true}
+			if err := c.dial(ctx, hostPort(url)); err != nil {
+				results[i] = EndpointHealth{URL: url, Error: err.Error()}
+			}
+		}(i, url)
+	}
+	wg.Wait()
+	return results
+}
+
+// checkSubscriptions 事件监听重连失败或投递延迟超过 subscriptionLagFail 时失败，
+// 正在重连或延迟超过 subscriptionLagWarn 时为 degraded
+func (c *Checker) checkSubscriptions(ctx context.Context) Check {
+	check := Check{Name: "subscriptions", Status: StatusOK}
+	progress := subscription.DefaultManager.Progress(ctx)
+	var problems []string
+	for _, p := range progress {
+		status := StatusOK
+		switch {
+		case p.Health == subscription.HealthFailed:
+			status = StatusFail
+			problems = append(problems, p.Id+": event listener failed")
+		case p.Health == subscription.HealthReconnecting:
+			status = StatusDegraded
+			problems = append(problems, p.Id+": reconnecting")
+		}
+		if p.Lag != nil {
+			switch lag := *p.Lag; {
+			case c.cfg.SubscriptionLagFail > 0 && lag > c.cfg.SubscriptionLagFail:
+				status = StatusFail
+				problems = append(problems, fmt.Sprintf("%s: lag %d blocks", p.Id, lag))
+			case c.cfg.SubscriptionLagWarn > 0 && lag > c.cfg.SubscriptionLagWarn:
+				status = worse(status, StatusDegraded)
+				problems = append(problems, fmt.Sprintf("%s: lag %d blocks", p.Id, lag))
+			}
+		}
+		check.Status = worse(check.Status, status)
+	}
+	check.Message = strings.Join(problems, "; ")
+	if len(progress) > 0 {
+		check.Details = progress
+	}
+	return check
+}
+
+// hostPort 去掉连接配置中 grpc:// 或 grpcs:// 前缀
+func hostPort(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		return url[i+3:]
+	}
+	return url
+}
+
+func dialTCP(ctx context.Context, addr string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
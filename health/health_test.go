@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+func newTestChecker(dialErr error) *Checker {
+	return &Checker{
+		cfg:        define.HealthConfig{},
+		mqEndpoint: func() string { return "mq:8081" },
+		mqReady:    func() bool { return true },
+		dial:       func(ctx context.Context, addr string) error { return dialErr },
+		cache:      &reportCache{},
+	}
+}
+
+func TestReady(t *testing.T) {
+	report := newTestChecker(nil).Ready(context.Background())
+	if report.Status != StatusOK {
+		t.Fatalf("status = %s, checks = %+v", report.Status, report.Checks)
+	}
+	if len(report.Checks) != 4 {
+		t.Errorf("checks = %+v", report.Checks)
+	}
+}
+
+func TestReadyFailsWhenMQUnreachable(t *testing.T) {
+	report := newTestChecker(errors.New("connection refused")).Ready(context.Background())
+	if report.Status != StatusFail {
+		t.Fatalf("status = %s", report.Status)
+	}
+	for _, check := range report.Checks {
+		if check.Name == "mq" && (check.Status != StatusFail || check.Message != "connection refused") {
+			t.Errorf("mq check = %+v", check)
+		}
+	}
+}
+
+func TestReadyFailsWhenProducerNotStarted(t *testing.T) {
+	c := newTestChecker(nil)
+	c.mqReady = func() bool { return false }
+	if report := c.Ready(context.Background()); report.Status != StatusFail {
+		t.Errorf("status = %s", report.Status)
+	}
+}
+
+func TestReadyFailsWhileShuttingDown(t *testing.T) {
+	SetShuttingDown()
+	defer shuttingDown.Store(false)
+	if report := newTestChecker(nil).Ready(context.Background()); report.Status != StatusFail {
+		t.Errorf("status = %s", report.Status)
+	}
+}
+
+func TestCachedReusesReport(t *testing.T) {
+	c := newTestChecker(nil)
+	dials := 0
+	c.dial = func(ctx context.Context, addr string) error {
+		dials++
+		return nil
+	}
+	first := c.Cached(context.Background())
+	if second := c.Cached(context.Background()); dials != 1 || !second.Timestamp.Equal(first.Timestamp) {
+		t.Fatalf("dials = %d, expected the cached report", dials)
+	}
+	// 开始关闭后不再使用缓存
+	SetShuttingDown()
+	defer shuttingDown.Store(false)
+	if report := c.Cached(context.Background()); report.Status != StatusFail || dials != 2 {
+		t.Errorf("status = %s, dials = %d", report.Status, dials)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	report := newTestChecker(errors.New("dial tcp mq:8081: connection refused")).Ready(context.Background())
+	summary := report.Summary()
+	if summary.Status != StatusFail || len(summary.Checks) != len(report.Checks) {
+		t.Fatalf("summary = %+v", summary)
+	}
+	for _, check := range summary.Checks {
+		if check.Name == "" || check.Message != "" || check.Details != nil {
+			t.Errorf("summary check = %+v", check)
+		}
+	}
+}
+
+func TestWorse(t *testing.T) {
+	if worse(StatusDegraded, StatusOK) != StatusDegraded || worse(StatusDegraded, StatusFail) != StatusFail {
+		t.Error("worse should keep the most severe status")
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	for url, want := range map[string]string{
+		"grpcs://peer0.org1.example.com:7051": "peer0.org1.example.com:7051",
+		"grpc://orderer:7050":                 "orderer:7050",
+		"peer1:8051":                          "peer1:8051",
+	} {
+		if got := hostPort(url); got != want {
+			t.Errorf("hostPort(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package hsm 让 SDK 使用 PKCS#11 硬件安全模块中的私钥签名，组织管理员与用户的私钥不离开 HSM。
+// 连接配置中的用户只需提供证书，SDK 以证书公钥的 SKI（未压缩公钥点的 SHA-256）查找 HSM 中 CKA_ID 相同的私钥对象。
+// ECDSA 私钥使用 SDK 自带的 PKCS#11 密码套件，SM2 私钥使用国密 HSM 厂商定义的密钥类型与签名机制。
+package hsm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	p11suite "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/pkcs11"
+	sdkp11 "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/common/pkcs11"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defcore"
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+// Default 全局 HSM 配置，为 nil 时 SDK 使用软件密码套件
+var Default *Provider
+
+// Provider 已登录的 PKCS#11 令牌
+type Provider struct {
+	cfg define.HSMConfig
+	ctx *sdkp11.ContextHandle
+}
+
+// New 按配置加载 PKCS#11 库并登录令牌，库路径、标签或 PIN 错误时返回错误；未启用时返回 nil
+func New(cfg define.HSMConfig) (*Provider, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.Library == "" {
+		return nil, errors.New("hsm.library is required")
+	}
+	if cfg.Label == "" {
+		return nil, errors.New("hsm.label is required")
+	}
+	if (cfg.SM2KeyType == 0) != (cfg.SM2Mechanism == 0) {
+		return nil, errors.New("hsm.sm2KeyType and hsm.sm2Mechanism must be set together")
+	}
+	// 上下文按库路径与标签缓存，SDK 的 PKCS#11 密码套件复用同一个上下文与会话池
+	ctx, err := sdkp11.LoadContextAndLogin(cfg.Library, cfg.Pin, cfg.Label)
+	if err != nil {
+		return nil, fmt.Errorf("login pkcs11 token %q: %w", cfg.Label, err)
+	}
+	return &Provider{cfg: cfg, ctx: ctx}, nil
+}
+
+// CoreProviderFactory 返回使用 HSM 的 SDK 核心组件工厂，作为 fabsdk.WithCorePkg 的参数
+func (p *Provider) CoreProviderFactory() *CoreProviderFactory {
+	return &CoreProviderFactory{provider: p}
+}
+
+// CoreProviderFactory 在 SDK 默认的核心组件之上替换密码套件。
+// 库路径、标签与 PIN 只取自服务配置，连接配置由调用方提交，其中的 BCCSP 安全配置不用于加载 PKCS#11 库
+type CoreProviderFactory struct {
+	defcore.ProviderFactory
+	provider *Provider
+}
+
+// CreateCryptoSuiteProvider 创建 PKCS#11 密码套件，哈希与验签仍在软件中完成
+func (f *CoreProviderFactory) CreateCryptoSuiteProvider(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
+	suite, err := p11suite.GetSuiteByConfig(&cryptoConfig{CryptoSuiteConfig: config, cfg: f.provider.cfg})
+	if err != nil {
+		return nil, err
+	}
+	var signer sm2Signer
+	if f.provider.cfg.SM2KeyType != 0 {
+		signer = &p11SM2{ctx: f.provider.ctx, keyType: f.provider.cfg.SM2KeyType, mechanism: f.provider.cfg.SM2Mechanism}
+	}
+	return newCryptoSuite(suite, signer), nil
+}
+
+// cryptoConfig 以服务配置覆盖连接配置中的安全提供者设置
+type cryptoConfig struct {
+	core.CryptoSuiteConfig
+	cfg define.HSMConfig
+}
+
+func (c *cryptoConfig) SecurityProvider() string        { return "pkcs11" }
+func (c *cryptoConfig) SecurityProviderLibPath() string { return c.cfg.Library }
+func (c *cryptoConfig) SecurityProviderPin() string     { return c.cfg.Pin }
+func (c *cryptoConfig) SecurityProviderLabel() string   { return c.cfg.Label }
+func (c *cryptoConfig) SoftVerify() bool                { return c.cfg.SoftVerify }
+
+// SecurityAlgorithm PKCS#11 密码套件只支持 SHA2 与 SHA3，国密配置的 SM3 哈希由 SDK 按签名选项单独处理
+func (c *cryptoConfig) SecurityAlgorithm() string {
+	if alg := c.CryptoSuiteConfig.SecurityAlgorithm(); alg == "SHA3" {
+		return alg
+	}
+	return "SHA2"
+}
+
+// SecurityLevel PKCS#11 密码套件只支持 256 与 384
+func (c *cryptoConfig) SecurityLevel() int {
+	if level := c.CryptoSuiteConfig.SecurityLevel(); level == 384 {
+		return level
+	}
+	return 256
+}
@@ -0,0 +1,245 @@
+package hsm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"gitee.com/china_uni/tjfoc-gm/sm2"
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+func TestNewInvalidConfig(t *testing.T) {
+	p, err := New(define.HSMConfig{})
+	if err != nil || p != nil {
+		t.Fatalf("New() = %v, %v", p, err)
+	}
+	cases := []define.HSMConfig{
+		{Enabled: true, Label: "fabric"},
+		{Enabled: true, Library: "/usr/lib/softhsm/libsofthsm2.so"},
+		{Enabled: true, Library: "/usr/lib/softhsm/libsofthsm2.so", Label: "fabric", SM2KeyType: 0x80000001},
+	}
+	for _, cfg := range cases {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) expected error", cfg)
+		}
+	}
+}
+
+func TestSM2CryptoSuite(t *testing.T) {
+	soft, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, certPEM := newSM2Cert(t)
+	signer := &fakeSM2{keys: map[string]*sm2.PrivateKey{}}
+	suite := newCryptoSuite(soft, signer)
+
+	pub, err := cryptoutil.GetPublicKeyFromCert(certPEM, suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// HSM 中没有私钥时查找失败
+	if _, err := suite.GetKey(pub.SKI()); err == nil {
+		t.Fatal("expected error when private key is not in hsm")
+	}
+	signer.keys[string(pub.SKI())] = key
+
+	priv, err := suite.GetKey(pub.SKI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Private() {
+		t.Fatal("GetKey returned a public key")
+	}
+	if _, err := priv.Bytes(); err == nil {
+		t.Error("hsm private key must not be exportable")
+	}
+	digest, err := suite.Hash([]byte("proposal"), cryptosuite.GetSM3Opts())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := suite.Sign(priv, digest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 签名与 SDK 软件签名的格式和规则一致
+	r, s, err := sm2.SignDataToSignDigit(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sm2.Verify(&key.PublicKey, digest, r, s) {
+		t.Error("sm2 signature does not verify")
+	}
+	if ok, err := suite.Verify(priv, sig, digest, nil); err != nil || !ok {
+		t.Errorf("Verify() = %v, %v", ok, err)
+	}
+}
+
+// TestSoftHSM 在 SoftHSM 令牌中生成 ECDSA 私钥，按证书查找私钥并签名。
+// 需要设置 PKCS11_LIB、PKCS11_PIN 与 PKCS11_LABEL，令牌可用 softhsm2-util --init-token 创建
+func TestSoftHSM(t *testing.T) {
+	lib := os.Getenv("PKCS11_LIB")
+	if lib == "" {
+		t.Skip("PKCS11_LIB is not set")
+	}
+	p, err := New(define.HSMConfig{
+		Enabled:    true,
+		Library:    lib,
+		Pin:        os.Getenv("PKCS11_PIN"),
+		Label:      os.Getenv("PKCS11_LABEL"),
+		SoftVerify: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite, err := p.CoreProviderFactory().CreateCryptoSuiteProvider(cryptosuite.ConfigFromBackend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := suite.KeyGen(cryptosuite.GetECDSAP256KeyGenOpts(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey, err := generated.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := pubKey.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := stdx509.ParsePKIXPublicKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := newECDSACert(t, pub.(*ecdsa.PublicKey))
+
+	certKey, err := cryptoutil.GetPublicKeyFromCert(certPEM, suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := suite.GetKey(certKey.SKI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Private() {
+		t.Fatal("GetKey returned a public key")
+	}
+	digest := sha256.Sum256([]byte("proposal"))
+	sig, err := suite.Sign(priv, digest[:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := suite.Verify(certKey, sig, digest[:], nil); err != nil || !ok {
+		t.Errorf("Verify() = %v, %v", ok, err)
+	}
+}
+
+// fakeSM2 以软件私钥模拟 HSM 的 SM2 签名机制：输入摘要 e，输出 r || s
+type fakeSM2 struct {
+	keys map[string]*sm2.PrivateKey
+}
+
+func (f *fakeSM2) find(ski []byte) error {
+	if _, ok := f.keys[string(ski)]; !ok {
+		return errors.New("not found")
+	}
+	return nil
+}
+
+func (f *fakeSM2) sign(ski, e []byte) ([]byte, error) {
+	priv, ok := f.keys[string(ski)]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	n := priv.Curve.Params().N
+	ei := new(big.Int).SetBytes(e)
+	for {
+		k, err := rand.Int(rand.Reader, new(big.Int).Sub(n, big.NewInt(1)))
+		if err != nil {
+			return nil, err
+		}
+		k.Add(k, big.NewInt(1))
+		x1, _ := priv.Curve.ScalarBaseMult(k.Bytes())
+		r := new(big.Int).Add(ei, x1)
+		r.Mod(r, n)
+		if r.Sign() == 0 || new(big.Int).Add(r, k).Cmp(n) == 0 {
+			continue
+		}
+		// s = (1 + d)^-1 * (k - r * d) mod n
+		s := new(big.Int).Sub(k, new(big.Int).Mul(r, priv.D))
+		s.Mul(s, new(big.Int).ModInverse(new(big.Int).Add(priv.D, big.NewInt(1)), n))
+		s.Mod(s, n)
+		if s.Sign() == 0 {
+			continue
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	}
+}
+
+func newSM2Cert(t *testing.T) (*sm2.PrivateKey, []byte) {
+	t.Helper()
+	key, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:       big.NewInt(1),
+		Subject:            pkix.Name{CommonName: "Admin@org1"},
+		NotBefore:          time.Now().Add(-time.Hour),
+		NotAfter:           time.Now().Add(time.Hour),
+		SignatureAlgorithm: x509.SM2WithSM3,
+	}
+	certPEM, err := x509.CreateCertificateToMem(tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, certPEM
+}
+
+// newECDSACert 以临时 CA 为 HSM 中的公钥签发证书
+func newECDSACert(t *testing.T, pub *ecdsa.PublicKey) []byte {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &stdx509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              stdx509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	tmpl := &stdx509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Admin@org1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     stdx509.KeyUsageDigitalSignature,
+	}
+	der, err := stdx509.CreateCertificate(rand.Reader, tmpl, ca, pub, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -0,0 +1,179 @@
+package hsm
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"gitee.com/china_uni/tjfoc-gm/sm2"
+	"gitee.com/china_uni/tjfoc-gm/sm3"
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	sdkp11 "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/common/pkcs11"
+	"github.com/miekg/pkcs11"
+)
+
+// sm2UID SM2 签名的默认用户标识，与 SDK 软件签名一致
+var sm2UID = []byte("1234567812345678")
+
+// sm2Signer 在 HSM 中查找 SM2 私钥并对摘要 e 签名
+type sm2Signer interface {
+	find(ski []byte) error
+	sign(ski, e []byte) ([]byte, error)
+}
+
+// cryptoSuite 在 PKCS#11 密码套件之上增加 SM2 私钥。SDK 先从证书导入公钥再按 SKI 获取私钥，
+// 导入时记录 SM2 公钥，签名时据此计算 Z 值
+type cryptoSuite struct {
+	core.CryptoSuite
+	signer sm2Signer
+
+	mu      sync.RWMutex
+	sm2Keys map[string]*sm2PrivateKey
+}
+
+func newCryptoSuite(suite core.CryptoSuite, signer sm2Signer) core.CryptoSuite {
+	if signer == nil {
+		return suite
+	}
+	return &cryptoSuite{CryptoSuite: suite, signer: signer, sm2Keys: make(map[string]*sm2PrivateKey)}
+}
+
+// KeyImport 导入证书公钥时记录 SM2 公钥
+func (c *cryptoSuite) KeyImport(raw interface{}, opts core.KeyImportOpts) (core.Key, error) {
+	k, err := c.CryptoSuite.KeyImport(raw, opts)
+	if err != nil {
+		return nil, err
+	}
+	if cert, ok := raw.(*x509.Certificate); ok {
+		if pub, ok := cert.PublicKey.(*sm2.PublicKey); ok {
+			c.mu.Lock()
+			c.sm2Keys[hex.EncodeToString(k.SKI())] = &sm2PrivateKey{pub: k, sm2: pub}
+			c.mu.Unlock()
+		}
+	}
+	return k, nil
+}
+
+// GetKey HSM 中存在 SKI 对应的 SM2 私钥时返回该私钥，否则按 PKCS#11 密码套件查找
+func (c *cryptoSuite) GetKey(ski []byte) (core.Key, error) {
+	c.mu.RLock()
+	key, ok := c.sm2Keys[hex.EncodeToString(ski)]
+	c.mu.RUnlock()
+	if ok {
+		if err := c.signer.find(ski); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	return c.CryptoSuite.GetKey(ski)
+}
+
+// Sign SM2 私钥在 HSM 中签名，其他私钥交给 PKCS#11 密码套件
+func (c *cryptoSuite) Sign(k core.Key, digest []byte, opts core.SignerOpts) ([]byte, error) {
+	key, ok := k.(*sm2PrivateKey)
+	if !ok {
+		return c.CryptoSuite.Sign(k, digest, opts)
+	}
+	return signSM2(key.sm2, digest, func(e []byte) ([]byte, error) {
+		return c.signer.sign(key.SKI(), e)
+	})
+}
+
+// Verify SM2 私钥以对应的公钥验签
+func (c *cryptoSuite) Verify(k core.Key, signature, digest []byte, opts core.SignerOpts) (bool, error) {
+	if key, ok := k.(*sm2PrivateKey); ok {
+		k = key.pub
+	}
+	return c.CryptoSuite.Verify(k, signature, digest, opts)
+}
+
+// sm2PrivateKey HSM 中的 SM2 私钥，只保存公钥部分
+type sm2PrivateKey struct {
+	pub core.Key
+	sm2 *sm2.PublicKey
+}
+
+func (k *sm2PrivateKey) Bytes() ([]byte, error) {
+	return nil, errors.New("not supported: private key is stored in hsm")
+}
+func (k *sm2PrivateKey) SKI() []byte                  { return k.pub.SKI() }
+func (k *sm2PrivateKey) Symmetric() bool              { return false }
+func (k *sm2PrivateKey) Private() bool                { return true }
+func (k *sm2PrivateKey) PublicKey() (core.Key, error) { return k.pub, nil }
+
+// signSM2 按 SM2 签名规则计算 e = SM3(Z || M)，M 为 SDK 传入的摘要，由 sign 对 e 签名并返回 r || s，
+// 结果转换为与 SDK 软件签名相同的 DER 编码
+func signSM2(pub *sm2.PublicKey, msg []byte, sign func(e []byte) ([]byte, error)) ([]byte, error) {
+	za, err := sm2.ZA(pub, sm2UID)
+	if err != nil {
+		return nil, err
+	}
+	h := sm3.New()
+	h.Write(za)
+	h.Write(msg)
+	raw, err := sign(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != 64 {
+		return nil, fmt.Errorf("unexpected sm2 signature length %d, want 64 (r || s)", len(raw))
+	}
+	return sm2.SignDigitToSignData(new(big.Int).SetBytes(raw[:32]), new(big.Int).SetBytes(raw[32:]))
+}
+
+// p11SM2 以厂商定义的密钥类型与签名机制在 PKCS#11 令牌中签名
+type p11SM2 struct {
+	ctx       *sdkp11.ContextHandle
+	keyType   uint
+	mechanism uint
+}
+
+func (p *p11SM2) find(ski []byte) error {
+	session := p.ctx.GetSession()
+	defer p.ctx.ReturnSession(session)
+	_, err := p.findPrivateKey(session, ski)
+	return err
+}
+
+func (p *p11SM2) sign(ski, e []byte) ([]byte, error) {
+	session := p.ctx.GetSession()
+	defer p.ctx.ReturnSession(session)
+	key, err := p.findPrivateKey(session, ski)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.ctx.SignInit(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(p.mechanism, nil)}, key); err != nil {
+		return nil, fmt.Errorf("sm2 sign init: %w", err)
+	}
+	sig, err := p.ctx.Sign(session, e)
+	if err != nil {
+		return nil, fmt.Errorf("sm2 sign: %w", err)
+	}
+	return sig, nil
+}
+
+// findPrivateKey 按 CKA_ID 查找 SM2 私钥对象
+func (p *p11SM2) findPrivateKey(session pkcs11.SessionHandle, ski []byte) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, p.keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, ski),
+	}
+	if err := p.ctx.FindObjectsInit(session, template); err != nil {
+		return 0, fmt.Errorf("find sm2 private key: %w", err)
+	}
+	objects, _, err := p.ctx.FindObjects(session, 1)
+	if finalErr := p.ctx.FindObjectsFinal(session); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("find sm2 private key: %w", err)
+	}
+	if len(objects) == 0 {
+		return 0, fmt.Errorf("sm2 private key with SKI %x not found in hsm", ski)
+	}
+	return objects[0], nil
+}
@@ -0,0 +1,412 @@
+// Package idempotency 保存带幂等键的交易提交结果。客户端超时后以相同幂等键重试时返回首次提交的结果
+// 或处理中状态，不再生成新的提案与交易 ID 重复调用合约。
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// Header 传递幂等键的请求头，gRPC 接口使用同名元数据
+const Header = "Idempotency-Key"
+
+// ReplayedHeader 响应为保存的首次提交结果时设置的响应头
+const ReplayedHeader = "Idempotent-Replayed"
+
+// MaxKeyLength 幂等键的最大长度
+const MaxKeyLength = 255
+
+// defaultRetention 未配置时幂等记录的保留时间
+const defaultRetention = 24 * time.Hour
+
+// defaultSubmitTimeout 未配置时带幂等键的提交的最长时间
+const defaultSubmitTimeout = 2 * time.Minute
+
+// purgeInterval 清理过期记录并写入记录文件的间隔
+const purgeInterval = time.Minute
+
+var (
+	// ErrInProgress 相同幂等键的请求仍在处理中
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+	// ErrKeyReused 幂等键已用于内容不同的请求
+	ErrKeyReused = errors.New("idempotency key was already used for a different request")
+)
+
+// Default 全局幂等记录，为 nil 时不启用，请求中的幂等键被忽略
+var Default *Store
+
+// State 幂等记录的状态
+type State string
+
+const (
+	StatePending   State = "pending"   // 首次提交处理中
+	StateCompleted State = "completed" // 已保存提交结果
+)
+
+// Outcome 交易提交的结果，失败时 Error 不为空
+type Outcome struct {
+	TxId    string           `json:"txId,omitempty"`
+	Payload string           `json:"payload,omitempty"`
+	Height  uint64           `json:"height,omitempty"`
+	Status  int              `json:"status,omitempty"` // 失败时的 HTTP 状态码
+	Message string           `json:"message,omitempty"`
+	Error   *utils.ErrorInfo `json:"error,omitempty"`
+}
+
+// Success 提交成功的结果
+func Success(payload []byte, txId string, height uint64) *Outcome {
+	return &Outcome{TxId: txId, Payload: string(payload), Height: height}
+}
+
+// Failure 提交失败的结果，txId 不为空表示交易已发送排序，可能已经上链
+func Failure(err error, txId string) *Outcome {
+	info := utils.ClassifyError(err)
+	return &Outcome{TxId: txId, Status: info.Status, Message: err.Error(), Error: info}
+}
+
+// resubmittable 提交失败且交易确定没有写入账本：交易未发送排序，或已被判定为无效交易
+func (o *Outcome) resubmittable() bool {
+	if o.Error == nil {
+		return false
+	}
+	if o.TxId == "" {
+		return true
+	}
+	return o.Error.Fabric != nil && o.Error.Fabric.Group == status.EventServerStatus.String()
+}
+
+// Record 幂等记录
+type Record struct {
+	Fingerprint string    `json:"fingerprint"`
+	State       State     `json:"state"`
+	Outcome     *Outcome  `json:"outcome,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Store 幂等记录，按保留时间清理，配置了记录文件时定期及关闭时写入文件
+type Store struct {
+	retention     time.Duration
+	file          string
+	submitTimeout time.Duration
+	now           func() time.Time
+
+	mu      sync.Mutex
+	records map[string]*Record
+	dirty   bool
+	// saveMu 串行化记录文件的写入，写入时不持有 mu，不阻塞其他幂等键的提交
+	saveMu sync.Mutex
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New 按配置创建幂等记录并读取记录文件，未启用时返回 nil
+func New(cfg define.IdempotencyConfig) (*Store, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	s := newStore(cfg.Retention, cfg.File)
+	if cfg.SubmitTimeout > 0 {
+		s.submitTimeout = cfg.SubmitTimeout
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	go s.run()
+	return s, nil
+}
+
+func newStore(retention time.Duration, file string) *Store {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	return &Store{
+		retention:     retention,
+		file:          file,
+		submitTimeout: defaultSubmitTimeout,
+		now:           time.Now,
+		records:       make(map[string]*Record),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Detach 返回带幂等键的提交使用的上下文：不随请求取消，超时由服务端决定。客户端超时断开后提交继续执行，
+// 保存的是交易的真实结果而不是 context canceled。s 为 nil 或 key 为空时返回 ctx
+func (s *Store) Detach(ctx context.Context, key string) (context.Context, context.CancelFunc) {
+	if s == nil || key == "" {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(context.WithoutCancel(ctx), s.submitTimeout)
+}
+
+// Key 以调用方与连接配置限定幂等键，不同调用方使用相同的幂等键互不影响
+func Key(caller, sdkId, key string) string {
+	return caller + "\n" + sdkId + "\n" + key
+}
+
+// Fingerprint 请求内容摘要，相同幂等键用于内容不同的请求时拒绝
+func Fingerprint(chaincode, function string, args []string) string {
+	data, _ := json.Marshal(struct {
+		Chaincode string   `json:"chaincode"`
+		Function  string   `json:"function"`
+		Args      []string `json:"args"`
+	}{chaincode, function, args})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateKey 校验幂等键：长度不超过 MaxKeyLength，只包含可见 ASCII 字符
+func ValidateKey(key string) error {
+	if len(key) > MaxKeyLength {
+		return fmt.Errorf("idempotency key longer than %d characters", MaxKeyLength)
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return errors.New("idempotency key must contain only visible ASCII characters")
+		}
+	}
+	return nil
+}
+
+// RequestKey 合并请求头与请求体中的幂等键，两者同时提供时必须相同
+func RequestKey(header, body string) (string, error) {
+	if header != "" && body != "" && header != body {
+		return "", errors.New("idempotency key in header and body differ")
+	}
+	key := header
+	if key == "" {
+		key = body
+	}
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Do 以幂等键执行 submit 并保存结果。已保存结果时直接返回且 replayed 为 true，首次提交仍在处理中时返回
+// ErrInProgress，幂等键用于内容不同的请求时返回 ErrKeyReused。提交确定没有写入账本时删除记录，重试会重新提交。
+// s 为 nil 或 key 为空时直接执行 submit
+func (s *Store) Do(key, fingerprint string, submit func() *Outcome) (outcome *Outcome, replayed bool, err error) {
+	if s == nil || key == "" {
+		return submit(), false, nil
+	}
+	if rec, err := s.begin(key, fingerprint); err != nil || rec != nil {
+		if err != nil {
+			return nil, false, err
+		}
+		return rec.Outcome, true, nil
+	}
+	// 提交前后立即写入记录文件：进程在提交中退出时重启后该幂等键的结果为未知，提交完成后退出时重试返回保存的结果
+	s.persist()
+
+	completed := false
+	defer func() {
+		// submit 发生 panic 时删除记录，避免重试一直返回处理中
+		if !completed {
+			s.remove(key)
+		}
+	}()
+	outcome = submit()
+	completed = true
+	if outcome.resubmittable() {
+		s.remove(key)
+	} else {
+		s.complete(key, outcome)
+	}
+	s.persist()
+	return outcome, false, nil
+}
+
+// begin 登记首次提交，已有记录时返回已完成的记录或错误
+func (s *Store) begin(key, fingerprint string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if rec, ok := s.records[key]; ok && !s.expired(rec, now) {
+		switch {
+		case rec.Fingerprint != fingerprint:
+			return nil, ErrKeyReused
+		case rec.State != StateCompleted:
+			return nil, ErrInProgress
+		default:
+			return rec, nil
+		}
+	}
+	s.records[key] = &Record{Fingerprint: fingerprint, State: StatePending, CreatedAt: now}
+	s.dirty = true
+	return nil, nil
+}
+
+func (s *Store) complete(key string, outcome *Outcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[key]; ok {
+		rec.State, rec.Outcome = StateCompleted, outcome
+		s.dirty = true
+	}
+}
+
+func (s *Store) remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	s.dirty = true
+}
+
+// persist 写入记录文件，失败时由定期写入重试
+func (s *Store) persist() {
+	if err := s.save(); err != nil {
+		slog.Warn("save idempotency records failed", "file", s.file, "error", err)
+	}
+}
+
+// interrupted 进程在首次提交处理中退出，交易可能已经上链也可能没有发送
+func interrupted() *Outcome {
+	info := utils.NewErrorInfo(utils.CodeIdempotencyUnknown)
+	return &Outcome{
+		Status:  info.Status,
+		Message: "the server stopped while the first request with this idempotency key was in progress, the transaction may have been committed; check the ledger and retry with a new idempotency key",
+		Error:   info,
+	}
+}
+
+func (s *Store) expired(rec *Record, now time.Time) bool {
+	return now.Sub(rec.CreatedAt) >= s.retention
+}
+
+// purge 删除过期记录
+func (s *Store) purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for key, rec := range s.records {
+		if s.expired(rec, now) {
+			delete(s.records, key)
+			s.dirty = true
+		}
+	}
+}
+
+func (s *Store) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.purge()
+			s.persist()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close 停止定期清理并写入记录文件
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	close(s.stop)
+	<-s.done
+	s.purge()
+	return s.save()
+}
+
+// load 读取记录文件，文件不存在时直接返回
+func (s *Store) load() error {
+	if s.file == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var records map[string]*Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("parse idempotency file %s: %w", s.file, err)
+	}
+	for key, rec := range records {
+		// 处理中的记录来自上次进程退出时未完成的提交，结果未知，不能返回处理中也不能重新提交
+		if rec.State != StateCompleted {
+			rec.State, rec.Outcome = StateCompleted, interrupted()
+			s.dirty = true
+		}
+		// ErrorInfo 中的 HTTP 状态码不参与序列化，按保存的状态码恢复
+		if rec.Outcome != nil && rec.Outcome.Error != nil {
+			rec.Outcome.Error.Status = rec.Outcome.Status
+		}
+		s.records[key] = rec
+	}
+	s.purge()
+	return nil
+}
+
+// save 记录有变化时写入记录文件，写入失败时保留变化标记以便下次重试。持有 mu 时只复制记录，
+// 序列化与写入文件在 mu 之外进行；saveMu 保证较新的快照不会被较早的快照覆盖
+func (s *Store) save() error {
+	if s.file == "" {
+		return nil
+	}
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	records := s.snapshot()
+	if records == nil {
+		return nil
+	}
+	err := s.write(records)
+	if err != nil {
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// snapshot 复制有变化的记录并清除变化标记，没有变化时返回 nil。complete 替换而不修改 Outcome，复制记录本身即可
+func (s *Store) snapshot() map[string]Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	records := make(map[string]Record, len(s.records))
+	for key, rec := range s.records {
+		records[key] = *rec
+	}
+	s.dirty = false
+	return records
+}
+
+func (s *Store) write(records map[string]Record) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.file), 0o700); err != nil {
+		return err
+	}
+	// 先写临时文件再重命名，避免写入中断留下不完整的记录文件
+	tmp := s.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.file)
+}
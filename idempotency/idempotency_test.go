@@ -0,0 +1,253 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+func TestDoReplaysCompletedOutcome(t *testing.T) {
+	s := newStore(time.Hour, "")
+	fp := Fingerprint("points", "Transfer", []string{"a", "b", "10"})
+	calls := 0
+	submit := func() *Outcome {
+		calls++
+		return Success([]byte("ok"), "tx1", 7)
+	}
+
+	first, replayed, err := s.Do("k1", fp, submit)
+	if err != nil || replayed || first.TxId != "tx1" {
+		t.Fatalf("first = %+v, %v, %v", first, replayed, err)
+	}
+	second, replayed, err := s.Do("k1", fp, submit)
+	if err != nil || !replayed || second.TxId != "tx1" || second.Height != 7 {
+		t.Fatalf("second = %+v, %v, %v", second, replayed, err)
+	}
+	if calls != 1 {
+		t.Errorf("submit called %d times, want 1", calls)
+	}
+
+	if _, _, err := s.Do("k1", Fingerprint("points", "Transfer", []string{"a", "b", "20"}), submit); !errors.Is(err, ErrKeyReused) {
+		t.Errorf("reused key error = %v", err)
+	}
+}
+
+func TestDoInProgress(t *testing.T) {
+	s := newStore(time.Hour, "")
+	started, finish := make(chan struct{}), make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.Do("k1", "fp", func() *Outcome {
+			close(started)
+			<-finish
+			return Success(nil, "tx1", 1)
+		})
+	}()
+	<-started
+	if _, _, err := s.Do("k1", "fp", func() *Outcome { t.Fatal("resubmitted"); return nil }); !errors.Is(err, ErrInProgress) {
+		t.Errorf("err = %v, want in progress", err)
+	}
+	close(finish)
+	wg.Wait()
+}
+
+func TestDoResubmitsWhenNotCommitted(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcome  *Outcome
+		resubmit bool
+	}{
+		{"endorsement failed", Failure(status.New(status.ChaincodeStatus, 500, "insufficient balance", nil), ""), true},
+		{"invalid transaction", Failure(status.New(status.EventServerStatus, int32(pb.TxValidationCode_MVCC_READ_CONFLICT), "received invalid transaction", nil), "tx1"), true},
+		{"commit timed out", Failure(status.New(status.ClientStatus, status.Timeout.ToInt32(), "request timed out or been cancelled", nil), "tx1"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(time.Hour, "")
+			s.Do("k1", "fp", func() *Outcome { return tt.outcome })
+			calls := 0
+			_, replayed, err := s.Do("k1", "fp", func() *Outcome { calls++; return Success(nil, "tx2", 1) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resubmitted := calls == 1 && !replayed; resubmitted != tt.resubmit {
+				t.Errorf("resubmitted = %v, want %v", resubmitted, tt.resubmit)
+			}
+		})
+	}
+}
+
+func TestRetention(t *testing.T) {
+	s := newStore(time.Hour, "")
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	s.Do("k1", "fp", func() *Outcome { return Success(nil, "tx1", 1) })
+
+	now = now.Add(2 * time.Hour)
+	s.purge()
+	if len(s.records) != 0 {
+		t.Errorf("expired records kept: %d", len(s.records))
+	}
+	if _, replayed, _ := s.Do("k1", "fp", func() *Outcome { return Success(nil, "tx2", 2) }); replayed {
+		t.Error("expired key replayed")
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "idempotency.json")
+	s := newStore(time.Hour, file)
+	s.Do("k1", "fp", func() *Outcome {
+		return Failure(status.New(status.ClientStatus, status.Timeout.ToInt32(), "timed out", nil), "tx1")
+	})
+	if err := s.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newStore(time.Hour, file)
+	if err := restored.load(); err != nil {
+		t.Fatal(err)
+	}
+	outcome, replayed, err := restored.Do("k1", "fp", func() *Outcome { t.Fatal("resubmitted"); return nil })
+	if err != nil || !replayed {
+		t.Fatalf("replay = %v, %v", replayed, err)
+	}
+	if outcome.TxId != "tx1" || outcome.Error.Code != utils.CodeTimeout || outcome.Error.Status != http.StatusGatewayTimeout {
+		t.Errorf("restored outcome = %+v, error = %+v", outcome, outcome.Error)
+	}
+}
+
+func TestDetach(t *testing.T) {
+	s := newStore(time.Hour, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	detached, stop := s.Detach(ctx, "k1")
+	defer stop()
+	cancel()
+	// 客户端断开后提交继续执行，超时由服务端决定
+	if detached.Err() != nil {
+		t.Errorf("detached context canceled with request: %v", detached.Err())
+	}
+	if deadline, ok := detached.Deadline(); !ok || time.Until(deadline) > defaultSubmitTimeout {
+		t.Errorf("deadline = %v, %v", deadline, ok)
+	}
+	if same, _ := s.Detach(ctx, ""); same != ctx {
+		t.Error("request without idempotency key detached")
+	}
+	var disabled *Store
+	if same, _ := disabled.Detach(ctx, "k1"); same != ctx {
+		t.Error("disabled store detached")
+	}
+}
+
+func TestPersistBeforeAndAfterSubmit(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "idempotency.json")
+	s := newStore(time.Hour, file)
+	// 提交中进程退出：记录文件中为处理中的记录
+	s.Do("k1", "fp", func() *Outcome {
+		crashed := newStore(time.Hour, file)
+		if err := crashed.load(); err != nil {
+			t.Fatal(err)
+		}
+		outcome, replayed, err := crashed.Do("k1", "fp", func() *Outcome { t.Error("resubmitted after restart"); return nil })
+		if err != nil || !replayed || outcome.Error == nil || outcome.Error.Code != utils.CodeIdempotencyUnknown || outcome.Error.Status != http.StatusConflict {
+			t.Errorf("restored pending record = %+v, %v, %v", outcome, replayed, err)
+		}
+		return Success(nil, "tx1", 1)
+	})
+
+	// 提交完成后立即写入，不等待定期写入
+	restored := newStore(time.Hour, file)
+	if err := restored.load(); err != nil {
+		t.Fatal(err)
+	}
+	outcome, replayed, err := restored.Do("k1", "fp", func() *Outcome { t.Fatal("resubmitted"); return nil })
+	if err != nil || !replayed || outcome.TxId != "tx1" || outcome.Error != nil {
+		t.Errorf("restored outcome = %+v, %v, %v", outcome, replayed, err)
+	}
+}
+
+func TestConcurrentSubmitsPersistLatestRecords(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "idempotency.json")
+	s := newStore(time.Hour, file)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			s.Do(key, "fp", func() *Outcome { return Success(nil, "tx-"+key, 1) })
+		}(fmt.Sprintf("k%d", i))
+	}
+	wg.Wait()
+
+	// 每次提交返回前其记录都已写入，最后写入的快照包含全部已完成的记录
+	restored := newStore(time.Hour, file)
+	if err := restored.load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.records) != 20 {
+		t.Fatalf("restored %d records, want 20", len(restored.records))
+	}
+	for key, rec := range restored.records {
+		if rec.State != StateCompleted || rec.Outcome.TxId != "tx-"+key {
+			t.Errorf("record %s = %+v", key, rec)
+		}
+	}
+}
+
+func TestSaveFailureKeepsDirty(t *testing.T) {
+	dir := t.TempDir()
+	// 父路径是普通文件，写入失败
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := newStore(time.Hour, filepath.Join(blocker, "idempotency.json"))
+	s.Do("k1", "fp", func() *Outcome { return Success(nil, "tx1", 1) })
+	if err := s.save(); err == nil {
+		t.Fatal("save() succeeded")
+	}
+	s.mu.Lock()
+	dirty := s.dirty
+	s.mu.Unlock()
+	if !dirty {
+		t.Error("dirty cleared after failed save")
+	}
+
+	s.file = filepath.Join(dir, "idempotency.json")
+	if err := s.save(); err != nil {
+		t.Fatal(err)
+	}
+	restored := newStore(time.Hour, s.file)
+	if err := restored.load(); err != nil {
+		t.Fatal(err)
+	}
+	if rec := restored.records["k1"]; rec == nil || rec.Outcome.TxId != "tx1" {
+		t.Errorf("restored record = %+v", rec)
+	}
+}
+
+func TestRequestKey(t *testing.T) {
+	if key, err := RequestKey("abc", ""); err != nil || key != "abc" {
+		t.Errorf("header key = %q, %v", key, err)
+	}
+	if key, err := RequestKey("", "abc"); err != nil || key != "abc" {
+		t.Errorf("body key = %q, %v", key, err)
+	}
+	if _, err := RequestKey("abc", "def"); err == nil {
+		t.Error("mismatched keys accepted")
+	}
+	if _, err := RequestKey("a b", ""); err == nil {
+		t.Error("key with space accepted")
+	}
+}
@@ -0,0 +1,267 @@
+package identity
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	stdx509 "crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"gitee.com/china_uni/tjfoc-gm/sm2"
+	"gitee.com/china_uni/tjfoc-gm/sm4"
+	"gitee.com/china_uni/tjfoc-gm/x509"
+)
+
+// 私钥加密算法，均使用 GCM 模式
+const (
+	CipherSM4 = "sm4"
+	CipherAES = "aes"
+)
+
+// 私钥类型
+const (
+	KeyTypeECDSA = "ECDSA"
+	KeyTypeSM2   = "SM2"
+)
+
+// ParseMasterKey 解析十六进制或 base64 编码的主密钥
+func ParseMasterKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("identityStore.masterKey is required")
+	}
+	if key, err := hex.DecodeString(s); err == nil {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("identityStore.masterKey must be hex or base64 encoded")
+}
+
+// newAEAD 按算法名称创建 GCM 加密器
+func newAEAD(name string, key []byte) (cipher.AEAD, error) {
+	var block cipher.Block
+	var err error
+	switch name {
+	case CipherSM4:
+		if len(key) != sm4.BlockSize {
+			return nil, fmt.Errorf("sm4 master key must be %d bytes, got %d", sm4.BlockSize, len(key))
+		}
+		block, err = sm4.NewCipher(key)
+	case CipherAES:
+		block, err = aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("aes master key must be 16, 24 or 32 bytes, got %d", len(key))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cipher %q, must be %s or %s", name, CipherSM4, CipherAES)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// CheckKeyPair 校验证书与私钥（PEM）匹配，返回证书信息，不合法时返回 ErrInvalid
+func CheckKeyPair(certPEM, keyPEM []byte) (*Identity, error) {
+	id, err := parseKeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return id, nil
+}
+
+// parseKeyPair 解析证书与私钥并校验两者匹配，返回证书信息
+func parseKeyPair(certPEM, keyPEM []byte) (*Identity, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil || certBlock.Type != "CERTIFICATE" {
+		return nil, errors.New("certificate must be a PEM encoded CERTIFICATE")
+	}
+	// 国密 x509 同时支持 SM2 与 ECDSA 证书
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, errors.New("private key must be PEM encoded")
+	}
+	if _, ok := keyBlock.Headers["DEK-Info"]; ok || strings.Contains(keyBlock.Type, "ENCRYPTED") {
+		return nil, errors.New("private key must not be password protected")
+	}
+
+	id := &Identity{
+		Certificate: string(pem.EncodeToMemory(certBlock)),
+		Subject:     cert.Subject.String(),
+		Issuer:      cert.Issuer.String(),
+		NotBefore:   cert.NotBefore.UTC(),
+		NotAfter:    cert.NotAfter.UTC(),
+	}
+	switch pub := cert.PublicKey.(type) {
+	case *sm2.PublicKey:
+		key, err := x509.ParsePKCS8UnecryptedPrivateKey(keyBlock.Bytes)
+		if err != nil {
+			if key, err = x509.ParseSm2PrivateKey(keyBlock.Bytes); err != nil {
+				return nil, errors.New("invalid sm2 private key: expected PKCS#8 or SEC 1")
+			}
+		}
+		if key.X.Cmp(pub.X) != 0 || key.Y.Cmp(pub.Y) != 0 {
+			return nil, errors.New("private key does not match certificate")
+		}
+		id.KeyType = KeyTypeSM2
+		id.SKI = ski(elliptic.Marshal(pub.Curve, pub.X, pub.Y))
+	case *ecdsa.PublicKey:
+		key, err := parseECDSAKey(keyBlock.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if key.X.Cmp(pub.X) != 0 || key.Y.Cmp(pub.Y) != 0 {
+			return nil, errors.New("private key does not match certificate")
+		}
+		id.KeyType = KeyTypeECDSA
+		id.SKI = ski(elliptic.Marshal(pub.Curve, pub.X, pub.Y))
+	default:
+		return nil, fmt.Errorf("unsupported certificate public key %T, must be ECDSA or SM2", cert.PublicKey)
+	}
+	return id, nil
+}
+
+// certificateSKI 证书公钥的 SKI，与 SDK 密钥目录中私钥文件的名称一致
+func certificateSKI(certPEM []byte) (string, error) {
+	pub, err := certificatePublicKey(certPEM)
+	if err != nil {
+		return "", err
+	}
+	switch pub := pub.(type) {
+	case *sm2.PublicKey:
+		return ski(elliptic.Marshal(pub.Curve, pub.X, pub.Y)), nil
+	case *ecdsa.PublicKey:
+		return ski(elliptic.Marshal(pub.Curve, pub.X, pub.Y)), nil
+	}
+	return "", fmt.Errorf("unsupported certificate public key %T", pub)
+}
+
+// certificatePublicKey 解析证书（PEM）的公钥，只支持 ECDSA 与 SM2
+func certificatePublicKey(certPEM []byte) (interface{}, error) {
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	switch pub := cert.PublicKey.(type) {
+	case *sm2.PublicKey, *ecdsa.PublicKey:
+		return pub, nil
+	default:
+		return nil, fmt.Errorf("unsupported certificate public key %T, must be ECDSA or SM2", cert.PublicKey)
+	}
+}
+
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("certificate must be a PEM encoded CERTIFICATE")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// attributesOID Fabric CA 写入证书属性的扩展，值为 JSON {"attrs":{"<name>":"<value>"}}
+var attributesOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// CertificateAttribute 返回 Fabric CA 写入证书的属性值，证书没有该属性时 ok 为 false，证书不合法时返回 ErrInvalid
+func CertificateAttribute(certPEM []byte, name string) (value string, ok bool, err error) {
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return "", false, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(attributesOID) {
+			continue
+		}
+		var attrs struct {
+			Attrs map[string]string `json:"attrs"`
+		}
+		if err := json.Unmarshal(ext.Value, &attrs); err != nil {
+			return "", false, fmt.Errorf("%w: invalid certificate attributes: %v", ErrInvalid, err)
+		}
+		value, ok = attrs.Attrs[name]
+		return value, ok, nil
+	}
+	return "", false, nil
+}
+
+// CertificateKeyType 返回证书公钥的类型 ECDSA 或 SM2，证书不合法时返回 ErrInvalid
+func CertificateKeyType(certPEM []byte) (string, error) {
+	pub, err := certificatePublicKey(certPEM)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if _, ok := pub.(*sm2.PublicKey); ok {
+		return KeyTypeSM2, nil
+	}
+	return KeyTypeECDSA, nil
+}
+
+// ecSignature ECDSA 与 SM2 签名的 ASN.1 结构
+type ecSignature struct {
+	R, S *big.Int
+}
+
+// VerifySignature 以证书公钥校验对 digest 的签名（ASN.1 DER 编码的 r、s），返回节点接受的签名：ECDSA 签名的 s 转换为低 s 形式。
+// SM2 签名以 digest 为消息、使用默认用户标识计算，与 SDK 的国密签名一致。证书或签名不合法时返回 ErrInvalid
+func VerifySignature(certPEM, digest, signature []byte) ([]byte, error) {
+	pub, err := certificatePublicKey(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	var sig ecSignature
+	if rest, err := asn1.Unmarshal(signature, &sig); err != nil || len(rest) > 0 || sig.R == nil || sig.S == nil {
+		return nil, fmt.Errorf("%w: signature must be ASN.1 DER encoded (r, s)", ErrInvalid)
+	}
+	switch pub := pub.(type) {
+	case *sm2.PublicKey:
+		if !sm2.Verify(pub, digest, sig.R, sig.S) {
+			return nil, fmt.Errorf("%w: sm2 signature does not match certificate", ErrInvalid)
+		}
+		return signature, nil
+	case *ecdsa.PublicKey:
+		if !ecdsa.Verify(pub, digest, sig.R, sig.S) {
+			return nil, fmt.Errorf("%w: ecdsa signature does not match certificate", ErrInvalid)
+		}
+		// 节点只接受低 s 签名
+		n := pub.Params().N
+		if sig.S.Cmp(new(big.Int).Rsh(n, 1)) <= 0 {
+			return signature, nil
+		}
+		sig.S = new(big.Int).Sub(n, sig.S)
+		return asn1.Marshal(sig)
+	}
+	return nil, fmt.Errorf("%w: unsupported certificate public key %T", ErrInvalid, pub)
+}
+
+func parseECDSAKey(der []byte) (*ecdsa.PrivateKey, error) {
+	if key, err := stdx509.ParsePKCS8PrivateKey(der); err == nil {
+		ec, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key %T, must be ECDSA or SM2", key)
+		}
+		return ec, nil
+	}
+	key, err := stdx509.ParseECPrivateKey(der)
+	if err != nil {
+		return nil, errors.New("invalid ecdsa private key: expected PKCS#8 or SEC 1")
+	}
+	return key, nil
+}
@@ -0,0 +1,440 @@
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitee.com/china_uni/tjfoc-gm/sm2"
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+const (
+	sm4Key = "00112233445566778899aabbccddeeff"
+	aesKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+)
+
+func TestNewDisabled(t *testing.T) {
+	s, err := New(define.IdentityStoreConfig{})
+	if err != nil || s != nil {
+		t.Fatalf("New() = %v, %v", s, err)
+	}
+	if _, err := s.Put("Org1MSP", "admin", nil, nil); !errors.Is(err, ErrDisabled) {
+		t.Errorf("Put on nil store = %v", err)
+	}
+	if _, _, _, err := s.keyPair("Org1MSP", "admin"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("keyPair on nil store = %v", err)
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "identities.json")
+	cases := []define.IdentityStoreConfig{
+		{Enabled: true, MasterKey: sm4Key},
+		{Enabled: true, File: file},
+		{Enabled: true, File: file, MasterKey: aesKey},
+		{Enabled: true, File: file, Cipher: "des", MasterKey: sm4Key},
+		{Enabled: true, File: file, Cipher: CipherAES, MasterKey: "0011"},
+		{Enabled: true, File: file, MasterKey: "not a key!"},
+	}
+	for _, cfg := range cases {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) expected error", cfg)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	for _, c := range []struct{ cipher, key string }{{CipherSM4, sm4Key}, {CipherAES, aesKey}} {
+		for _, keyType := range []string{KeyTypeSM2, KeyTypeECDSA} {
+			t.Run(c.cipher+"/"+keyType, func(t *testing.T) {
+				cfg := define.IdentityStoreConfig{
+					Enabled:   true,
+					File:      filepath.Join(t.TempDir(), "identities.json"),
+					Cipher:    c.cipher,
+					MasterKey: c.key,
+				}
+				s, err := New(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				certPEM, keyPEM := newKeyPair(t, keyType, "Admin@org1")
+				id, err := s.Put("Org1MSP", "Admin", certPEM, keyPEM)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if id.KeyType != keyType || !strings.Contains(id.Subject, "Admin@org1") || id.SKI == "" {
+					t.Errorf("identity = %+v", id)
+				}
+
+				// 存储文件中不含明文私钥
+				data, err := os.ReadFile(cfg.File)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strings.Contains(string(data), "PRIVATE KEY") {
+					t.Error("identity store contains plaintext private key")
+				}
+
+				// 重新加载后私钥可以解密，名称不区分大小写
+				s, err = New(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cert, key, _, err := s.keyPair("Org1MSP", "admin")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(cert) != string(certPEM) || string(key) != string(keyPEM) {
+					t.Error("reloaded key pair differs")
+				}
+				if list := s.List(""); len(list) != 1 || list[0].Name != "Admin" {
+					t.Errorf("List() = %+v", list)
+				}
+				if list := s.List("Org2MSP"); len(list) != 0 {
+					t.Errorf("List(Org2MSP) = %+v", list)
+				}
+
+				// 主密钥错误时启动失败
+				wrong := cfg
+				wrong.MasterKey = strings.Repeat("ff", len(c.key)/2)
+				if _, err := New(wrong); err == nil {
+					t.Error("expected error with wrong master key")
+				}
+
+				if err := s.Delete("Org1MSP", "ADMIN"); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := s.Get("Org1MSP", "Admin"); !errors.Is(err, ErrNotFound) {
+					t.Errorf("Get after delete = %v", err)
+				}
+				if err := s.Delete("Org1MSP", "Admin"); !errors.Is(err, ErrNotFound) {
+					t.Errorf("second Delete = %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestPutInvalid(t *testing.T) {
+	s := newStore(t)
+	certPEM, keyPEM := newKeyPair(t, KeyTypeSM2, "user1")
+	_, otherKey := newKeyPair(t, KeyTypeSM2, "user2")
+	_, ecdsaKey := newKeyPair(t, KeyTypeECDSA, "user3")
+	cases := []struct {
+		name, mspID, user string
+		cert, key         []byte
+	}{
+		{"mismatched key", "Org1MSP", "user1", certPEM, otherKey},
+		{"wrong key type", "Org1MSP", "user1", certPEM, ecdsaKey},
+		{"not pem", "Org1MSP", "user1", []byte("cert"), keyPEM},
+		{"bad name", "Org1MSP", "../user1", certPEM, keyPEM},
+		{"bad msp", "", "user1", certPEM, keyPEM},
+	}
+	for _, c := range cases {
+		if _, err := s.Put(c.mspID, c.user, c.cert, c.key); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: Put() = %v, want ErrInvalid", c.name, err)
+		}
+	}
+	if list := s.List(""); len(list) != 0 {
+		t.Errorf("List() = %+v", list)
+	}
+}
+
+func TestImportEnrollment(t *testing.T) {
+	s := newStore(t)
+	dir := t.TempDir()
+	certPEM, keyPEM := newKeyPair(t, KeyTypeECDSA, "user1")
+	if _, err := s.ImportEnrollment("Org1MSP", "user1", certPEM, dir); err == nil {
+		t.Fatal("expected error when private key file is missing")
+	}
+	ski, err := certificateSKI(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, ski+"_sk")
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	id, err := s.ImportEnrollment("Org1MSP", "user1", certPEM, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.SKI != ski {
+		t.Errorf("SKI = %s, want %s", id.SKI, ski)
+	}
+	// 明文私钥文件已删除，私钥只保存在存储中
+	if _, err := os.Stat(keyFile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("private key file not removed: %v", err)
+	}
+	if _, key, _, err := s.keyPair("Org1MSP", "user1"); err != nil || string(key) != string(keyPEM) {
+		t.Errorf("keyPair() = %v", err)
+	}
+}
+
+func TestCallerName(t *testing.T) {
+	subjects := []string{
+		"apikey:alice",
+		"jwt:alice",
+		"jwt:Alice",
+		"cert:Alice Smith",
+		"jwt:" + strings.Repeat("a", 130),
+		// 与其他调用方的摘要名称形式相同的调用方名称
+		"jwt:sha256-" + CallerName("jwt:alice")[len("jwt.sha256-"):],
+	}
+	names := map[string]string{}
+	for _, subject := range subjects {
+		got := CallerName(subject)
+		method, _, _ := strings.Cut(subject, ":")
+		if !strings.HasPrefix(got, method+".sha256-") || got != strings.ToLower(got) || CheckName("Org1MSP", got) != nil {
+			t.Errorf("CallerName(%q) = %q", subject, got)
+		}
+		if other, ok := names[got]; ok {
+			t.Errorf("callers %q and %q mapped to the same name %s", other, subject, got)
+		}
+		names[got] = subject
+	}
+}
+
+func TestCertificateAttribute(t *testing.T) {
+	ext, err := json.Marshal(map[string]map[string]string{"attrs": {"api.caller": "jwt:alice", "hf.EnrollmentID": "u1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &stdx509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: "u1"},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: attributesOID, Value: ext}},
+	}
+	der, err := stdx509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if value, ok, err := CertificateAttribute(certPEM, "api.caller"); err != nil || !ok || value != "jwt:alice" {
+		t.Errorf("CertificateAttribute() = %q, %v, %v", value, ok, err)
+	}
+	if _, ok, err := CertificateAttribute(certPEM, "missing"); err != nil || ok {
+		t.Errorf("missing attribute = %v, %v", ok, err)
+	}
+	// 国密证书没有属性扩展
+	sm2Cert, _ := newKeyPair(t, KeyTypeSM2, "u2")
+	if _, ok, err := CertificateAttribute(sm2Cert, "api.caller"); err != nil || ok {
+		t.Errorf("certificate without attributes = %v, %v", ok, err)
+	}
+	if _, _, err := CertificateAttribute([]byte("garbage"), "api.caller"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("invalid certificate = %v", err)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	digest := sha256.Sum256([]byte("proposal"))
+	for _, keyType := range []string{KeyTypeECDSA, KeyTypeSM2} {
+		certPEM, keyPEM := newKeyPair(t, keyType, "User1")
+		if got, err := CertificateKeyType(certPEM); err != nil || got != keyType {
+			t.Errorf("CertificateKeyType() = %s, %v, want %s", got, err, keyType)
+		}
+		block, _ := pem.Decode(keyPEM)
+		var r, s *big.Int
+		var n *big.Int
+		if keyType == KeyTypeSM2 {
+			key, err := x509.ReadPrivateKeyFromPem(keyPEM, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r, s, err = sm2.Sign(rand.Reader, key, digest[:]); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			key, err := parseECDSAKey(block.Bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r, s, err = ecdsa.Sign(rand.Reader, key, digest[:]); err != nil {
+				t.Fatal(err)
+			}
+			// 高 s 签名同样有效，校验后应转换为低 s
+			n = key.Params().N
+			if s.Cmp(new(big.Int).Rsh(n, 1)) <= 0 {
+				s = new(big.Int).Sub(n, s)
+			}
+		}
+		signature, err := asn1.Marshal(ecSignature{R: r, S: s})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := VerifySignature(certPEM, digest[:], signature)
+		if err != nil {
+			t.Fatalf("%s: VerifySignature() = %v", keyType, err)
+		}
+		var sig ecSignature
+		if _, err := asn1.Unmarshal(got, &sig); err != nil {
+			t.Fatal(err)
+		}
+		if n != nil && sig.S.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+			t.Errorf("%s: signature s is not low", keyType)
+		}
+
+		other := sha256.Sum256([]byte("other"))
+		if _, err := VerifySignature(certPEM, other[:], signature); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: VerifySignature(other digest) = %v, want ErrInvalid", keyType, err)
+		}
+		if _, err := VerifySignature(certPEM, digest[:], []byte("not der")); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: VerifySignature(malformed) = %v, want ErrInvalid", keyType, err)
+		}
+	}
+}
+
+func TestGetSigningIdentity(t *testing.T) {
+	s := newStore(t)
+	certPEM, keyPEM := newKeyPair(t, KeyTypeSM2, "user1")
+	if _, err := s.Put("Org1MSP", "User1", certPEM, keyPEM); err != nil {
+		t.Fatal(err)
+	}
+	fallback := &fakeIdentity{name: "from-profile"}
+	inner := &fakeManager{fallback: fallback}
+	m := &identityManager{IdentityManager: inner, mspID: "Org1MSP", store: s, cache: make(map[string]cachedIdentity)}
+
+	// 存储中的身份优先，重复获取使用缓存
+	for i := 0; i < 2; i++ {
+		id, err := m.GetSigningIdentity("user1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f := id.(*fakeIdentity); string(f.cert) != string(certPEM) || string(f.key) != string(keyPEM) {
+			t.Error("signing identity not loaded from store")
+		}
+	}
+	if inner.created != 1 {
+		t.Errorf("created %d signing identities, want 1", inner.created)
+	}
+
+	// 存储修改后重新导入
+	certPEM, keyPEM = newKeyPair(t, KeyTypeSM2, "user1")
+	if _, err := s.Put("Org1MSP", "user1", certPEM, keyPEM); err != nil {
+		t.Fatal(err)
+	}
+	id, err := m.GetSigningIdentity("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := id.(*fakeIdentity); string(f.cert) != string(certPEM) || inner.created != 2 {
+		t.Error("signing identity not reloaded after store changed")
+	}
+
+	// 存储中没有的身份按连接配置加载
+	if id, err := m.GetSigningIdentity("user2"); err != nil || id != fallback {
+		t.Errorf("GetSigningIdentity(user2) = %v, %v", id, err)
+	}
+	// 其他 MSP 的同名身份不可见
+	other := &identityManager{IdentityManager: inner, mspID: "Org2MSP", store: s, cache: make(map[string]cachedIdentity)}
+	if id, err := other.GetSigningIdentity("user1"); err != nil || id != fallback {
+		t.Errorf("Org2MSP GetSigningIdentity(user1) = %v, %v", id, err)
+	}
+}
+
+type fakeManager struct {
+	fallback *fakeIdentity
+	created  int
+}
+
+func (m *fakeManager) GetSigningIdentity(name string) (msp.SigningIdentity, error) {
+	return m.fallback, nil
+}
+
+func (m *fakeManager) CreateSigningIdentity(opts ...msp.SigningIdentityOption) (msp.SigningIdentity, error) {
+	var o msp.IdentityOption
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+	m.created++
+	return &fakeIdentity{cert: o.Cert, key: o.PrivateKey}, nil
+}
+
+type fakeIdentity struct {
+	msp.SigningIdentity
+	name      string
+	cert, key []byte
+}
+
+func newStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := New(define.IdentityStoreConfig{
+		Enabled:   true,
+		File:      filepath.Join(t.TempDir(), "identities.json"),
+		MasterKey: sm4Key,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// newKeyPair 生成自签名证书与 PKCS#8 私钥（PEM）
+func newKeyPair(t *testing.T, keyType, cn string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	if keyType == KeyTypeSM2 {
+		key, err := sm2.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl := &x509.Certificate{
+			SerialNumber:       big.NewInt(time.Now().UnixNano()),
+			Subject:            pkix.Name{CommonName: cn},
+			NotBefore:          time.Now().Add(-time.Hour),
+			NotAfter:           time.Now().Add(time.Hour),
+			SignatureAlgorithm: x509.SM2WithSM3,
+		}
+		if certPEM, err = x509.CreateCertificateToMem(tmpl, tmpl, &key.PublicKey, key); err != nil {
+			t.Fatal(err)
+		}
+		if keyPEM, err = x509.WritePrivateKeytoPem(key, nil); err != nil {
+			t.Fatal(err)
+		}
+		return certPEM, keyPEM
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &stdx509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := stdx509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := stdx509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
@@ -0,0 +1,96 @@
+package identity
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defmsp"
+)
+
+// MSPProviderFactory 在 SDK 默认的 MSP 之上优先从身份存储加载签名身份，存储中没有的身份仍按连接配置
+// （证书与私钥文件、内嵌 PEM 或 cryptoPath）加载。私钥只以临时密钥导入 SDK 的密码套件，不写入文件
+type MSPProviderFactory struct {
+	defmsp.ProviderFactory
+	store *Store
+}
+
+// NewMSPProviderFactory 创建使用 store 的 MSP 工厂，作为 fabsdk.WithMSPPkg 的参数
+func NewMSPProviderFactory(store *Store) *MSPProviderFactory {
+	return &MSPProviderFactory{store: store}
+}
+
+// CreateIdentityManagerProvider 包装 SDK 默认的身份管理
+func (f *MSPProviderFactory) CreateIdentityManagerProvider(config fab.EndpointConfig, cryptoProvider core.CryptoSuite, userStore msp.UserStore) (msp.IdentityManagerProvider, error) {
+	provider, err := f.ProviderFactory.CreateIdentityManagerProvider(config, cryptoProvider, userStore)
+	if err != nil {
+		return nil, err
+	}
+	managers := make(map[string]*identityManager)
+	for orgName, org := range config.NetworkConfig().Organizations {
+		mgr, ok := provider.IdentityManager(orgName)
+		if !ok {
+			continue
+		}
+		managers[strings.ToLower(orgName)] = &identityManager{
+			IdentityManager: mgr,
+			mspID:           org.MSPID,
+			store:           f.store,
+			cache:           make(map[string]cachedIdentity),
+		}
+	}
+	return &managerProvider{IdentityManagerProvider: provider, managers: managers}, nil
+}
+
+type managerProvider struct {
+	msp.IdentityManagerProvider
+	managers map[string]*identityManager
+}
+
+func (p *managerProvider) IdentityManager(orgName string) (msp.IdentityManager, bool) {
+	mgr, ok := p.managers[strings.ToLower(orgName)]
+	if !ok {
+		return nil, false
+	}
+	return mgr, true
+}
+
+// identityManager 按组织 MSP ID 与用户名从存储加载签名身份，身份存储变化后重新导入
+type identityManager struct {
+	msp.IdentityManager
+	mspID string
+	store *Store
+
+	mu    sync.Mutex
+	cache map[string]cachedIdentity
+}
+
+type cachedIdentity struct {
+	revision uint64
+	identity msp.SigningIdentity
+}
+
+// GetSigningIdentity 优先返回身份存储中的同名身份
+func (m *identityManager) GetSigningIdentity(id string) (msp.SigningIdentity, error) {
+	certPEM, keyPEM, revision, err := m.store.keyPair(m.mspID, id)
+	if err == ErrNotFound {
+		return m.IdentityManager.GetSigningIdentity(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cached, ok := m.cache[id]; ok && cached.revision == revision {
+		return cached.identity, nil
+	}
+	identity, err := m.CreateSigningIdentity(msp.WithCert(certPEM), msp.WithPrivateKey(keyPEM))
+	if err != nil {
+		return nil, err
+	}
+	m.cache[id] = cachedIdentity{revision: revision, identity: identity}
+	return identity, nil
+}
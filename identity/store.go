@@ -0,0 +1,351 @@
+// Package identity 托管 Fabric 身份的证书与私钥。私钥以主密钥加密（SM4-GCM 或 AES-GCM）后保存到存储文件，
+// SDK 通过 MSPProviderFactory 按组织 MSP ID 与用户名从存储中加载身份，连接配置无需引用服务器上的证书与私钥文件。
+package identity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+var (
+	// ErrNotFound 身份不存在
+	ErrNotFound = errors.New("identity not found")
+	// ErrDisabled 未启用托管身份存储
+	ErrDisabled = errors.New("identity store is not enabled")
+	// ErrInvalid 名称、证书或私钥不合法
+	ErrInvalid = errors.New("invalid identity")
+)
+
+// Default 全局身份存储，为 nil 时不启用，SDK 只从连接配置加载身份
+var Default *Store
+
+// namePattern 身份名称与 MSP ID 的格式
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@-]{0,127}$`)
+
+// Identity 身份的公开信息，不含私钥
+type Identity struct {
+	Name        string    `json:"name"`
+	MSPID       string    `json:"mspId"`
+	Certificate string    `json:"certificate"` // 证书（PEM）
+	KeyType     string    `json:"keyType"`     // ECDSA 或 SM2
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	NotBefore   time.Time `json:"notBefore"`
+	NotAfter    time.Time `json:"notAfter"`
+	SKI         string    `json:"ski"` // 公钥摘要，与 SDK 的密钥标识一致
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// record 存储文件中的身份记录
+type record struct {
+	Identity
+	Cipher       string `json:"cipher"`
+	Nonce        []byte `json:"nonce"`
+	EncryptedKey []byte `json:"encryptedKey"`
+}
+
+// Store 身份存储，修改后立即写入存储文件
+type Store struct {
+	file   string
+	cipher string
+	key    []byte
+	now    func() time.Time
+
+	mu      sync.RWMutex
+	records map[string]*record
+	// revision 每次修改递增，MSP 据此判断缓存的签名身份是否需要重新加载
+	revision uint64
+}
+
+// New 按配置创建身份存储并读取存储文件，未启用时返回 nil
+func New(cfg define.IdentityStoreConfig) (*Store, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.File == "" {
+		return nil, errors.New("identityStore.file is required")
+	}
+	name := cfg.Cipher
+	if name == "" {
+		name = CipherSM4
+	}
+	key, err := ParseMasterKey(cfg.MasterKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := newAEAD(name, key); err != nil {
+		return nil, err
+	}
+	s := &Store{
+		file:    cfg.File,
+		cipher:  name,
+		key:     key,
+		now:     time.Now,
+		records: make(map[string]*record),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// recordKey 身份的存储键。SDK 读取连接配置时用户名被转换为小写，名称不区分大小写
+func recordKey(mspID, name string) string {
+	return mspID + "/" + strings.ToLower(name)
+}
+
+// additionalData 将密文与身份绑定，密文被挪到其他身份的记录中时无法解密
+func additionalData(mspID, name string) []byte {
+	return []byte(recordKey(mspID, name))
+}
+
+// CheckName 校验 MSP ID 与身份名称能否保存到存储，登记等操作在联系 CA 之前据此校验
+func CheckName(mspID, name string) error {
+	if !namePattern.MatchString(mspID) {
+		return fmt.Errorf("%w: mspId %q", ErrInvalid, mspID)
+	}
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: name %q", ErrInvalid, name)
+	}
+	return nil
+}
+
+// CallerName 将已认证调用方的标识 <method>:<name> 转换为身份名称 <method>.sha256-<摘要>，摘要为完整标识的 SHA-256
+// 前 32 位（十六进制小写）。SDK 按小写查找用户，直接使用调用方名称时仅大小写不同的调用方会映射到同一身份，
+// 任何调用方名称也都不会与其他调用方的摘要名称相同
+func CallerName(subject string) string {
+	method, _, _ := strings.Cut(subject, ":")
+	return method + ".sha256-" + ski([]byte(subject))[:32]
+}
+
+// Put 保存身份，同一 MSP 下同名身份被替换。私钥为 PEM 格式，须与证书公钥匹配
+func (s *Store) Put(mspID, name string, certPEM, keyPEM []byte) (*Identity, error) {
+	if s == nil {
+		return nil, ErrDisabled
+	}
+	if err := CheckName(mspID, name); err != nil {
+		return nil, err
+	}
+	id, err := parseKeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	id.Name, id.MSPID = name, mspID
+
+	aead, err := newAEAD(s.cipher, s.key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	rec := &record{
+		Identity:     *id,
+		Cipher:       s.cipher,
+		Nonce:        nonce,
+		EncryptedKey: aead.Seal(nil, nonce, keyPEM, additionalData(mspID, name)),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now().UTC()
+	rec.CreatedAt, rec.UpdatedAt = now, now
+	key := recordKey(mspID, name)
+	old, replaced := s.records[key]
+	if replaced {
+		rec.CreatedAt = old.CreatedAt
+	}
+	s.records[key] = rec
+	if err := s.saveLocked(); err != nil {
+		if replaced {
+			s.records[key] = old
+		} else {
+			delete(s.records, key)
+		}
+		return nil, err
+	}
+	s.revision++
+	result := rec.Identity
+	return &result, nil
+}
+
+// ImportEnrollment 导入 SDK 登记时生成的私钥。SDK 的软件密码套件将私钥明文写入密钥目录下的 <SKI>_sk 文件，
+// 按证书公钥的 SKI 读取该文件，保存到存储后删除明文文件
+func (s *Store) ImportEnrollment(mspID, name string, certPEM []byte, keystoreDir string) (*Identity, error) {
+	if s == nil {
+		return nil, ErrDisabled
+	}
+	id, err := certificateSKI(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	keyFile := filepath.Join(keystoreDir, id+"_sk")
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read enrolled private key: %w", err)
+	}
+	result, err := s.Put(mspID, name, certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(keyFile); err != nil {
+		return nil, fmt.Errorf("remove enrolled private key file: %w", err)
+	}
+	return result, nil
+}
+
+// Get 返回身份的公开信息
+func (s *Store) Get(mspID, name string) (*Identity, error) {
+	if s == nil {
+		return nil, ErrDisabled
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec, ok := s.records[recordKey(mspID, name)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	result := rec.Identity
+	return &result, nil
+}
+
+// List 按 MSP ID 与名称排序返回全部身份，mspID 不为空时只返回该 MSP 的身份
+func (s *Store) List(mspID string) []Identity {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]Identity, 0, len(s.records))
+	for _, rec := range s.records {
+		if mspID == "" || rec.MSPID == mspID {
+			list = append(list, rec.Identity)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].MSPID != list[j].MSPID {
+			return list[i].MSPID < list[j].MSPID
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Delete 删除身份
+func (s *Store) Delete(mspID, name string) error {
+	if s == nil {
+		return ErrDisabled
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := recordKey(mspID, name)
+	old, ok := s.records[key]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.records, key)
+	if err := s.saveLocked(); err != nil {
+		s.records[key] = old
+		return err
+	}
+	s.revision++
+	return nil
+}
+
+// keyPair 返回证书与解密后的私钥（PEM），以及当前的修改序号
+func (s *Store) keyPair(mspID, name string) (certPEM, keyPEM []byte, revision uint64, err error) {
+	if s == nil {
+		return nil, nil, 0, ErrNotFound
+	}
+	s.mu.RLock()
+	rec, ok := s.records[recordKey(mspID, name)]
+	revision = s.revision
+	s.mu.RUnlock()
+	if !ok {
+		return nil, nil, revision, ErrNotFound
+	}
+	keyPEM, err = s.decrypt(rec)
+	if err != nil {
+		return nil, nil, revision, err
+	}
+	return []byte(rec.Certificate), keyPEM, revision, nil
+}
+
+func (s *Store) decrypt(rec *record) ([]byte, error) {
+	aead, err := newAEAD(rec.Cipher, s.key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, rec.Nonce, rec.EncryptedKey, additionalData(rec.MSPID, rec.Name))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt private key of %s: wrong master key or corrupted record", recordKey(rec.MSPID, rec.Name))
+	}
+	return plain, nil
+}
+
+// load 读取存储文件并以主密钥逐条校验，主密钥错误时启动失败，避免运行中才发现身份不可用
+func (s *Store) load() error {
+	data, err := os.ReadFile(s.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var records []*record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("parse identity store %s: %w", s.file, err)
+	}
+	for _, rec := range records {
+		if _, err := s.decrypt(rec); err != nil {
+			return err
+		}
+		s.records[recordKey(rec.MSPID, rec.Name)] = rec
+	}
+	return nil
+}
+
+// saveLocked 写入存储文件，调用方持有写锁
+func (s *Store) saveLocked() error {
+	records := make([]*record, 0, len(s.records))
+	for _, rec := range s.records {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return recordKey(records[i].MSPID, records[i].Name) < recordKey(records[j].MSPID, records[j].Name)
+	})
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.file), 0o700); err != nil {
+		return err
+	}
+	// 先写临时文件再重命名，避免写入中断留下不完整的存储文件
+	tmp := s.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.file)
+}
+
+// ski 公钥摘要的十六进制形式
+func ski(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor 与 HTTP 中间件相同，请求 ID 取自 x-request-id 元数据并通过响应头返回
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx = grpcContext(ctx)
+		resp, err := handler(ctx, req)
+		logRPC(ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor 同 UnaryServerInterceptor，访问日志在流结束时输出
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := grpcContext(ss.Context())
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		logRPC(ctx, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func grpcContext(ctx context.Context) context.Context {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if !validRequestID(id) {
+		id = NewRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+	return WithFields(WithRequestID(ctx, id))
+}
+
+func logRPC(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK:
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DeadlineExceeded, codes.DataLoss:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+	slog.LogAttrs(ctx, level, "grpc request",
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+	)
+}
+
+// serverStream 替换流的上下文以携带请求 ID 与日志字段
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
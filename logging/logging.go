@@ -0,0 +1,172 @@
+// Package logging 提供 JSON 结构化日志：按请求附加请求 ID、链路标识与请求字段，并对连接配置、
+// 私密数据等敏感字段脱敏。标准库 log 与 Fabric SDK 的日志也经由同一处理器输出。
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/qctc/fabric2-api-server/define"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Redacted 替换敏感字段的值
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys 需要脱敏的字段名（小写），连接配置含证书、私钥路径与节点地址
+var sensitiveKeys = map[string]bool{
+	"sdkconfig":     true,
+	"sdk_config":    true,
+	"password":      true,
+	"privatekey":    true,
+	"private_key":   true,
+	"authorization": true,
+	"x-api-key":     true,
+	"apikey":        true,
+	"token":         true,
+}
+
+// defaultLevel 默认日志的级别，重新加载配置时通过 SetLevel 修改
+var defaultLevel slog.LevelVar
+
+// Setup 按配置安装 JSON 日志处理器作为默认日志，标准库 log 的输出同样以 JSON 格式写出
+func Setup(cfg define.LogConfig) error {
+	if err := SetLevel(cfg.Level); err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(NewHandler(os.Stdout, &defaultLevel)))
+	// slog.SetDefault 已将标准库 log 转到处理器，去掉 log 自带的时间前缀
+	log.SetFlags(0)
+	return nil
+}
+
+// SetLevel 修改默认日志的级别，无需重新安装处理器
+func SetLevel(s string) error {
+	l, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	defaultLevel.Set(l)
+	return nil
+}
+
+// ParseLevel 解析日志级别，空字符串为 info
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+// NewHandler 创建脱敏并附加请求上下文字段的 JSON 处理器
+func NewHandler(w io.Writer, level slog.Leveler) slog.Handler {
+	return &contextHandler{Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})}
+}
+
+// IsSensitive 判断字段名是否需要脱敏，transient 开头的字段为链码私密数据
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	return sensitiveKeys[key] || strings.HasPrefix(key, "transient")
+}
+
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if IsSensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// contextHandler 从上下文中取出请求 ID、链路标识与请求字段附加到每条日志
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+		if f, ok := ctx.Value(fieldsKey{}).(*fields); ok {
+			r.AddAttrs(f.attrs()...)
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID 在上下文中记录请求 ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID 返回上下文中的请求 ID
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+type fieldsKey struct{}
+
+// fields 请求处理过程中逐步确定的日志字段，如调用方、连接配置、合约与交易 ID
+type fields struct {
+	mu   sync.Mutex
+	list []slog.Attr
+}
+
+func (f *fields) add(attrs []slog.Attr) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+next:
+	for _, a := range attrs {
+		for i := range f.list {
+			if f.list[i].Key == a.Key {
+				f.list[i] = a
+				continue next
+			}
+		}
+		f.list = append(f.list, a)
+	}
+}
+
+func (f *fields) attrs() []slog.Attr {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]slog.Attr(nil), f.list...)
+}
+
+// WithFields 为一次请求创建日志字段集合，之后经 AddFields 添加的字段会出现在该请求的全部日志中
+func WithFields(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, &fields{})
+}
+
+// AddFields 向请求的日志字段集合添加字段，同名字段覆盖；上下文中没有字段集合时忽略
+func AddFields(ctx context.Context, attrs ...slog.Attr) {
+	if f, ok := ctx.Value(fieldsKey{}).(*fields); ok {
+		f.add(attrs)
+	}
+}
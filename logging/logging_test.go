@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// captureDefault 将默认日志替换为写入缓冲区的处理器
+func captureDefault(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(NewHandler(&buf, slog.LevelDebug)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid json line %q: %v", line, err)
+		}
+		records = append(records, m)
+	}
+	return records
+}
+
+func TestRedaction(t *testing.T) {
+	buf := captureDefault(t)
+	slog.Info("request",
+		"sdkConfig", "name: test\nclient:\n  credentialStore: /etc/keys",
+		"transientMap", map[string]string{"price": "100"},
+		"Authorization", "Bearer abc",
+		"chaincode", "basic",
+	)
+	rec := decodeLines(t, buf)[0]
+	for _, key := range []string{"sdkConfig", "transientMap", "Authorization"} {
+		if rec[key] != Redacted {
+			t.Errorf("%s = %v, want redacted", key, rec[key])
+		}
+	}
+	if rec["chaincode"] != "basic" {
+		t.Errorf("chaincode = %v", rec["chaincode"])
+	}
+	if strings.Contains(buf.String(), "/etc/keys") {
+		t.Error("profile contents leaked into log")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	buf := captureDefault(t)
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		AddFields(r.Context(), slog.String("chaincode", "basic"))
+		slog.InfoContext(r.Context(), "handling")
+		AddFields(r.Context(), slog.String("txId", "abc"))
+		w.WriteHeader(http.StatusConflict)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/contract/sendTransaction", nil)
+	req.Header.Set(RequestIDHeader, "client-id-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get(RequestIDHeader); got != "client-id-1" {
+		t.Errorf("response request id = %q", got)
+	}
+
+	records := decodeLines(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	handling, access := records[0], records[1]
+	if handling["request_id"] != "client-id-1" || handling["chaincode"] != "basic" {
+		t.Errorf("handler log = %v", handling)
+	}
+	if access["msg"] != "http request" || access["level"] != "WARN" || access["status"] != float64(http.StatusConflict) {
+		t.Errorf("access log = %v", access)
+	}
+	if access["txId"] != "abc" || access["chaincode"] != "basic" {
+		t.Errorf("access log fields = %v", access)
+	}
+	if _, ok := access["latency_ms"]; !ok {
+		t.Error("access log without latency")
+	}
+}
+
+func TestMiddlewareGeneratesRequestID(t *testing.T) {
+	captureDefault(t)
+	var seen string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	// 含换行等字符的请求 ID 不被接受，避免伪造日志行
+	req.Header.Set(RequestIDHeader, "bad\nid")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if len(seen) != 32 || rec.Header().Get(RequestIDHeader) != seen {
+		t.Errorf("request id = %q, header = %q", seen, rec.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestAddFieldsWithoutRequest(t *testing.T) {
+	// 请求之外的上下文没有字段集合，AddFields 不应出错
+	AddFields(context.Background(), slog.String("caller", "x"))
+}
+
+func TestSDKLogger(t *testing.T) {
+	buf := captureDefault(t)
+	logger := SDKLoggerProvider().GetLogger("fabsdk/fab")
+	logger.Warnf("peer %s unreachable", "peer0")
+	rec := decodeLines(t, buf)[0]
+	if rec["msg"] != "peer peer0 unreachable" || rec["module"] != "fabsdk/fab" || rec["level"] != "WARN" {
+		t.Errorf("sdk log = %v", rec)
+	}
+}
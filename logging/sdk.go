@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/logging/api"
+)
+
+// SDKLoggerProvider 将 Fabric SDK 的日志转到默认日志处理器，通过 fabsdk.WithLoggerPkg 使用
+func SDKLoggerProvider() api.LoggerProvider {
+	return sdkLoggerProvider{}
+}
+
+type sdkLoggerProvider struct{}
+
+func (sdkLoggerProvider) GetLogger(module string) api.Logger {
+	return &sdkLogger{module: module}
+}
+
+// sdkLogger 每次输出时取默认日志，以便 Setup 之前创建的 SDK 实例也使用最新配置
+type sdkLogger struct {
+	module string
+}
+
+func (l *sdkLogger) log(level slog.Level, msg string) {
+	slog.Default().LogAttrs(context.Background(), level, msg,
+		slog.String("logger", "fabric-sdk"), slog.String("module", l.module))
+}
+
+func (l *sdkLogger) Fatal(v ...interface{}) { l.Fatalln(v...) }
+
+func (l *sdkLogger) Fatalf(format string, v ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprintf(format, v...))
+	os.Exit(1)
+}
+
+func (l *sdkLogger) Fatalln(v ...interface{}) {
+	l.log(slog.LevelError, sprint(v))
+	os.Exit(1)
+}
+
+func (l *sdkLogger) Panic(v ...interface{}) { l.Panicln(v...) }
+
+func (l *sdkLogger) Panicf(format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	l.log(slog.LevelError, msg)
+	panic(msg)
+}
+
+func (l *sdkLogger) Panicln(v ...interface{}) {
+	msg := sprint(v)
+	l.log(slog.LevelError, msg)
+	panic(msg)
+}
+
+func (l *sdkLogger) Print(v ...interface{})   { l.log(slog.LevelInfo, sprint(v)) }
+func (l *sdkLogger) Println(v ...interface{}) { l.log(slog.LevelInfo, sprint(v)) }
+func (l *sdkLogger) Printf(format string, v ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, v...))
+}
+
+func (l *sdkLogger) Debug(v ...interface{})   { l.log(slog.LevelDebug, sprint(v)) }
+func (l *sdkLogger) Debugln(v ...interface{}) { l.log(slog.LevelDebug, sprint(v)) }
+func (l *sdkLogger) Debugf(format string, v ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprintf(format, v...))
+}
+
+func (l *sdkLogger) Info(v ...interface{})   { l.log(slog.LevelInfo, sprint(v)) }
+func (l *sdkLogger) Infoln(v ...interface{}) { l.log(slog.LevelInfo, sprint(v)) }
+func (l *sdkLogger) Infof(format string, v ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, v...))
+}
+
+func (l *sdkLogger) Warn(v ...interface{})   { l.log(slog.LevelWarn, sprint(v)) }
+func (l *sdkLogger) Warnln(v ...interface{}) { l.log(slog.LevelWarn, sprint(v)) }
+func (l *sdkLogger) Warnf(format string, v ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprintf(format, v...))
+}
+
+func (l *sdkLogger) Error(v ...interface{})   { l.log(slog.LevelError, sprint(v)) }
+func (l *sdkLogger) Errorln(v ...interface{}) { l.log(slog.LevelError, sprint(v)) }
+func (l *sdkLogger) Errorf(format string, v ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprintf(format, v...))
+}
+
+// sprint 与 fmt.Sprintln 相同以空格分隔参数，但不带结尾换行
+func sprint(v []interface{}) string {
+	s := fmt.Sprintln(v...)
+	return s[:len(s)-1]
+}
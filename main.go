@@ -1,17 +1,36 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"github.com/apache/rocketmq-clients/golang/v5"
-	"github.com/apache/rocketmq-clients/golang/v5/credentials"
+	"github.com/qctc/fabric2-api-server/auth"
+	"github.com/qctc/fabric2-api-server/certmon"
+	"github.com/qctc/fabric2-api-server/config"
+	"github.com/qctc/fabric2-api-server/controller"
 	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/grpcserver"
+	"github.com/qctc/fabric2-api-server/health"
+	"github.com/qctc/fabric2-api-server/hsm"
+	"github.com/qctc/fabric2-api-server/idempotency"
+	"github.com/qctc/fabric2-api-server/identity"
+	"github.com/qctc/fabric2-api-server/logging"
+	"github.com/qctc/fabric2-api-server/ratelimit"
 	"github.com/qctc/fabric2-api-server/router"
+	"github.com/qctc/fabric2-api-server/servertls"
 	"github.com/qctc/fabric2-api-server/service"
-	"gopkg.in/yaml.v3"
-	"io/ioutil"
+	"github.com/qctc/fabric2-api-server/subscription"
+	"github.com/qctc/fabric2-api-server/tracing"
+	"google.golang.org/grpc"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 )
 
 //	func main() {
@@ -20,79 +39,234 @@ import (
 //		log.Fatal(http.ListenAndServe(":8080", router))
 //	}
 
-func init() {
-	// 读取配置文件
-	data, err := ioutil.ReadFile("./config.yaml")
+// defaultShutdownTimeout 未配置时优雅关闭的最长等待时间
+const defaultShutdownTimeout = 30 * time.Second
+
+func main() {
+	os.Exit(run())
+}
+
+// run 启动 HTTP 服务并在收到退出信号后按顺序释放资源，返回进程退出码
+func run() int {
+	configFlag := flag.String("config", "", "配置文件路径，未指定时读取环境变量 "+config.PathEnv+"，默认 "+config.DefaultPath)
+	flag.Parse()
+
+	// 读取配置文件，环境变量覆盖后校验
+	configPath := config.Path(*configFlag)
+	cfg, err := config.Load(configPath)
 	if err != nil {
-		log.Fatalf("无法读取配置文件: %v", err)
+		log.Printf("加载配置失败: %v", err)
+		return 1
+	}
+	define.GlobalConfig = cfg
+	// JSON 结构化日志，之后标准库 log 与 SDK 的日志均以 JSON 输出
+	if err := logging.Setup(cfg.Log); err != nil {
+		log.Printf("无法解析日志配置: %v", err)
+		return 1
+	}
+	// 订阅事件投递的 RocketMQ Producer，未配置 mq.host 时不投递
+	if err := subscription.DefaultSink.Configure(cfg.MQ); err != nil {
+		log.Printf("初始化 RocketMQ Producer 失败: %v", err)
+		return 1
 	}
 
-	// 解析配置文件
-	var config *define.Config
-	err = yaml.Unmarshal(data, &config)
+	// 认证与授权，REST 与 gRPC 接口共用；未启用时 guard 为 nil
+	guard, err := auth.New(define.GlobalConfig.Auth)
 	if err != nil {
-		log.Fatalf("无法解析配置文件: %v", err)
-	}
-
-	// 设置全局配置
-	define.GlobalConfig = config
-	mqConfig := define.GlobalConfig.MQ
-	//配置mq
-	define.GlobalProducer, err = golang.NewProducer(&golang.Config{
-		Endpoint: fmt.Sprintf("%s:%d", mqConfig.Host, mqConfig.Port),
-		Credentials: &credentials.SessionCredentials{
-			AccessKey:    "",
-			AccessSecret: "",
-		},
-	},
-		golang.WithTopics(mqConfig.Topic),
-	)
+		log.Printf("加载认证配置失败: %v", err)
+		return 1
+	}
+	if guard == nil {
+		log.Println("警告: 未启用接口认证，请勿将服务暴露在可信主机之外")
+	}
+	// 限流与并发配额，订阅补齐历史区块时也使用
+	ratelimit.Default, err = ratelimit.New(define.GlobalConfig.RateLimit)
 	if err != nil {
-		log.Fatalf("Failed to initialize RocketMQ producer: %v", err)
+		log.Printf("加载限流配置失败: %v", err)
+		return 1
 	}
-	err = define.GlobalProducer.Start()
+	// 交易提交幂等记录
+	idempotency.Default, err = idempotency.New(define.GlobalConfig.Idempotency)
 	if err != nil {
-		log.Fatalf("Failed to start RocketMQ producer: %v", err)
+		log.Printf("加载幂等记录失败: %v", err)
+		return 1
+	}
+	// 托管身份存储，须在初始化 SDK 之前加载
+	identity.Default, err = identity.New(define.GlobalConfig.IdentityStore)
+	if err != nil {
+		log.Printf("加载身份存储失败: %v", err)
+		return 1
+	}
+	// PKCS#11 硬件安全模块，启动时登录令牌
+	hsm.Default, err = hsm.New(define.GlobalConfig.HSM)
+	if err != nil {
+		log.Printf("初始化 HSM 失败: %v", err)
+		return 1
+	}
+	// 调用方身份，依赖身份存储与 HSM 保存自动登记的私钥
+	service.CallerIdentities, err = service.NewCallerIdentityResolver(define.GlobalConfig.UserIdentities)
+	if err != nil {
+		log.Printf("初始化调用方身份失败: %v", err)
+		return 1
+	}
+
+	// 链路追踪，未启用时只安装传播器
+	shutdownTracing, err := tracing.Setup(context.Background(), define.GlobalConfig.Tracing)
+	if err != nil {
+		log.Printf("初始化链路追踪失败: %v", err)
+		return 1
+	}
+
+	// 监听器的传输加密，未启用时为 nil
+	tlsConfig, err := servertls.New(define.GlobalConfig.Server.TLS)
+	if err != nil {
+		log.Printf("加载 TLS 配置失败: %v", err)
+		return 1
 	}
-}
 
-func main() {
 	port := define.GlobalConfig.Server.Port
-	useRouter := router.SetUpRouter()
-	defer func() {
-		log.Println("开始执行清理任务...")
-
-		// 关闭 RocketMQ Producer
-		if define.GlobalProducer != nil {
-			if err := define.GlobalProducer.GracefulStop(); err != nil {
-				log.Printf("RocketMQ Producer 关闭失败: %v", err)
-			} else {
-				log.Println("RocketMQ Producer 已关闭")
-			}
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		log.Printf("HTTP 端口监听失败: %v", err)
+		return 1
+	}
+	server := &http.Server{
+		Handler:     servertls.Handler(router.SetUpRouter(guard)),
+		ConnContext: servertls.ConnContext,
+	}
+	// 关闭时结束长连接事件流，否则 Shutdown 会一直等待这些连接
+	server.RegisterOnShutdown(controller.StopStreams)
+
+	var grpcServer *grpcserver.Server
+	var grpcListener net.Listener
+	if grpcPort := define.GlobalConfig.Server.GRPCPort; grpcPort > 0 {
+		grpcListener, err = net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
+		if err != nil {
+			log.Printf("gRPC 端口监听失败: %v", err)
+			return 1
+		}
+		var opts []grpc.ServerOption
+		if opt := tlsConfig.ServerOption(); opt != nil {
+			opts = append(opts, opt)
 		}
+		grpcServer = grpcserver.New(guard, opts...)
+	}
 
-		// 取消所有事件订阅
-		unsubscribeAll()
+	// 恢复上次退出时保存的订阅，连接配置尚未加载的订阅在其加入连接池后恢复
+	service.OnPoolAdd = subscription.DefaultManager.RestorePending
+	if err := subscription.DefaultManager.Restore(define.GlobalConfig.Subscription.CheckpointFile, subscription.DefaultSink); err != nil {
+		log.Printf("恢复订阅失败: %v", err)
+	}
+
+	// 收到 SIGHUP 或配置文件变化时重新加载可热更新的配置项
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go config.Watch(watchCtx, configPath, reloader(define.GlobalConfig))
+	// 证书到期监控，连接配置在请求中首次使用时才加入连接池，之后的检查才会包含
+	go certmon.New(define.GlobalConfig.Certificates).Run(watchCtx)
+
+	serverErr := make(chan error, 2)
+	go func() {
+		log.Printf("服务器正在端口 %d 上运行（传输加密: %s）...", port, tlsConfig.Mode())
+		serverErr <- fmt.Errorf("http: %w", server.Serve(tlsConfig.Listener(listener)))
+	}()
+	if grpcServer != nil {
+		go func() {
+			log.Printf("gRPC 服务正在端口 %d 上运行...", define.GlobalConfig.Server.GRPCPort)
+			serverErr <- fmt.Errorf("grpc: %w", grpcServer.Serve(grpcListener))
+		}()
+	}
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		log.Printf("服务异常退出: %v", err)
+		exitCode = 1
+	case sig := <-signals:
+		log.Printf("收到信号 %s，开始优雅关闭...", sig)
+	}
 
-		log.Println("清理任务完成，服务即将退出。")
+	// 关闭过程中再次收到信号时立即退出
+	go func() {
+		sig := <-signals
+		log.Printf("再次收到信号 %s，强制退出", sig)
+		os.Exit(1)
 	}()
 
-	log.Printf("服务器正在端口 %d 上运行...", port)
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", port), useRouter))
+	timeout := define.GlobalConfig.Server.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := shutdown(ctx, server, grpcServer, shutdownTracing); err != nil {
+		log.Printf("优雅关闭未完全完成: %v", err)
+		exitCode = 1
+	}
+	log.Println("清理任务完成，服务即将退出。")
+	return exitCode
 }
 
-func unsubscribeAll() {
-	define.SubscriptionMutex.RLock()
-	defer define.SubscriptionMutex.RUnlock()
+// shutdown 停止接收请求并等待处理中的请求完成，随后停止订阅、保存断点、关闭 MQ Producer 与 SDK，最后导出剩余的 span
+func shutdown(ctx context.Context, server *http.Server, grpcServer *grpcserver.Server, shutdownTracing func(context.Context) error) error {
+	log.Println("开始执行清理任务...")
+	health.SetShuttingDown()
+	var errs []error
+
+	if err := server.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("http server shutdown: %w", err))
+	}
+	if grpcServer != nil {
+		if err := grpcServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("grpc server shutdown: %w", err))
+		}
+	}
+
+	// 服务已停止接收请求，保存幂等记录
+	if err := idempotency.Default.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("save idempotency records: %w", err))
+	}
+
+	// 停止订阅，等待已取出的事件发送完成并保存断点
+	if err := subscription.DefaultManager.Shutdown(ctx, define.GlobalConfig.Subscription.CheckpointFile); err != nil {
+		errs = append(errs, err)
+	}
+
+	// 关闭 RocketMQ Producer
+	if err := subscription.DefaultSink.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("rocketmq producer stop: %w", err))
+	} else {
+		log.Println("RocketMQ Producer 已关闭")
+	}
+
+	service.CloseAll()
+
+	if err := shutdownTracing(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("tracing shutdown: %w", err))
+	}
+	return errors.Join(errs...)
+}
 
-	for key, regID := range define.EventSubscriptions {
-		parts := strings.Split(key, "-")
-		if len(parts) < 3 {
-			continue
+// reloader 返回应用重新加载的配置的函数：日志级别、限流配额与消息队列立即生效，其余配置段的变化需重启服务才能生效。
+// 需要重启的配置段与上一次加载的配置 applied 比较，每次修改只提示一次；define.GlobalConfig 保持为启动时的配置
+func reloader(applied *define.Config) func(*define.Config) {
+	return func(cfg *define.Config) {
+		if err := logging.SetLevel(cfg.Log.Level); err != nil {
+			log.Printf("更新日志级别失败: %v", err)
+		}
+		if err := ratelimit.Default.Reload(cfg.RateLimit); err != nil {
+			log.Printf("更新限流配置失败: %v", err)
+		}
+		if err := subscription.DefaultSink.Configure(cfg.MQ); err != nil {
+			log.Printf("更新消息队列配置失败，继续使用原配置: %v", err)
+		}
+		if sections := config.RestartRequired(applied, cfg); len(sections) > 0 {
+			log.Printf("警告: 以下配置段的变化需要重启服务才能生效: %s", strings.Join(sections, ", "))
 		}
-		sdkID := parts[0]
-		sdk, _ := service.Fabric2ServicePool[sdkID]
-		_ = sdk.UnsubscribeEvent(regID)
-		log.Printf("已取消订阅: %s", key)
+		applied = cfg
 	}
 }
@@ -0,0 +1,143 @@
+// Package metrics 定义服务导出的 Prometheus 指标，通过 /metrics 接口提供。
+// 指标注册在独立的 Registry 中，不包含依赖库注册到默认 Registry 的指标。
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace 指标名前缀
+const namespace = "fabric2"
+
+// Result 标签值
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Registry 服务指标的注册表
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests 按路由、请求方法与状态码统计的请求数
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	// HTTPDuration 按路由与请求方法统计的请求耗时，事件流为整个连接的持续时间
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// FabricDuration 按阶段（invoke 的 endorse、commit 与查询的 query）、合约与方法统计的 Fabric 调用耗时
+	FabricDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "fabric_operation_duration_seconds",
+		Help:      "Fabric endorsement, commit and query latency by chaincode and function.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2, 5, 10, 30, 60},
+	}, []string{"phase", "chaincode", "function", "result"})
+
+	// FabricFailures 按阶段、合约与方法统计的 Fabric 调用失败次数
+	FabricFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fabric_operation_failures_total",
+		Help:      "Failed Fabric endorsements, commits and queries by chaincode and function.",
+	}, []string{"phase", "chaincode", "function"})
+
+	// SDKPoolSize 连接池中已初始化的 SDK 实例数
+	SDKPoolSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sdk_pool_size",
+		Help:      "Initialized Fabric SDK instances in the pool.",
+	})
+
+	// MQSendDuration 按主题与结果统计的消息发送耗时
+	MQSendDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mq_send_duration_seconds",
+		Help:      "RocketMQ send latency by topic and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"topic", "result"})
+
+	// MQSendErrors 按主题统计的消息发送失败次数
+	MQSendErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mq_send_errors_total",
+		Help:      "Failed RocketMQ sends by topic.",
+	}, []string{"topic"})
+
+	// CertificateExpiry 连接配置引用的证书与身份存储中证书的到期时间（Unix 秒），告警规则以 time() 计算剩余时间
+	CertificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "certificate_expiry_timestamp_seconds",
+		Help:      "Expiry time of certificates referenced by connection profiles and the identity store.",
+	}, []string{"profile", "kind", "owner", "subject"})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPDuration,
+		FabricDuration, FabricFailures,
+		SDKPoolSize,
+		MQSendDuration, MQSendErrors,
+		CertificateExpiry,
+	)
+}
+
+// Handler 以 Prometheus 文本格式导出 Registry 中的指标
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Result 按错误返回 result 标签值
+func Result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// ObserveFabric 记录一次 Fabric 调用阶段的耗时，失败时同时计入失败次数
+func ObserveFabric(phase, chaincode, function string, start time.Time, err error) {
+	FabricDuration.WithLabelValues(phase, chaincode, function, Result(err)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		FabricFailures.WithLabelValues(phase, chaincode, function).Inc()
+	}
+}
+
+// ObserveMQSend 记录一次消息发送的耗时与结果
+func ObserveMQSend(topic string, start time.Time, err error) {
+	MQSendDuration.WithLabelValues(topic, Result(err)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		MQSendErrors.WithLabelValues(topic).Inc()
+	}
+}
+
+// Middleware 按路由模板统计请求数与耗时，需注册在 mux.Router 上以便取得匹配的路由
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if tmpl, err := current.GetPathTemplate(); err == nil {
+				route = tmpl
+			}
+		}
+		labels := prometheus.Labels{"route": route}
+		// promhttp 记录状态码并保留 Flusher 与 Hijacker，SSE 与 WebSocket 不受影响
+		promhttp.InstrumentHandlerDuration(HTTPDuration.MustCurryWith(labels),
+			promhttp.InstrumentHandlerCounter(HTTPRequests.MustCurryWith(labels), next),
+		).ServeHTTP(w, r)
+	})
+}
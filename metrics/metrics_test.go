@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddlewareUsesRouteTemplate(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(Middleware)
+	router.HandleFunc("/api/v1/subscriptions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+
+	for _, id := range []string{"a", "b"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/"+id, nil))
+	}
+	// 路径参数不进入标签，同一路由的请求计入同一序列
+	got := testutil.ToFloat64(HTTPRequests.WithLabelValues("/api/v1/subscriptions/{id}", "get", "404"))
+	if got != 2 {
+		t.Errorf("requests = %v, want 2", got)
+	}
+}
+
+func TestObserveMQSend(t *testing.T) {
+	ObserveMQSend("events", time.Now(), nil)
+	ObserveMQSend("events", time.Now(), errors.New("timeout"))
+	if got := testutil.ToFloat64(MQSendErrors.WithLabelValues("events")); got != 1 {
+		t.Errorf("send errors = %v, want 1", got)
+	}
+}
+
+func TestObserveFabric(t *testing.T) {
+	ObserveFabric("endorse", "basic", "Transfer", time.Now(), errors.New("endorsement mismatch"))
+	if got := testutil.ToFloat64(FabricFailures.WithLabelValues("endorse", "basic", "Transfer")); got != 1 {
+		t.Errorf("failures = %v, want 1", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	SDKPoolSize.Set(3)
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "fabric2_sdk_pool_size 3") {
+		t.Errorf("metrics output missing pool size:\n%s", rec.Body.String())
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// requestFields 请求中需要记录到日志的字段
+type requestFields struct {
+	SdkConfig     string `json:"sdkConfig"`
+	SdkId         string `json:"sdkId"`
+	ChaincodeName string `json:"chaincodeName"`
+	Method        string `json:"method"`
+}
+
+// LogRequestFields 将请求体与查询参数中的连接配置、合约与方法加入请求日志字段，需在 logging.Middleware 之后执行
+func LogRequestFields(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var fields requestFields
+		if r.Method == http.MethodPost {
+			_ = utils.PeekJSON(r, &fields)
+		}
+		q := r.URL.Query()
+		if fields.SdkId == "" {
+			fields.SdkId = q.Get("sdkId")
+		}
+		if fields.ChaincodeName == "" {
+			fields.ChaincodeName = q.Get("chaincodeName")
+		}
+		utils.LogRequestFields(r.Context(), fields.SdkConfig, fields.SdkId, fields.ChaincodeName, fields.Method)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// RequestTimeoutHeader 调用方指定单个请求超时的请求头，值为时长（如 30s、1500ms）或秒数
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// Timeout 为请求设置截止时间，调用方未通过 X-Request-Timeout 指定时使用 def，指定的值不超过 max。
+// 截止时间随请求的 ctx 传入服务层与 SDK，超时或客户端断开时中止排队与进行中的 Fabric 调用；
+// 事件流等长连接不应使用
+func Timeout(def, max time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested, err := ParseTimeout(r.Header.Get(RequestTimeoutHeader))
+			if err != nil {
+				utils.BadRequest(w, err.Error())
+				return
+			}
+			timeout := EffectiveTimeout(requested, def, max)
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ParseTimeout 解析 X-Request-Timeout，为空时返回 0
+func ParseTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, serr := strconv.ParseFloat(value, 64)
+		if serr != nil {
+			return 0, fmt.Errorf("invalid %s %q", RequestTimeoutHeader, value)
+		}
+		d = time.Duration(seconds * float64(time.Second))
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be positive", RequestTimeoutHeader, value)
+	}
+	return d, nil
+}
+
+// EffectiveTimeout 返回请求实际使用的超时：requested 为 0 表示调用方未指定，使用 def；结果不超过 max，
+// def 与 max 为 0 表示不限制
+func EffectiveTimeout(requested, def, max time.Duration) time.Duration {
+	timeout := def
+	if requested > 0 {
+		timeout = requested
+	}
+	if max > 0 && (timeout <= 0 || timeout > max) {
+		timeout = max
+	}
+	return timeout
+}
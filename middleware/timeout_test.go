@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		err   bool
+	}{
+		{"", 0, false},
+		{"30s", 30 * time.Second, false},
+		{"1500ms", 1500 * time.Millisecond, false},
+		{"2.5", 2500 * time.Millisecond, false},
+		{"0", 0, true},
+		{"-1s", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeout(tt.value)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseTimeout(%q) = %v, %v", tt.value, got, err)
+		}
+	}
+}
+
+func TestEffectiveTimeout(t *testing.T) {
+	tests := []struct {
+		requested, def, max, want time.Duration
+	}{
+		{0, time.Minute, 0, time.Minute},
+		{5 * time.Second, time.Minute, 0, 5 * time.Second},
+		{10 * time.Minute, time.Minute, 5 * time.Minute, 5 * time.Minute},
+		{0, 0, 5 * time.Minute, 5 * time.Minute},
+		{0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := EffectiveTimeout(tt.requested, tt.def, tt.max); got != tt.want {
+			t.Errorf("EffectiveTimeout(%v, %v, %v) = %v, want %v", tt.requested, tt.def, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	var remaining time.Duration
+	handler := Timeout(time.Minute, 5*time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		if !ok {
+			t.Fatal("request without deadline")
+		}
+		remaining = time.Until(deadline)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/contract/call", nil)
+	req.Header.Set(RequestTimeoutHeader, "2s")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if remaining <= 0 || remaining > 2*time.Second {
+		t.Errorf("remaining = %v, want at most 2s", remaining)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/contract/call", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if remaining <= 2*time.Second || remaining > time.Minute {
+		t.Errorf("remaining = %v, want default 1m", remaining)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/contract/call", nil)
+	req.Header.Set(RequestTimeoutHeader, "soon")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gorilla/mux"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// ValidateRequests 按 OpenAPI 文档校验请求参数与请求体，未在文档中定义的路由不做校验
+func ValidateRequests(spec []byte) (mux.MiddlewareFunc, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi spec: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	options := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			})
+			if err != nil {
+				utils.ValidationError(w, "Invalid request", fieldErrors(err))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// fieldErrors 将校验错误展开为字段级别的错误信息
+func fieldErrors(err error) []utils.FieldError {
+	var fields []utils.FieldError
+	var walk func(prefix string, err error)
+	walk = func(prefix string, err error) {
+		switch e := err.(type) {
+		case openapi3.MultiError:
+			for _, inner := range e {
+				walk(prefix, inner)
+			}
+		case *openapi3filter.RequestError:
+			if e.Parameter != nil {
+				prefix = e.Parameter.Name
+			}
+			if e.Err == nil {
+				fields = append(fields, utils.FieldError{Field: prefix, Reason: e.Reason})
+				return
+			}
+			walk(prefix, e.Err)
+		case *openapi3.SchemaError:
+			fields = append(fields, utils.FieldError{Field: joinField(prefix, e), Reason: e.Reason})
+		case *openapi3filter.ParseError:
+			fields = append(fields, utils.FieldError{Field: prefix, Reason: e.Error()})
+		default:
+			if next := errors.Unwrap(err); next != nil {
+				walk(prefix, next)
+				return
+			}
+			fields = append(fields, utils.FieldError{Field: prefix, Reason: err.Error()})
+		}
+	}
+	walk("", err)
+	return fields
+}
+
+// joinField 拼接字段路径，对象中不支持的字段直接指向该字段
+func joinField(prefix string, err *openapi3.SchemaError) string {
+	path := err.JSONPointer()
+	if err.SchemaField == "additionalProperties" || err.SchemaField == "properties" {
+		if name, ok := unsupportedProperty(err.Reason); ok {
+			path = append(path, name)
+		}
+	}
+	if prefix != "" {
+		path = append([]string{prefix}, path...)
+	}
+	return strings.Join(path, "/")
+}
+
+// unsupportedProperty 从 `property "x" is unsupported` 中取出字段名
+func unsupportedProperty(reason string) (string, bool) {
+	var name string
+	if _, err := fmt.Sscanf(reason, "property %q is unsupported", &name); err != nil {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/qctc/fabric2-api-server/api"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+func newTestRouter(t *testing.T) *mux.Router {
+	validate, err := ValidateRequests(api.Spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok := func(w http.ResponseWriter, r *http.Request) { utils.Success(w, nil) }
+	router := mux.NewRouter()
+	router.Use(validate)
+	router.HandleFunc("/api/v1/contract/sendTransaction", ok).Methods("POST")
+	router.HandleFunc("/api/v1/contract/endorse", ok).Methods("POST")
+	router.HandleFunc("/api/v1/block/info", ok).Methods("POST")
+	router.HandleFunc("/api/v1/events/stream", ok).Methods("GET", "POST")
+	router.HandleFunc("/internal/undocumented", ok).Methods("GET")
+	return router
+}
+
+func TestValidateRequests(t *testing.T) {
+	router := newTestRouter(t)
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+		fields []string
+	}{
+		{"valid invoke", "POST", "/api/v1/contract/sendTransaction", `{"sdkConfig":"cfg","isGM":true,"chaincodeName":"cc","method":"set","args":["a"]}`, http.StatusOK, nil},
+		{"missing required", "POST", "/api/v1/contract/sendTransaction", `{"sdkConfig":"cfg","chaincodeName":"cc"}`, http.StatusBadRequest, []string{"method"}},
+		{"unknown field", "POST", "/api/v1/contract/sendTransaction", `{"sdkConfig":"cfg","chaincodeName":"cc","method":"set","isGm":true}`, http.StatusBadRequest, []string{"isGm"}},
+		{"wrong case", "POST", "/api/v1/contract/sendTransaction", `{"sdkConfig":"cfg","ChaincodeName":"cc","method":"set"}`, http.StatusBadRequest, []string{"chaincodeName", "ChaincodeName"}},
+		{"wrong type", "POST", "/api/v1/contract/sendTransaction", `{"sdkConfig":"cfg","chaincodeName":"cc","method":"set","args":[1]}`, http.StatusBadRequest, []string{"args/0"}},
+		{"idempotency key", "POST", "/api/v1/contract/sendTransaction", `{"sdkConfig":"cfg","chaincodeName":"cc","method":"set","idempotencyKey":"9f1c-2b"}`, http.StatusOK, nil},
+		{"idempotency key format", "POST", "/api/v1/contract/sendTransaction", `{"sdkConfig":"cfg","chaincodeName":"cc","method":"set","idempotencyKey":"a b"}`, http.StatusBadRequest, []string{"idempotencyKey"}},
+		{"signed proposal", "POST", "/api/v1/contract/endorse", `{"sdkConfig":"cfg","proposal":"CgEx","signature":"MEUCIQ=="}`, http.StatusOK, nil},
+		{"signature encoding", "POST", "/api/v1/contract/endorse", `{"sdkConfig":"cfg","proposal":"CgEx","signature":"not base64!"}`, http.StatusBadRequest, []string{"signature"}},
+		{"block number format", "POST", "/api/v1/block/info", `{"sdkConfig":"cfg","blockNumber":"abc"}`, http.StatusBadRequest, []string{"blockNumber"}},
+		{"latest block", "POST", "/api/v1/block/info", `{"sdkConfig":"cfg","blockNumber":"latest"}`, http.StatusOK, nil},
+		{"query parameter", "GET", "/api/v1/events/stream?sdkId=abc&fromBlock=-1", "", http.StatusBadRequest, []string{"fromBlock"}},
+		{"event type", "GET", "/api/v1/events/stream?sdkId=abc&type=unknown", "", http.StatusBadRequest, []string{"type"}},
+		{"undocumented route", "GET", "/internal/undocumented", "", http.StatusOK, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("got HTTP %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.fields == nil {
+				return
+			}
+			var resp utils.Response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Error == nil || resp.Error.Code != utils.CodeInvalidRequest {
+				t.Fatalf("unexpected error %+v", resp.Error)
+			}
+			got := make(map[string]bool)
+			for _, f := range resp.Error.Fields {
+				got[f.Field] = true
+			}
+			for _, field := range tt.fields {
+				if !got[field] {
+					t.Fatalf("missing field error for %q in %+v", field, resp.Error.Fields)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package vo
+
+// CAInfoVO CA 信息
+type CAInfoVO struct {
+	CAName  string `json:"caName"`
+	CAChain string `json:"caChain"` // CA 证书链（PEM），第一个为根证书
+	Version string `json:"version"`
+}
+
+// EnrollmentVO 登记结果，私钥不返回
+type EnrollmentVO struct {
+	Name        string `json:"name"`
+	MSPID       string `json:"mspId"`
+	Certificate string `json:"certificate"`
+	KeyStorage  string `json:"keyStorage"` // 私钥保存位置：identityStore 或 hsm
+}
+
+// CAAttributeVO 身份属性，ecert 为 true 时默认写入登记证书，供链码按属性控制访问
+type CAAttributeVO struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	ECert bool   `json:"ecert"`
+}
+
+// CAIdentityVO CA 中注册的身份
+type CAIdentityVO struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	Affiliation    string          `json:"affiliation"`
+	Attributes     []CAAttributeVO `json:"attributes"`
+	MaxEnrollments int             `json:"maxEnrollments"`
+	CAName         string          `json:"caName,omitempty"`
+}
+
+// CAAffiliationVO 机构及其下级机构与身份
+type CAAffiliationVO struct {
+	Name         string            `json:"name"`
+	Affiliations []CAAffiliationVO `json:"affiliations"`
+	Identities   []CAIdentityVO    `json:"identities"`
+	CAName       string            `json:"caName,omitempty"`
+}
+
+// RevokedCertVO 被吊销的证书
+type RevokedCertVO struct {
+	Serial string `json:"serial"`
+	AKI    string `json:"aki"`
+}
+
+// RevocationVO 吊销结果，请求生成 CRL 时 crl 为 PEM 格式的证书吊销列表
+type RevocationVO struct {
+	RevokedCerts []RevokedCertVO `json:"revokedCerts"`
+	CRL          string          `json:"crl,omitempty"`
+}
@@ -0,0 +1,27 @@
+package vo
+
+// OfflineProposalVO 待调用方签名的提案，二进制字段以 base64 编码
+type OfflineProposalVO struct {
+	TxHash        string `json:"txHash"`
+	Proposal      []byte `json:"proposal"`      // protobuf 编码的 peer.Proposal，背书时原样带回
+	Digest        []byte `json:"digest"`        // 待签名的摘要，即 proposal 的哈希
+	HashAlgorithm string `json:"hashAlgorithm"` // 摘要的哈希算法：SHA256 或 SM3
+	KeyType       string `json:"keyType"`       // 证书公钥类型：ECDSA 或 SM2
+}
+
+// OfflineTransactionVO 背书完成、待调用方签名的交易，二进制字段以 base64 编码
+type OfflineTransactionVO struct {
+	TxHash        string   `json:"txHash"`
+	Payload       []byte   `json:"payload"` // protobuf 编码的 common.Payload，提交时原样带回
+	Digest        []byte   `json:"digest"`  // 待签名的摘要，即 payload 的哈希
+	HashAlgorithm string   `json:"hashAlgorithm"`
+	Result        string   `json:"result"` // 合约返回值
+	Endorsers     []string `json:"endorsers"`
+}
+
+// OfflineCommitVO 交易提交结果
+type OfflineCommitVO struct {
+	TxHash         string `json:"txHash"`
+	Height         uint64 `json:"height"` // 交易所在区块
+	ValidationCode string `json:"validationCode"`
+}
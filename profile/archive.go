@@ -0,0 +1,305 @@
+package profile
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/qctc/fabric2-api-server/identity"
+)
+
+// 归档的大小限制，organizations 目录只包含证书与私钥，通常远小于限制
+const (
+	maxArchiveFiles = 10000
+	maxFileSize     = 1 << 20
+	maxArchiveSize  = 64 << 20
+)
+
+// 未指定地址时节点使用的默认端口
+const (
+	defaultPeerPort    = "7051"
+	defaultOrdererPort = "7050"
+)
+
+// ArchiveOptions 归档中没有的连接信息
+type ArchiveOptions struct {
+	Channel string
+	// Organization 客户端所属组织，可以是组织名称或域名，为空时使用第一个 Peer 组织
+	Organization string
+	// MSPIDs 组织域名到 MSP ID 的映射。未指定时 Peer 组织使用域名第一段首字母大写加 MSP（org1.example.com 为 Org1MSP），
+	// 只有一个 Orderer 组织时其 MSP ID 为 OrdererMSP，与 Fabric 示例网络一致
+	MSPIDs map[string]string
+	// Endpoints 节点名称到地址的映射，未指定时使用节点名称与默认端口（Peer 7051、Orderer 7050）
+	Endpoints map[string]string
+	// ServerNames 节点名称到 TLS 校验主机名的映射，通过 IP 地址或代理访问节点时需要指定
+	ServerNames map[string]string
+}
+
+// cryptoOrg 归档中一个组织目录下读取到的文件
+type cryptoOrg struct {
+	domain  string
+	orderer bool
+	nodes   map[string][]byte // 节点名称到 tls/ca.crt
+	users   map[string]*cryptoUser
+	tlsCA   []byte // tlsca 目录或 msp/tlscacerts 中的 TLS CA 证书
+}
+
+type cryptoUser struct {
+	cert []byte
+	keys [][]byte
+}
+
+// FromArchive 读取 cryptogen 或 Fabric CA 生成的 organizations 目录归档（tar 或 tar.gz），生成连接配置的描述。
+// 归档中可以包含任意上层目录，按 peerOrganizations 与 ordererOrganizations 目录识别组织；
+// 节点的 TLS CA 证书读取 tls/ca.crt，用户证书与私钥读取 users/<用户>@<域名>/msp 下的 signcerts 与 keystore
+func FromArchive(r io.Reader, opts ArchiveOptions) (*Spec, error) {
+	orgs, err := readArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(orgs) == 0 {
+		return nil, fmt.Errorf("%w: archive contains no peerOrganizations or ordererOrganizations directory", ErrInvalid)
+	}
+
+	var ordererOrgs int
+	for _, org := range orgs {
+		if org.orderer {
+			ordererOrgs++
+		}
+	}
+	spec := &Spec{Channel: opts.Channel}
+	names := map[string]bool{}
+	var problems []string
+	for _, org := range orgs {
+		o := Organization{Name: orgName(org.domain, names), MSPID: opts.MSPIDs[org.domain]}
+		if o.MSPID == "" {
+			o.MSPID = defaultMSPID(org, ordererOrgs)
+		}
+		if strings.EqualFold(opts.Organization, org.domain) && !org.orderer {
+			spec.Organization = o.Name
+		}
+		for _, name := range sortedKeys(org.nodes) {
+			n := Node{Name: name, URL: opts.Endpoints[name], TLSCACert: string(org.nodes[name]), ServerName: opts.ServerNames[name]}
+			if n.TLSCACert == "" {
+				n.TLSCACert = string(org.tlsCA)
+			}
+			if n.URL == "" {
+				port := defaultPeerPort
+				if org.orderer {
+					port = defaultOrdererPort
+				}
+				n.URL = name + ":" + port
+			}
+			if org.orderer {
+				o.Orderers = append(o.Orderers, n)
+			} else {
+				o.Peers = append(o.Peers, n)
+			}
+		}
+		for _, dir := range userDirs(org.users) {
+			u, err := newUser(dir, org.users[dir])
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("organization %s user %s: %v", org.domain, dir, err))
+				continue
+			}
+			if u != nil {
+				o.Users = append(o.Users, *u)
+			}
+		}
+		spec.Organizations = append(spec.Organizations, o)
+	}
+	if spec.Organization == "" && opts.Organization != "" {
+		// 按组织名称指定
+		spec.Organization = opts.Organization
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, strings.Join(problems, "; "))
+	}
+	return spec, nil
+}
+
+// readArchive 读取归档中各组织的证书与私钥，组织按 Peer 组织在前、域名字典序排列
+func readArchive(r io.Reader) ([]*cryptoOrg, error) {
+	br := bufio.NewReader(r)
+	var src io.Reader = br
+	// gzip 格式以 0x1f 0x8b 开头
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid gzip archive: %v", ErrInvalid, err)
+		}
+		defer gz.Close()
+		src = gz
+	}
+
+	orgs := map[string]*cryptoOrg{}
+	tr := tar.NewReader(io.LimitReader(src, maxArchiveSize))
+	for files := 0; ; files++ {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid tar archive: %v", ErrInvalid, err)
+		}
+		if files >= maxArchiveFiles {
+			return nil, fmt.Errorf("%w: archive contains more than %d files", ErrInvalid, maxArchiveFiles)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		org, rest := locate(orgs, hdr.Name)
+		if org == nil || !wanted(rest) {
+			continue
+		}
+		if hdr.Size > maxFileSize {
+			return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrInvalid, hdr.Name, maxFileSize)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: read %s: %v", ErrInvalid, hdr.Name, err)
+		}
+		org.add(rest, data)
+	}
+
+	result := make([]*cryptoOrg, 0, len(orgs))
+	for _, org := range orgs {
+		result = append(result, org)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].orderer != result[j].orderer {
+			return !result[i].orderer
+		}
+		return result[i].domain < result[j].domain
+	})
+	return result, nil
+}
+
+// locate 按路径中的 peerOrganizations 或 ordererOrganizations 目录找到文件所属的组织，返回组织目录下的相对路径
+func locate(orgs map[string]*cryptoOrg, name string) (*cryptoOrg, []string) {
+	segments := strings.Split(path.Clean(strings.TrimPrefix(name, "./")), "/")
+	for i := 0; i+2 < len(segments); i++ {
+		orderer := segments[i] == "ordererOrganizations"
+		if !orderer && segments[i] != "peerOrganizations" {
+			continue
+		}
+		domain := strings.ToLower(segments[i+1])
+		key := segments[i] + "/" + domain
+		org, ok := orgs[key]
+		if !ok {
+			org = &cryptoOrg{domain: domain, orderer: orderer, nodes: map[string][]byte{}, users: map[string]*cryptoUser{}}
+			orgs[key] = org
+		}
+		return org, segments[i+2:]
+	}
+	return nil, nil
+}
+
+// wanted 只读取生成连接配置需要的文件
+func wanted(rest []string) bool {
+	switch {
+	case len(rest) == 4 && (rest[0] == "peers" || rest[0] == "orderers") && rest[2] == "tls" && rest[3] == "ca.crt":
+		return true
+	case len(rest) == 5 && rest[0] == "users" && rest[2] == "msp" && (rest[3] == "signcerts" || rest[3] == "keystore"):
+		return true
+	case len(rest) == 2 && rest[0] == "tlsca" && strings.HasSuffix(rest[1], ".pem"):
+		return true
+	case len(rest) == 3 && rest[0] == "msp" && rest[1] == "tlscacerts":
+		return true
+	}
+	return false
+}
+
+func (org *cryptoOrg) add(rest []string, data []byte) {
+	switch rest[0] {
+	case "peers", "orderers":
+		// Peer 组织目录下的 orderers 不是本组织的节点
+		if (rest[0] == "orderers") == org.orderer {
+			org.nodes[strings.ToLower(rest[1])] = data
+		}
+	case "users":
+		u, ok := org.users[rest[1]]
+		if !ok {
+			u = &cryptoUser{}
+			org.users[rest[1]] = u
+		}
+		if rest[3] == "signcerts" {
+			u.cert = data
+		} else {
+			u.keys = append(u.keys, data)
+		}
+	case "tlsca":
+		org.tlsCA = data
+	case "msp":
+		if org.tlsCA == nil {
+			org.tlsCA = data
+		}
+	}
+}
+
+// newUser 由用户目录生成用户，用户名为目录名中 @ 之前的部分。没有签名证书的目录返回 nil；
+// 密钥目录中有多个私钥时（如重新登记后）使用与证书匹配的私钥
+func newUser(dir string, u *cryptoUser) (*User, error) {
+	if u.cert == nil {
+		return nil, nil
+	}
+	name, _, _ := strings.Cut(dir, "@")
+	user := &User{Name: name, Certificate: string(u.cert)}
+	if len(u.keys) == 0 {
+		return user, nil
+	}
+	for _, key := range u.keys {
+		if _, err := identity.CheckKeyPair(u.cert, key); err == nil {
+			user.PrivateKey = string(key)
+			return user, nil
+		}
+	}
+	return nil, errors.New("no private key in msp/keystore matches msp/signcerts")
+}
+
+// userDirs 返回用户目录，管理员排在最前，SDK 默认以组织的第一个用户签名
+func userDirs(users map[string]*cryptoUser) []string {
+	dirs := sortedKeys(users)
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return strings.HasPrefix(dirs[i], "Admin@") && !strings.HasPrefix(dirs[j], "Admin@")
+	})
+	return dirs
+}
+
+// orgName 连接配置中的组织名称，使用域名第一段，重复时使用完整域名
+func orgName(domain string, used map[string]bool) string {
+	name, _, _ := strings.Cut(domain, ".")
+	if used[name] {
+		name = strings.ReplaceAll(domain, ".", "-")
+	}
+	used[name] = true
+	return name
+}
+
+// defaultMSPID 未指定时组织的 MSP ID
+func defaultMSPID(org *cryptoOrg, ordererOrgs int) string {
+	if org.orderer && ordererOrgs == 1 {
+		return "OrdererMSP"
+	}
+	label, _, _ := strings.Cut(org.domain, ".")
+	if label == "" {
+		return ""
+	}
+	return strings.ToUpper(label[:1]) + label[1:] + "MSP"
+}
+
+// sortedKeys 返回 map 按字典序排列的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,519 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	gmtls "gitee.com/china_uni/tjfoc-gm/tls"
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
+	fabImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab"
+	"github.com/qctc/fabric2-api-server/model/vo"
+	"github.com/qctc/fabric2-api-server/service"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// dialTimeout 单个节点建立连接与 TLS 握手的最长时间
+const dialTimeout = 5 * time.Second
+
+// Status 诊断步骤与检查项的结果
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusWarning Status = "warning" // 可以使用，但可能导致部分请求失败
+	StatusFail    Status = "fail"
+	StatusSkipped Status = "skipped" // 前置步骤失败或只做静态检查，未执行
+)
+
+func (s Status) severity() int {
+	switch s {
+	case StatusFail:
+		return 2
+	case StatusWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// 诊断步骤，按执行顺序排列
+const (
+	StepStructure = "structure"  // 必需的节点与节点之间的引用
+	StepCrypto    = "crypto"     // 证书与私钥文件可以读取和解析
+	StepKeyPair   = "keyPair"    // 私钥与证书匹配
+	StepMSPID     = "mspId"      // MSP ID 不重复、属于通道，用户证书由组织的 CA 签发
+	StepNetwork   = "network"    // Peer 与 Orderer 的 DNS 解析与 TCP 连接
+	StepTLS       = "tls"        // TLS 或国密 TLS 握手
+	StepChannels  = "channels"   // Peer 已加入连接配置中的通道
+	StepChaincode = "chaincodes" // 通道中已提交的链码
+)
+
+// Finding 检查项，Target 为连接配置中的位置，如 peers.peer0.org1.example.com.url
+type Finding struct {
+	Target  string      `json:"target"`
+	Status  Status      `json:"status"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Step 诊断步骤，Status 为各检查项中最差的结果
+type Step struct {
+	Name     string    `json:"name"`
+	Status   Status    `json:"status"`
+	Message  string    `json:"message,omitempty"`
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+func (s *Step) add(status Status, target, message string) {
+	s.addDetails(status, target, message, nil)
+}
+
+func (s *Step) addf(status Status, target, format string, args ...interface{}) {
+	s.addDetails(status, target, fmt.Sprintf(format, args...), nil)
+}
+
+func (s *Step) addDetails(status Status, target, message string, details interface{}) {
+	s.Findings = append(s.Findings, Finding{Target: target, Status: status, Message: message, Details: details})
+}
+
+// finish 按检查项确定步骤结果，没有问题时使用 ok 作为说明
+func (s *Step) finish(ok string) {
+	s.Status = StatusOK
+	var problems int
+	for _, f := range s.Findings {
+		if f.Status.severity() > s.Status.severity() {
+			s.Status = f.Status
+		}
+		if f.Status == StatusFail || f.Status == StatusWarning {
+			problems++
+		}
+	}
+	if problems == 0 {
+		s.Message = ok
+		return
+	}
+	s.Message = fmt.Sprintf("%d problem(s) found", problems)
+}
+
+func skipped(name, reason string) Step {
+	return Step{Name: name, Status: StatusSkipped, Message: reason}
+}
+
+// Report 诊断报告，Status 为各步骤中最差的结果
+type Report struct {
+	SdkId  string `json:"sdkId"`
+	Status Status `json:"status"`
+	Steps  []Step `json:"steps"`
+}
+
+// DiagnoseOptions 诊断选项
+type DiagnoseOptions struct {
+	GM  bool // 使用国密 TLS
+	SM3 bool
+	// LintOnly 只检查连接配置与证书，不访问网络
+	LintOnly bool
+	// Chaincode 检查该链码已在通道中提交，为空时只列出已提交的链码
+	Chaincode string
+}
+
+// Diagnose 逐步检查连接配置：结构、证书与私钥文件、私钥与证书匹配、MSP ID、节点的 DNS 解析与 TCP 连接、
+// TLS 握手与服务端证书、Peer 加入的通道与通道中的链码。某一步失败时依赖它的后续步骤标记为 skipped
+func Diagnose(ctx context.Context, sdkConfig string, opts DiagnoseOptions) *Report {
+	report := &Report{SdkId: utils.SdkId(sdkConfig)}
+	defer report.summarize()
+
+	m, err := parseModel(sdkConfig)
+	if err != nil {
+		step := Step{Name: StepStructure}
+		step.add(StatusFail, "", "profile is not valid YAML: "+err.Error())
+		step.finish("")
+		report.Steps = append(report.Steps, step,
+			skipped(StepCrypto, "structure check failed"), skipped(StepKeyPair, "structure check failed"),
+			skipped(StepMSPID, "structure check failed"), skipped(StepNetwork, "structure check failed"),
+			skipped(StepTLS, "structure check failed"), skipped(StepChannels, "structure check failed"),
+			skipped(StepChaincode, "structure check failed"))
+		return report
+	}
+
+	l := &lint{m: m, certs: map[string][]byte{}, keys: map[string][]byte{}}
+	structure := l.checkStructure()
+	report.Steps = append(report.Steps, structure, l.checkCrypto(), l.checkKeyPairs())
+	if opts.LintOnly {
+		report.Steps = append(report.Steps, l.checkMSPIDs(nil),
+			skipped(StepNetwork, "lint only"), skipped(StepTLS, "lint only"),
+			skipped(StepChannels, "lint only"), skipped(StepChaincode, "lint only"))
+		return report
+	}
+
+	p := &probe{m: m, gm: opts.GM, dial: dialTCP}
+	// entityMatchers 替换后的地址与 SDK 加载的 TLS CA 证书，加载失败时按连接配置中的地址检查网络
+	if backends, err := config.FromRaw([]byte(sdkConfig), "yaml")(); err != nil {
+		p.endpointErr = err
+	} else if p.endpoints, err = fabImpl.ConfigFromBackend(backends...); err != nil {
+		p.endpointErr = err
+	}
+	network := p.checkNetwork(ctx)
+	tls := p.checkTLS(ctx)
+
+	var channelMSPs map[string]*x509.CertPool
+	var channels, chaincodes Step
+	if structure.Status == StatusFail {
+		channels = skipped(StepChannels, "structure check failed")
+		chaincodes = skipped(StepChaincode, "structure check failed")
+	} else {
+		channels, chaincodes, channelMSPs = p.checkSDK(ctx, sdkConfig, opts)
+	}
+	mspStep := l.checkMSPIDs(channelMSPs)
+	if channelMSPs == nil {
+		mspStep.Message += " (channel configuration is not available, only duplicates are checked)"
+	}
+	report.Steps = append(report.Steps, mspStep, network, tls, channels, chaincodes)
+	return report
+}
+
+func (r *Report) summarize() {
+	r.Status = StatusOK
+	for _, s := range r.Steps {
+		if s.Status.severity() > r.Status.severity() {
+			r.Status = s.Status
+		}
+	}
+}
+
+// probe 访问网络的检查
+type probe struct {
+	m           *model
+	gm          bool
+	endpoints   fab.EndpointConfig
+	endpointErr error
+	dial        func(ctx context.Context, addr string) (net.Conn, error)
+
+	// reachable 可以建立 TCP 连接的节点
+	mu        sync.Mutex
+	reachable map[string]bool
+}
+
+// endpoint 待检查的节点
+type endpoint struct {
+	target      string // 连接配置中的位置
+	url         string
+	grpcOptions map[string]interface{}
+	tlsCACert   *x509.Certificate
+}
+
+// nodes 返回全部 Peer 与 Orderer，SDK 可以加载连接配置时使用 entityMatchers 替换后的地址
+func (p *probe) nodes() []endpoint {
+	var result []endpoint
+	for _, name := range sortedKeys(p.m.Peers) {
+		n := endpoint{target: "peers." + name, url: p.m.Peers[name].URL, grpcOptions: p.m.Peers[name].GRPCOptions}
+		if p.endpoints != nil {
+			if cfg, ok := p.endpoints.PeerConfig(name); ok {
+				n.url, n.grpcOptions, n.tlsCACert = cfg.URL, cfg.GRPCOptions, cfg.TLSCACert
+			}
+		}
+		result = append(result, n)
+	}
+	for _, name := range sortedKeys(p.m.Orderers) {
+		n := endpoint{target: "orderers." + name, url: p.m.Orderers[name].URL, grpcOptions: p.m.Orderers[name].GRPCOptions}
+		if p.endpoints != nil {
+			if cfg, ok, _ := p.endpoints.OrdererConfig(name); ok {
+				n.url, n.grpcOptions, n.tlsCACert = cfg.URL, cfg.GRPCOptions, cfg.TLSCACert
+			}
+		}
+		result = append(result, n)
+	}
+	return result
+}
+
+// serverName 校验服务端证书使用的名称，默认为地址中的主机名
+func (n endpoint) serverName(host string) string {
+	if name, _ := n.grpcOptions["ssl-target-name-override"].(string); name != "" {
+		return name
+	}
+	return host
+}
+
+// NetworkDetails 节点的 DNS 解析与 TCP 连接结果
+type NetworkDetails struct {
+	URL       string   `json:"url"`
+	Addresses []string `json:"addresses,omitempty"`
+	LatencyMs int64    `json:"latencyMs,omitempty"`
+}
+
+// checkNetwork 解析节点主机名并建立 TCP 连接
+func (p *probe) checkNetwork(ctx context.Context) Step {
+	step := Step{Name: StepNetwork}
+	p.reachable = map[string]bool{}
+	nodes := p.nodes()
+	findings := make([]Finding, len(nodes))
+	var wg sync.WaitGroup
+	for i, n := range nodes {
+		wg.Add(1)
+		go func(i int, n endpoint) {
+			defer wg.Done()
+			findings[i] = p.checkNode(ctx, n)
+		}(i, n)
+	}
+	wg.Wait()
+	step.Findings = findings
+	step.finish("all peers and orderers are reachable")
+	return step
+}
+
+func (p *probe) checkNode(ctx context.Context, n endpoint) Finding {
+	f := Finding{Target: n.target}
+	addr, _, err := nodeAddress(n.url, n.grpcOptions)
+	if err != nil {
+		f.Status, f.Message = StatusFail, err.Error()
+		return f
+	}
+	details := NetworkDetails{URL: n.url}
+	f.Details = &details
+	host, port, _ := net.SplitHostPort(addr)
+	if net.ParseIP(host) == nil {
+		ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+		defer cancel()
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		if err != nil {
+			f.Status, f.Message = StatusFail, "dns lookup failed: "+err.Error()
+			return f
+		}
+		details.Addresses = addrs
+	}
+	start := time.Now()
+	conn, err := p.dial(ctx, net.JoinHostPort(host, port))
+	if err != nil {
+		f.Status, f.Message = StatusFail, "tcp connection failed: "+err.Error()
+		return f
+	}
+	conn.Close()
+	details.LatencyMs = time.Since(start).Milliseconds()
+	p.mu.Lock()
+	p.reachable[n.target] = true
+	p.mu.Unlock()
+	f.Status, f.Message = StatusOK, "reachable"
+	return f
+}
+
+// TLSDetails TLS 握手结果与服务端证书
+type TLSDetails struct {
+	Protocol           string               `json:"protocol"`
+	NegotiatedProtocol string               `json:"negotiatedProtocol,omitempty"`
+	ServerName         string               `json:"serverName"`
+	Certificates       []CertificateDetails `json:"certificates,omitempty"`
+}
+
+// checkTLS 与 TCP 可达的 grpcs 节点进行 TLS 握手，使用 SDK 加载的 TLS CA 证书与客户端证书校验服务端证书。
+// 握手失败时尝试另一种 TLS（标准或国密），成功则提示 isGM 设置错误
+func (p *probe) checkTLS(ctx context.Context) Step {
+	if p.endpoints == nil {
+		step := Step{Name: StepTLS}
+		step.add(StatusFail, "", "SDK can not load the profile: "+p.endpointErr.Error())
+		step.finish("")
+		return step
+	}
+	step := Step{Name: StepTLS}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, n := range p.nodes() {
+		addr, secure, err := nodeAddress(n.url, n.grpcOptions)
+		if err != nil || !p.reachable[n.target] {
+			continue
+		}
+		if !secure {
+			step.add(StatusOK, n.target, "plaintext connection, TLS is not used")
+			continue
+		}
+		wg.Add(1)
+		go func(n endpoint, addr string) {
+			defer wg.Done()
+			f := p.handshake(ctx, n, addr)
+			mu.Lock()
+			step.Findings = append(step.Findings, f)
+			mu.Unlock()
+		}(n, addr)
+	}
+	wg.Wait()
+	sort.SliceStable(step.Findings, func(i, j int) bool { return step.Findings[i].Target < step.Findings[j].Target })
+	step.finish("TLS handshakes succeeded")
+	return step
+}
+
+func (p *probe) handshake(ctx context.Context, n endpoint, addr string) Finding {
+	f := Finding{Target: n.target}
+	host, _, _ := net.SplitHostPort(addr)
+	name := n.serverName(host)
+	details, err := p.tlsHandshake(ctx, n, addr, name, p.gm)
+	if err == nil {
+		f.Status, f.Message, f.Details = StatusOK, "handshake succeeded", details
+		return f
+	}
+	f.Status, f.Message = StatusFail, "handshake failed: "+err.Error()
+	if _, other := p.tlsHandshake(ctx, n, addr, name, !p.gm); other == nil {
+		if p.gm {
+			f.Message += "; handshake succeeds with standard TLS, set isGM to false"
+		} else {
+			f.Message += "; handshake succeeds with GM TLS, set isGM to true"
+		}
+	}
+	return f
+}
+
+func (p *probe) tlsHandshake(ctx context.Context, n endpoint, addr, name string, gm bool) (*TLSDetails, error) {
+	cfg, err := comm.TLSConfig(n.tlsCACert, name, p.endpoints, gm)
+	if err != nil {
+		return nil, err
+	}
+	// gRPC 使用 HTTP/2
+	cfg.NextProtos = []string{"h2"}
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	raw, err := p.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer raw.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = raw.SetDeadline(deadline)
+	}
+	conn := gmtls.Client(raw, cfg)
+	if err := conn.Handshake(); err != nil {
+		return nil, err
+	}
+	state := conn.ConnectionState()
+	details := &TLSDetails{Protocol: tlsVersion(state.Version), NegotiatedProtocol: state.NegotiatedProtocol, ServerName: name}
+	for _, cert := range state.PeerCertificates {
+		details.Certificates = append(details.Certificates, certificateDetails(cert))
+	}
+	return details, nil
+}
+
+func tlsVersion(v uint16) string {
+	switch v {
+	case gmtls.VersionGMSSL:
+		return "GMTLS"
+	case gmtls.VersionTLS10:
+		return "TLS1.0"
+	case gmtls.VersionTLS11:
+		return "TLS1.1"
+	case gmtls.VersionTLS12:
+		return "TLS1.2"
+	default:
+		return fmt.Sprintf("0x%04x", v)
+	}
+}
+
+// checkSDK 以不加入连接池的临时 SDK 实例查询客户端组织的 Peer 加入的通道与通道中已提交的链码，并读取通道配置中的 MSP，
+// 诊断结束后关闭该实例，只读的诊断不会把连接配置留在连接池中
+func (p *probe) checkSDK(ctx context.Context, sdkConfig string, opts DiagnoseOptions) (Step, Step, map[string]*x509.CertPool) {
+	channels := Step{Name: StepChannels}
+	sdk, err := service.NewFabric2Service(sdkConfig, opts.GM, opts.SM3)
+	if err != nil {
+		channels.add(StatusFail, "", "sdk initialize error: "+err.Error())
+		channels.finish("")
+		return channels, skipped(StepChaincode, "sdk initialize failed"), nil
+	}
+	defer sdk.Close()
+	channelID, err := sdk.ChannelID()
+	if err != nil {
+		channels.add(StatusFail, "channels", err.Error())
+		channels.finish("")
+		return channels, skipped(StepChaincode, "channel is not configured"), nil
+	}
+
+	// Peer 通常只允许本组织的管理员查询加入的通道
+	_, clientOrg, _ := lookupName(p.m.Organizations, p.m.Client.Organization)
+	peers := clientOrg.Peers
+	if len(peers) == 0 {
+		peers = sortedKeys(p.m.Channels[channelID].Peers)
+	}
+	var joined []string
+	for _, peer := range peers {
+		target := "peers." + peer
+		list, err := sdk.QueryPeerChannels(ctx, peer)
+		switch {
+		case err != nil:
+			channels.addf(StatusFail, target, "query channels failed: %v", err)
+		case !contains(list, channelID):
+			channels.addDetails(StatusFail, target, fmt.Sprintf("peer has not joined channel %s", channelID), list)
+		default:
+			channels.addDetails(StatusOK, target, fmt.Sprintf("peer has joined channel %s", channelID), list)
+			joined = append(joined, peer)
+		}
+	}
+	if len(peers) == 0 {
+		channels.add(StatusFail, "organizations."+p.m.Client.Organization+".peers", "client organization has no peers to query")
+	}
+	channels.finish(fmt.Sprintf("peers have joined channel %s", channelID))
+
+	if len(joined) == 0 {
+		return channels, skipped(StepChaincode, "no peer has joined the channel"), nil
+	}
+	chaincodes := Step{Name: StepChaincode}
+	for _, peer := range joined {
+		target := "peers." + peer
+		contracts, err := sdk.QueryCommittedChaincodes(ctx, peer)
+		switch {
+		case err != nil:
+			chaincodes.addf(StatusFail, target, "query committed chaincodes failed: %v", err)
+		case opts.Chaincode != "" && !hasChaincode(contracts, opts.Chaincode):
+			chaincodes.addDetails(StatusFail, target, fmt.Sprintf("chaincode %s is not committed on channel %s", opts.Chaincode, channelID), contracts)
+		case len(contracts) == 0:
+			chaincodes.add(StatusWarning, target, fmt.Sprintf("no chaincode is committed on channel %s", channelID))
+		default:
+			chaincodes.addDetails(StatusOK, target, fmt.Sprintf("%d chaincode(s) committed on channel %s", len(contracts), channelID), contracts)
+		}
+	}
+	chaincodes.finish("chaincodes are visible")
+
+	msps, err := sdk.ChannelMSPs(ctx)
+	if err != nil {
+		return channels, chaincodes, nil
+	}
+	return channels, chaincodes, certPools(msps)
+}
+
+// certPools 将通道 MSP 的根证书与中间证书转换为证书池
+func certPools(msps []service.ChannelMSP) map[string]*x509.CertPool {
+	pools := make(map[string]*x509.CertPool, len(msps))
+	for _, msp := range msps {
+		pool := x509.NewCertPool()
+		for _, cert := range append(append([][]byte{}, msp.RootCerts...), msp.IntermediateCerts...) {
+			pool.AppendCertsFromPEM(cert)
+		}
+		pools[msp.ID] = pool
+	}
+	return pools
+}
+
+func hasChaincode(contracts []vo.ContractVO, name string) bool {
+	for _, c := range contracts {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func dialTCP(ctx context.Context, addr string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", addr)
+}
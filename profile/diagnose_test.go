@@ -0,0 +1,302 @@
+package profile
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	fabImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab"
+	"github.com/qctc/fabric2-api-server/service"
+)
+
+func TestDiagnoseLint(t *testing.T) {
+	adminCert, adminKey := newCert(t, "Admin@org1.example.com")
+	_, otherKey := newCert(t, "User1@org1.example.com")
+	tlsCA, _ := newCert(t, "tlsca.org1.example.com")
+	sdkConfig := `
+version: 1.0.0
+client:
+  organization: Org1
+channels:
+  mychannel:
+    peers:
+      peer0.org1.example.com: {}
+      peer1.org1.example.com: {}
+organizations:
+  Org1:
+    mspid: Org1MSP
+    peers: [peer0.org1.example.com]
+    users:
+      Admin:
+        cert:
+          pem: |
+` + indent(adminCert, 12) + `
+        key:
+          pem: |
+` + indent(adminKey, 12) + `
+      User1:
+        cert:
+          pem: |
+` + indent(adminCert, 12) + `
+        key:
+          pem: |
+` + indent(otherKey, 12) + `
+      User2:
+        cert:
+          path: /nonexistent/User2@org1.example.com-cert.pem
+  Org2:
+    MSPID: Org1MSP
+orderers:
+  orderer.example.com:
+    url: grpc://orderer.example.com:7050
+peers:
+  peer0.org1.example.com:
+    URL: peer0.org1.example.com:7051
+    tlsCACerts:
+      pem: |
+` + indent(tlsCA, 8) + `
+`
+	report := Diagnose(context.Background(), sdkConfig, DiagnoseOptions{LintOnly: true})
+	if report.Status != StatusFail {
+		t.Errorf("status = %s", report.Status)
+	}
+	steps := map[string]Step{}
+	for _, s := range report.Steps {
+		steps[s.Name] = s
+	}
+	for name, want := range map[string][]string{
+		StepStructure: {"channels.mychannel.peers: peer peer1.org1.example.com is not defined in peers"},
+		StepCrypto:    {"organizations.Org1.users.User2.cert: file /nonexistent/User2@org1.example.com-cert.pem not found"},
+		StepKeyPair:   {"organizations.Org1.users.User1: invalid identity: private key does not match certificate"},
+		StepMSPID:     {"organizations: organizations Org1, Org2 share mspid Org1MSP"},
+	} {
+		step := steps[name]
+		if step.Status != StatusFail {
+			t.Errorf("%s status = %s, findings = %+v", name, step.Status, step.Findings)
+		}
+		for _, w := range want {
+			if !hasFinding(step, w) {
+				t.Errorf("%s findings %+v do not contain %q", name, step.Findings, w)
+			}
+		}
+	}
+	// 键不区分大小写，Admin 的私钥与证书匹配
+	if hasFinding(steps[StepStructure], "mspid is required") || hasFinding(steps[StepKeyPair], "users.Admin: invalid") {
+		t.Errorf("unexpected findings: %+v %+v", steps[StepStructure].Findings, steps[StepKeyPair].Findings)
+	}
+	for _, name := range []string{StepNetwork, StepTLS, StepChannels, StepChaincode} {
+		if steps[name].Status != StatusSkipped {
+			t.Errorf("%s status = %s", name, steps[name].Status)
+		}
+	}
+
+	report = Diagnose(context.Background(), "client: [", DiagnoseOptions{})
+	if report.Status != StatusFail || len(report.Steps) != 8 || report.Steps[0].Status != StatusFail || report.Steps[7].Status != StatusSkipped {
+		t.Errorf("invalid yaml report = %+v", report)
+	}
+}
+
+func TestDiagnoseTLS(t *testing.T) {
+	caCert, caKey := newCA(t)
+	serverCert := newServerCert(t, caCert, caKey, "peer0.org1.example.com")
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{serverCert}, NextProtos: []string{"h2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})
+	sdkConfig := `
+version: 1.0.0
+client:
+  organization: org1
+organizations:
+  org1:
+    mspid: Org1MSP
+    peers: [peer0.org1.example.com, peer1.org1.example.com, peer2.org1.example.com]
+peers:
+  peer0.org1.example.com:
+    url: grpcs://` + listener.Addr().String() + `
+    grpcOptions:
+      ssl-target-name-override: peer0.org1.example.com
+    tlsCACerts:
+      pem: |
+` + indent(string(caPEM), 8) + `
+  peer1.org1.example.com:
+    url: grpcs://` + listener.Addr().String() + `
+    grpcOptions:
+      ssl-target-name-override: peer1.org1.example.com
+    tlsCACerts:
+      pem: |
+` + indent(string(caPEM), 8) + `
+  peer2.org1.example.com:
+    url: grpcs://` + closed.Addr().String() + `
+    tlsCACerts:
+      pem: |
+` + indent(string(caPEM), 8) + `
+`
+	m, err := parseModel(sdkConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backends, err := config.FromRaw([]byte(sdkConfig), "yaml")()
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoints, err := fabImpl.ConfigFromBackend(backends...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &probe{m: m, endpoints: endpoints, dial: dialTCP}
+
+	network := p.checkNetwork(context.Background())
+	if network.Status != StatusFail || len(network.Findings) != 3 ||
+		network.Findings[0].Status != StatusOK || network.Findings[2].Status != StatusFail ||
+		!strings.HasPrefix(network.Findings[2].Message, "tcp connection failed") {
+		t.Errorf("network = %+v", network)
+	}
+
+	step := p.checkTLS(context.Background())
+	if len(step.Findings) != 2 {
+		t.Fatalf("tls findings = %+v", step.Findings)
+	}
+	peer0, peer1 := step.Findings[0], step.Findings[1]
+	details, ok := peer0.Details.(*TLSDetails)
+	if peer0.Status != StatusOK || !ok {
+		t.Fatalf("peer0 = %+v", peer0)
+	}
+	if details.Protocol != "TLS1.2" || details.NegotiatedProtocol != "h2" || len(details.Certificates) != 1 ||
+		details.Certificates[0].Subject != "CN=peer0.org1.example.com" || details.Certificates[0].KeyType != "ECDSA" {
+		t.Errorf("peer0 details = %+v", details)
+	}
+	// 服务端证书不包含 peer1 的主机名
+	if peer1.Status != StatusFail || !strings.Contains(peer1.Message, "peer1.org1.example.com") {
+		t.Errorf("peer1 = %+v", peer1)
+	}
+}
+
+func TestCheckSDKLeavesPoolUnchanged(t *testing.T) {
+	dir := filepath.ToSlash(t.TempDir())
+	sdkConfig := `
+version: 1.0.0
+client:
+  organization: org1
+  credentialStore:
+    path: ` + dir + `/state
+    cryptoStore:
+      path: ` + dir + `/msp
+organizations:
+  org1:
+    mspid: Org1MSP
+    cryptoPath: ` + dir + `/users/{username}/msp
+channels:
+  mychannel:
+    peers: {}
+`
+	m, err := parseModel(sdkConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &probe{m: m, dial: dialTCP}
+	pooled := len(service.List())
+	channels, chaincodes, _ := p.checkSDK(context.Background(), sdkConfig, DiagnoseOptions{})
+	if channels.Status != StatusFail || !hasFinding(channels, "client organization has no peers to query") || chaincodes.Status != StatusSkipped {
+		t.Errorf("channels = %+v, chaincodes = %+v", channels, chaincodes)
+	}
+	// 诊断使用临时 SDK 实例，不加入连接池
+	if n := len(service.List()); n != pooled {
+		t.Errorf("pool has %d profiles after diagnose, want %d", n, pooled)
+	}
+}
+
+func hasFinding(step Step, want string) bool {
+	for _, f := range step.Findings {
+		if strings.Contains(f.Target+": "+f.Message, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func indent(s string, n int) string {
+	prefix := strings.Repeat(" ", n)
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	return prefix + strings.Join(lines, "\n"+prefix)
+}
+
+func newCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "tlsca.org1.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func newServerCert(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, host string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
@@ -0,0 +1,549 @@
+package profile
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/pathvar"
+	"github.com/qctc/fabric2-api-server/hsm"
+	"github.com/qctc/fabric2-api-server/identity"
+	"gopkg.in/yaml.v3"
+)
+
+// model 诊断用到的连接配置内容。SDK 读取连接配置时键不区分大小写，解析前除名称外的键统一转换为小写
+type model struct {
+	Version string `yaml:"version"`
+	Client  struct {
+		Organization string `yaml:"organization"`
+		TLSCerts     struct {
+			Client struct {
+				Key  pemRef `yaml:"key"`
+				Cert pemRef `yaml:"cert"`
+			} `yaml:"client"`
+		} `yaml:"tlscerts"`
+	} `yaml:"client"`
+	Channels               map[string]modelChannel `yaml:"channels"`
+	Organizations          map[string]modelOrg     `yaml:"organizations"`
+	Orderers               map[string]modelNode    `yaml:"orderers"`
+	Peers                  map[string]modelNode    `yaml:"peers"`
+	CertificateAuthorities map[string]modelNode    `yaml:"certificateauthorities"`
+}
+
+type modelChannel struct {
+	Peers map[string]interface{} `yaml:"peers"`
+}
+
+type modelOrg struct {
+	MSPID                  string               `yaml:"mspid"`
+	CryptoPath             string               `yaml:"cryptopath"`
+	Users                  map[string]modelUser `yaml:"users"`
+	Peers                  []string             `yaml:"peers"`
+	CertificateAuthorities []string             `yaml:"certificateauthorities"`
+}
+
+type modelUser struct {
+	Key  pemRef `yaml:"key"`
+	Cert pemRef `yaml:"cert"`
+}
+
+type modelNode struct {
+	URL         string                 `yaml:"url"`
+	GRPCOptions map[string]interface{} `yaml:"grpcoptions"`
+	TLSCACerts  pemRef                 `yaml:"tlscacerts"`
+}
+
+// pemRef 证书或私钥，内嵌的 pem 与文件路径 path 都可以是字符串或列表
+type pemRef struct {
+	Path stringList `yaml:"path"`
+	PEM  stringList `yaml:"pem"`
+}
+
+func (r pemRef) empty() bool {
+	return len(r.Path) == 0 && len(r.PEM) == 0
+}
+
+// stringList 字符串或字符串列表，忽略空字符串
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	var values []string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		var s string
+		if err := node.Decode(&s); err != nil {
+			return err
+		}
+		values = []string{s}
+	default:
+		if err := node.Decode(&values); err != nil {
+			return err
+		}
+	}
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// namedSections 以名称为键的节点，名称保持原样
+var namedSections = map[string]bool{
+	"channels":               true,
+	"organizations":          true,
+	"orderers":               true,
+	"peers":                  true,
+	"certificateauthorities": true,
+	"users":                  true,
+}
+
+// parseModel 解析连接配置
+func parseModel(sdkConfig string) (*model, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal([]byte(sdkConfig), &raw); err != nil {
+		return nil, err
+	}
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, errors.New("profile must be a YAML mapping")
+	}
+	data, err := yaml.Marshal(normalize(raw, false))
+	if err != nil {
+		return nil, err
+	}
+	var m model
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// normalize 将键转换为小写，named 为 true 时当前层的键是名称，保持原样
+func normalize(value interface{}, named bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			key := k
+			if !named {
+				key = strings.ToLower(k)
+			}
+			result[key] = normalize(child, !named && namedSections[key])
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, child := range v {
+			result[i] = normalize(child, false)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// lookupName 不区分大小写查找名称
+func lookupName[V any](m map[string]V, name string) (string, V, bool) {
+	if v, ok := m[name]; ok {
+		return name, v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return k, v, true
+		}
+	}
+	var zero V
+	return "", zero, false
+}
+
+// lint 执行不访问网络的检查：结构、证书与私钥文件、私钥与证书匹配、MSP ID
+type lint struct {
+	m *model
+	// 读取成功的用户证书与私钥，键为 <组织>/<用户>
+	certs, keys map[string][]byte
+	clientCert  []byte
+	clientKey   []byte
+}
+
+// checkStructure 检查必需的节点与节点之间的引用
+func (l *lint) checkStructure() Step {
+	step := Step{Name: StepStructure}
+	m := l.m
+	if m.Version == "" {
+		step.add(StatusWarning, "version", "version is not set, the SDK assumes 1.0.0")
+	}
+
+	switch _, _, found := lookupName(m.Organizations, m.Client.Organization); {
+	case m.Client.Organization == "":
+		step.add(StatusFail, "client.organization", "client.organization is required")
+	case !found:
+		step.addf(StatusFail, "client.organization", "organization %s is not defined in organizations", m.Client.Organization)
+	}
+
+	if len(m.Organizations) == 0 {
+		step.add(StatusFail, "organizations", "no organization is defined")
+	}
+	for _, name := range sortedKeys(m.Organizations) {
+		org := m.Organizations[name]
+		target := "organizations." + name
+		if org.MSPID == "" {
+			step.add(StatusFail, target+".mspid", "mspid is required")
+		}
+		for _, peer := range org.Peers {
+			if _, _, ok := lookupName(m.Peers, peer); !ok {
+				step.addf(StatusFail, target+".peers", "peer %s is not defined in peers", peer)
+			}
+		}
+		for _, ca := range org.CertificateAuthorities {
+			if _, _, ok := lookupName(m.CertificateAuthorities, ca); !ok {
+				step.addf(StatusWarning, target+".certificateAuthorities", "certificate authority %s is not defined in certificateAuthorities", ca)
+			}
+		}
+	}
+	if name, org, ok := lookupName(m.Organizations, m.Client.Organization); ok && len(org.Users) == 0 {
+		step.addf(StatusFail, "organizations."+name+".users", "client organization %s has no users to sign requests", name)
+	}
+
+	switch len(m.Channels) {
+	case 0:
+		step.add(StatusFail, "channels", "no channel is defined")
+	case 1:
+	default:
+		step.addf(StatusWarning, "channels", "%d channels are defined, requests use an arbitrary one of them", len(m.Channels))
+	}
+	for _, name := range sortedKeys(m.Channels) {
+		ch := m.Channels[name]
+		if len(ch.Peers) == 0 {
+			step.addf(StatusWarning, "channels."+name+".peers", "channel %s lists no peers, all peers are used", name)
+		}
+		for _, peer := range sortedKeys(ch.Peers) {
+			if _, _, ok := lookupName(m.Peers, peer); !ok {
+				step.addf(StatusFail, "channels."+name+".peers", "peer %s is not defined in peers", peer)
+			}
+		}
+	}
+
+	if len(m.Peers) == 0 {
+		step.add(StatusFail, "peers", "no peer is defined")
+	}
+	if len(m.Orderers) == 0 {
+		step.add(StatusWarning, "orderers", "no orderer is defined, transactions can only be submitted to orderers from the channel configuration")
+	}
+	for _, section := range []struct {
+		name  string
+		nodes map[string]modelNode
+	}{{"peers", m.Peers}, {"orderers", m.Orderers}} {
+		for _, name := range sortedKeys(section.nodes) {
+			if err := checkNodeURL(section.nodes[name]); err != nil {
+				step.add(StatusFail, section.name+"."+name+".url", err.Error())
+			}
+		}
+	}
+	step.finish("profile structure is valid")
+	return step
+}
+
+// checkNodeURL 校验节点地址，grpcs 地址需要 TLS CA 证书
+func checkNodeURL(n modelNode) error {
+	if n.URL == "" {
+		return errors.New("url is required")
+	}
+	u := n.URL
+	if !strings.Contains(u, "://") {
+		u = "grpcs://" + u
+	}
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Hostname() == "" || parsed.Port() == "" {
+		return fmt.Errorf("url %s must be host:port with optional grpc:// or grpcs:// scheme", n.URL)
+	}
+	switch parsed.Scheme {
+	case "grpc", "grpcs":
+	default:
+		return fmt.Errorf("url scheme %s is not supported, must be grpc or grpcs", parsed.Scheme)
+	}
+	if parsed.Scheme == "grpcs" && n.TLSCACerts.empty() && n.GRPCOptions["allow-insecure"] != true {
+		return errors.New("tlsCACerts is required for TLS connections")
+	}
+	return nil
+}
+
+// checkCrypto 读取连接配置引用的证书与私钥
+func (l *lint) checkCrypto() Step {
+	step := Step{Name: StepCrypto}
+	m := l.m
+	for _, orgName := range sortedKeys(m.Organizations) {
+		org := m.Organizations[orgName]
+		for _, userName := range sortedKeys(org.Users) {
+			user := org.Users[userName]
+			target := "organizations." + orgName + ".users." + userName
+			key := orgName + "/" + userName
+			stored := identity.Default != nil && org.MSPID != ""
+			if stored {
+				_, err := identity.Default.Get(org.MSPID, userName)
+				stored = err == nil
+			}
+
+			switch {
+			case !user.Cert.empty():
+				l.certs[key] = step.readCertificate(target+".cert", user.Cert)
+			case stored:
+				step.add(StatusOK, target+".cert", "certificate is loaded from the identity store")
+			case org.CryptoPath != "":
+				step.add(StatusWarning, target+".cert", "certificate is loaded from cryptoPath and is not checked")
+			default:
+				step.add(StatusFail, target+".cert", "no certificate, set cert.pem or cert.path or import the identity into the identity store")
+			}
+
+			switch {
+			case !user.Key.empty():
+				l.keys[key] = step.readKey(target+".key", user.Key)
+			case stored:
+				step.add(StatusOK, target+".key", "private key is loaded from the identity store")
+			case hsm.Default != nil:
+				step.add(StatusOK, target+".key", "private key is looked up in the HSM")
+			case org.CryptoPath != "":
+				step.add(StatusWarning, target+".key", "private key is loaded from cryptoPath and is not checked")
+			default:
+				step.add(StatusWarning, target+".key", "no private key, the user can not sign requests")
+			}
+		}
+	}
+
+	client := m.Client.TLSCerts.Client
+	if !client.Cert.empty() || !client.Key.empty() {
+		l.clientCert = step.readCertificate("client.tlsCerts.client.cert", client.Cert)
+		l.clientKey = step.readKey("client.tlsCerts.client.key", client.Key)
+	}
+	for _, section := range []struct {
+		name  string
+		nodes map[string]modelNode
+	}{{"peers", m.Peers}, {"orderers", m.Orderers}, {"certificateAuthorities", m.CertificateAuthorities}} {
+		for _, name := range sortedKeys(section.nodes) {
+			if ref := section.nodes[name].TLSCACerts; !ref.empty() {
+				step.readCertificate(section.name+"."+name+".tlsCACerts", ref)
+			}
+		}
+	}
+	step.finish("all certificates and private keys are readable")
+	return step
+}
+
+// readPEM 读取内嵌或文件中的 PEM，返回读取到的内容
+func (s *Step) readPEM(target string, ref pemRef) ([]byte, bool) {
+	var data []byte
+	for _, p := range ref.PEM {
+		data = append(data, []byte(p+"\n")...)
+	}
+	ok := true
+	for _, p := range ref.Path {
+		path := pathvar.Subst(p)
+		content, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			s.addf(StatusFail, target, "file %s not found", path)
+			ok = false
+		case err != nil:
+			s.addf(StatusFail, target, "file %s is not readable: %v", path, err)
+			ok = false
+		default:
+			data = append(data, content...)
+		}
+	}
+	return data, ok
+}
+
+// readCertificate 解析证书，一个节点中可以有多个证书
+func (s *Step) readCertificate(target string, ref pemRef) []byte {
+	data, ok := s.readPEM(target, ref)
+	if !ok {
+		return nil
+	}
+	rest := data
+	var found bool
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		found = true
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			s.addf(StatusFail, target, "invalid certificate: %v", err)
+			return nil
+		}
+		info := certificateDetails(cert)
+		switch now := time.Now(); {
+		case now.After(cert.NotAfter):
+			s.addDetails(StatusFail, target, "certificate "+info.Subject+" has expired", info)
+		case now.Before(cert.NotBefore):
+			s.addDetails(StatusFail, target, "certificate "+info.Subject+" is not yet valid", info)
+		default:
+			s.addDetails(StatusOK, target, "certificate "+info.Subject, info)
+		}
+	}
+	if !found {
+		s.add(StatusFail, target, "no PEM encoded CERTIFICATE found")
+		return nil
+	}
+	return data
+}
+
+// readKey 检查私钥为 PEM 格式且未加密
+func (s *Step) readKey(target string, ref pemRef) []byte {
+	data, ok := s.readPEM(target, ref)
+	if !ok {
+		return nil
+	}
+	block, _ := pem.Decode(data)
+	switch {
+	case block == nil:
+		s.add(StatusFail, target, "private key must be PEM encoded")
+		return nil
+	case block.Headers["DEK-Info"] != "" || strings.Contains(block.Type, "ENCRYPTED"):
+		s.add(StatusFail, target, "private key must not be password protected")
+		return nil
+	}
+	s.add(StatusOK, target, "private key is readable")
+	return data
+}
+
+// checkKeyPairs 校验用户与 TLS 客户端的私钥与证书匹配
+func (l *lint) checkKeyPairs() Step {
+	step := Step{Name: StepKeyPair}
+	for _, key := range sortedKeys(l.keys) {
+		cert := l.certs[key]
+		if cert == nil || l.keys[key] == nil {
+			continue
+		}
+		orgName, userName, _ := strings.Cut(key, "/")
+		target := "organizations." + orgName + ".users." + userName
+		if _, err := identity.CheckKeyPair(cert, l.keys[key]); err != nil {
+			step.add(StatusFail, target, err.Error())
+		} else {
+			step.add(StatusOK, target, "private key matches certificate")
+		}
+	}
+	if l.clientCert != nil && l.clientKey != nil {
+		if _, err := identity.CheckKeyPair(l.clientCert, l.clientKey); err != nil {
+			step.add(StatusFail, "client.tlsCerts.client", err.Error())
+		} else {
+			step.add(StatusOK, "client.tlsCerts.client", "private key matches certificate")
+		}
+	}
+	step.finish("all private keys match their certificates")
+	return step
+}
+
+// checkMSPIDs 检查组织的 MSP ID 不重复；channelMSPs 不为空时同时检查组织属于通道、用户证书由组织的 CA 签发
+func (l *lint) checkMSPIDs(channelMSPs map[string]*x509.CertPool) Step {
+	step := Step{Name: StepMSPID}
+	owners := map[string][]string{}
+	for _, name := range sortedKeys(l.m.Organizations) {
+		if id := l.m.Organizations[name].MSPID; id != "" {
+			owners[id] = append(owners[id], name)
+		}
+	}
+	for _, id := range sortedKeys(owners) {
+		if orgs := owners[id]; len(orgs) > 1 {
+			step.addf(StatusFail, "organizations", "organizations %s share mspid %s, each organization must have its own MSP ID", strings.Join(orgs, ", "), id)
+		}
+	}
+
+	if channelMSPs != nil {
+		for _, name := range sortedKeys(l.m.Organizations) {
+			org := l.m.Organizations[name]
+			target := "organizations." + name + ".mspid"
+			roots, ok := channelMSPs[org.MSPID]
+			if !ok {
+				step.addf(StatusFail, target, "mspid %s is not a member of the channel, channel MSPs are %s", org.MSPID, strings.Join(sortedKeys(channelMSPs), ", "))
+				continue
+			}
+			step.addf(StatusOK, target, "mspid %s is a member of the channel", org.MSPID)
+			for _, userName := range sortedKeys(org.Users) {
+				cert := l.certs[name+"/"+userName]
+				if cert == nil {
+					continue
+				}
+				if err := verifyCertificate(cert, roots); err != nil {
+					step.addf(StatusFail, "organizations."+name+".users."+userName, "certificate is not issued by a CA of %s: %v", org.MSPID, err)
+				}
+			}
+		}
+	}
+	step.finish("MSP IDs are consistent")
+	return step
+}
+
+// verifyCertificate 校验证书由 roots 中的 CA 签发
+func verifyCertificate(certPEM []byte, roots *x509.CertPool) error {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return errors.New("invalid certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	return err
+}
+
+// CertificateDetails 证书信息
+type CertificateDetails struct {
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	Serial      string    `json:"serial"`
+	KeyType     string    `json:"keyType"`
+	DNSNames    []string  `json:"dnsNames,omitempty"`
+	IPAddresses []string  `json:"ipAddresses,omitempty"`
+	NotBefore   time.Time `json:"notBefore"`
+	NotAfter    time.Time `json:"notAfter"`
+}
+
+func certificateDetails(cert *x509.Certificate) CertificateDetails {
+	d := CertificateDetails{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		Serial:    cert.SerialNumber.Text(16),
+		KeyType:   fmt.Sprintf("%T", cert.PublicKey),
+		DNSNames:  cert.DNSNames,
+		NotBefore: cert.NotBefore.UTC(),
+		NotAfter:  cert.NotAfter.UTC(),
+	}
+	if certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}); certPEM != nil {
+		if keyType, err := identity.CertificateKeyType(certPEM); err == nil {
+			d.KeyType = keyType
+		}
+	}
+	for _, ip := range cert.IPAddresses {
+		d.IPAddresses = append(d.IPAddresses, ip.String())
+	}
+	return d
+}
+
+// nodeAddress 节点地址的 host:port 与是否使用 TLS，省略协议时按 grpcOptions.allow-insecure 判断
+func nodeAddress(rawURL string, grpcOptions map[string]interface{}) (string, bool, error) {
+	u := rawURL
+	if !strings.Contains(u, "://") {
+		scheme := "grpcs://"
+		if grpcOptions["allow-insecure"] == true {
+			scheme = "grpc://"
+		}
+		u = scheme + u
+	}
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Port() == "" {
+		return "", false, fmt.Errorf("invalid url %s", rawURL)
+	}
+	return net.JoinHostPort(parsed.Hostname(), parsed.Port()), parsed.Scheme == "grpcs", nil
+}
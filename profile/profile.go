@@ -0,0 +1,387 @@
+// Package profile 生成 Fabric 连接配置。连接配置可以由简化的 JSON 描述（通道、组织、MSP ID、节点地址、TLS CA 证书、
+// 用户证书与私钥）生成，也可以由 cryptogen 或 Fabric CA 生成的 organizations 目录归档生成；证书与私钥以 PEM 内嵌在
+// 连接配置中，生成的连接配置不引用服务器上的文件。
+package profile
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/qctc/fabric2-api-server/identity"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalid 描述或归档不合法，无法生成连接配置
+var ErrInvalid = errors.New("invalid profile description")
+
+// Spec 连接配置的简化描述
+type Spec struct {
+	Channel       string         `json:"channel"`
+	Organization  string         `json:"organization,omitempty"` // 客户端所属组织，为空时使用第一个有 Peer 的组织
+	Organizations []Organization `json:"organizations"`
+	ClientTLS     *KeyPair       `json:"clientTLS,omitempty"` // 双向 TLS 的客户端证书
+}
+
+// Organization 组织，Peer 组织与 Orderer 组织使用同一结构
+type Organization struct {
+	Name     string `json:"name"`
+	MSPID    string `json:"mspId"`
+	Peers    []Node `json:"peers,omitempty"`
+	Orderers []Node `json:"orderers,omitempty"`
+	Users    []User `json:"users,omitempty"`
+}
+
+// Node Peer 或 Orderer 节点
+type Node struct {
+	Name string `json:"name"`
+	// URL 节点地址，grpcs:// 需要 TLSCACert，grpc:// 为明文连接；省略协议时按是否提供 TLSCACert 补全
+	URL       string `json:"url"`
+	TLSCACert string `json:"tlsCACert,omitempty"`
+	// ServerName TLS 校验的主机名，为空时使用节点名称
+	ServerName string `json:"serverName,omitempty"`
+}
+
+// User 组织用户，PrivateKey 为空时私钥由身份存储或 HSM 提供
+type User struct {
+	Name        string `json:"name"`
+	Certificate string `json:"certificate"`
+	PrivateKey  string `json:"privateKey,omitempty"`
+}
+
+// KeyPair 证书与私钥（PEM）
+type KeyPair struct {
+	Certificate string `json:"certificate"`
+	PrivateKey  string `json:"privateKey"`
+}
+
+// Build 校验描述并生成 YAML 格式的连接配置，描述中的全部问题合并在一个 ErrInvalid 错误中返回
+func Build(spec *Spec) ([]byte, error) {
+	clientOrg, problems := validate(spec)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, strings.Join(problems, "; "))
+	}
+
+	doc := document{
+		Version: "1.0.0",
+		Client: client{
+			Organization: clientOrg,
+			Logging:      logging{Level: "info"},
+		},
+		Channels:      map[string]channel{},
+		Organizations: map[string]organization{},
+		Peers:         map[string]node{},
+		Orderers:      map[string]node{},
+	}
+	if spec.ClientTLS != nil {
+		doc.Client.TLSCerts.Client = &keyPair{
+			Key:  &pemValue{PEM: spec.ClientTLS.PrivateKey},
+			Cert: &pemValue{PEM: spec.ClientTLS.Certificate},
+		}
+	}
+	ch := channel{Peers: map[string]channelPeer{}}
+	for _, org := range spec.Organizations {
+		o := organization{MSPID: org.MSPID}
+		for _, u := range org.Users {
+			if o.Users == nil {
+				o.Users = map[string]user{}
+			}
+			cfg := user{Cert: &pemValue{PEM: u.Certificate}}
+			if u.PrivateKey != "" {
+				cfg.Key = &pemValue{PEM: u.PrivateKey}
+			}
+			o.Users[u.Name] = cfg
+		}
+		for _, p := range org.Peers {
+			o.Peers = append(o.Peers, p.Name)
+			doc.Peers[p.Name] = newNode(p)
+			ch.Peers[p.Name] = channelPeer{EndorsingPeer: true, ChaincodeQuery: true, LedgerQuery: true, EventSource: true}
+		}
+		for _, n := range org.Orderers {
+			doc.Orderers[n.Name] = newNode(n)
+		}
+		doc.Organizations[org.Name] = o
+	}
+	doc.Channels[spec.Channel] = ch
+	return yaml.Marshal(&doc)
+}
+
+// validate 校验描述，返回客户端所属组织与发现的问题
+func validate(spec *Spec) (string, []string) {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	if strings.TrimSpace(spec.Channel) == "" {
+		addf("channel is required")
+	}
+	if len(spec.Organizations) == 0 {
+		addf("at least one organization is required")
+	}
+
+	// SDK 读取连接配置时不区分键的大小写
+	orgNames, mspIDs, nodeNames := map[string]bool{}, map[string]string{}, map[string]bool{}
+	var peers, orderers int
+	clientOrg := spec.Organization
+	for i, org := range spec.Organizations {
+		field := fmt.Sprintf("organizations[%d]", i)
+		switch key := strings.ToLower(org.Name); {
+		case org.Name == "":
+			addf("%s.name is required", field)
+		case orgNames[key]:
+			addf("%s.name %s is duplicated", field, org.Name)
+		default:
+			orgNames[key] = true
+			field = "organization " + org.Name
+		}
+		switch other, ok := mspIDs[org.MSPID]; {
+		case org.MSPID == "":
+			addf("%s mspId is required", field)
+		case ok:
+			addf("%s reuses mspId %s of %s, each organization must have its own MSP ID", field, org.MSPID, other)
+		default:
+			mspIDs[org.MSPID] = field
+		}
+		if clientOrg == "" && len(org.Peers) > 0 && org.Name != "" {
+			clientOrg = org.Name
+		}
+
+		for _, group := range []struct {
+			kind  string
+			nodes []Node
+		}{{"peer", org.Peers}, {"orderer", org.Orderers}} {
+			kind := group.kind
+			for j, n := range group.nodes {
+				nodeField := fmt.Sprintf("%s %ss[%d]", field, kind, j)
+				switch key := strings.ToLower(n.Name); {
+				case n.Name == "":
+					addf("%s.name is required", nodeField)
+					continue
+				case nodeNames[key]:
+					addf("%s %s is duplicated", kind, n.Name)
+					continue
+				default:
+					nodeNames[key] = true
+				}
+				nodeField = kind + " " + n.Name
+				if err := checkURL(n); err != nil {
+					addf("%s %v", nodeField, err)
+				}
+				if n.TLSCACert != "" {
+					if err := checkCertificates([]byte(n.TLSCACert)); err != nil {
+						addf("%s tlsCACert: %v", nodeField, err)
+					}
+				}
+			}
+		}
+		peers += len(org.Peers)
+		orderers += len(org.Orderers)
+
+		userNames := map[string]bool{}
+		for j, u := range org.Users {
+			userField := fmt.Sprintf("%s users[%d]", field, j)
+			if u.Name == "" {
+				addf("%s.name is required", userField)
+				continue
+			}
+			if userNames[strings.ToLower(u.Name)] {
+				addf("%s user %s is duplicated", field, u.Name)
+				continue
+			}
+			userNames[strings.ToLower(u.Name)] = true
+			userField = field + " user " + u.Name
+			if err := checkUser(u); err != nil {
+				addf("%s: %v", userField, err)
+			}
+		}
+	}
+	if peers == 0 {
+		addf("at least one peer is required")
+	}
+	if orderers == 0 {
+		addf("at least one orderer is required")
+	}
+	if spec.ClientTLS != nil {
+		if _, err := identity.CheckKeyPair([]byte(spec.ClientTLS.Certificate), []byte(spec.ClientTLS.PrivateKey)); err != nil {
+			addf("clientTLS: %v", err)
+		}
+	}
+
+	// 合约调用与查询默认以客户端所属组织的第一个用户签名
+	if clientOrg != "" {
+		org := findOrganization(spec.Organizations, clientOrg)
+		switch {
+		case org == nil:
+			addf("organization %s not found", clientOrg)
+		case len(org.Users) == 0:
+			addf("organization %s must have at least one user to sign transactions", clientOrg)
+		}
+	}
+	return clientOrg, problems
+}
+
+// checkURL 校验节点地址，省略协议时按是否提供 TLS CA 证书补全
+func checkURL(n Node) error {
+	if n.URL == "" {
+		return errors.New("url is required")
+	}
+	u, err := url.Parse(nodeURL(n))
+	if err != nil || u.Host == "" || u.Port() == "" {
+		return fmt.Errorf("url %s must be host:port with optional grpc:// or grpcs:// scheme", n.URL)
+	}
+	switch u.Scheme {
+	case "grpcs":
+		if n.TLSCACert == "" {
+			return errors.New("tlsCACert is required for grpcs url")
+		}
+	case "grpc":
+	default:
+		return fmt.Errorf("url scheme %s is not supported, must be grpc or grpcs", u.Scheme)
+	}
+	return nil
+}
+
+// nodeURL 补全节点地址的协议
+func nodeURL(n Node) string {
+	if strings.Contains(n.URL, "://") {
+		return n.URL
+	}
+	if n.TLSCACert != "" {
+		return "grpcs://" + n.URL
+	}
+	return "grpc://" + n.URL
+}
+
+// checkCertificates 校验 PEM 中至少包含一个证书且全部可以解析
+func checkCertificates(data []byte) error {
+	var found bool
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		// 国密 x509 同时支持 SM2 与 ECDSA 证书
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return fmt.Errorf("invalid certificate: %v", err)
+		}
+		found = true
+	}
+	if !found {
+		return errors.New("no PEM encoded CERTIFICATE found")
+	}
+	return nil
+}
+
+// checkUser 校验用户证书，提供私钥时同时校验私钥与证书匹配
+func checkUser(u User) error {
+	if u.Certificate == "" {
+		return errors.New("certificate is required")
+	}
+	if u.PrivateKey == "" {
+		_, err := identity.CertificateKeyType([]byte(u.Certificate))
+		return err
+	}
+	_, err := identity.CheckKeyPair([]byte(u.Certificate), []byte(u.PrivateKey))
+	return err
+}
+
+func findOrganization(orgs []Organization, name string) *Organization {
+	for i := range orgs {
+		if strings.EqualFold(orgs[i].Name, name) {
+			return &orgs[i]
+		}
+	}
+	return nil
+}
+
+func newNode(n Node) node {
+	serverName := n.ServerName
+	if serverName == "" {
+		serverName = n.Name
+	}
+	cfg := node{
+		URL: nodeURL(n),
+		GRPCOptions: grpcOptions{
+			ServerName:    serverName,
+			FailFast:      false,
+			AllowInsecure: n.TLSCACert == "",
+		},
+	}
+	if n.TLSCACert != "" {
+		cfg.TLSCACerts = &pemValue{PEM: n.TLSCACert}
+	}
+	return cfg
+}
+
+// 以下结构对应 SDK 连接配置中用到的部分，YAML 中 map 的键按字典序输出
+type document struct {
+	Version       string                  `yaml:"version"`
+	Client        client                  `yaml:"client"`
+	Channels      map[string]channel      `yaml:"channels"`
+	Organizations map[string]organization `yaml:"organizations"`
+	Orderers      map[string]node         `yaml:"orderers"`
+	Peers         map[string]node         `yaml:"peers"`
+}
+
+type client struct {
+	Organization string  `yaml:"organization"`
+	Logging      logging `yaml:"logging"`
+	TLSCerts     struct {
+		SystemCertPool bool     `yaml:"systemCertPool"`
+		Client         *keyPair `yaml:"client,omitempty"`
+	} `yaml:"tlsCerts"`
+}
+
+type logging struct {
+	Level string `yaml:"level"`
+}
+
+type keyPair struct {
+	Key  *pemValue `yaml:"key"`
+	Cert *pemValue `yaml:"cert"`
+}
+
+type pemValue struct {
+	PEM string `yaml:"pem"`
+}
+
+type channel struct {
+	Peers map[string]channelPeer `yaml:"peers"`
+}
+
+type channelPeer struct {
+	EndorsingPeer  bool `yaml:"endorsingPeer"`
+	ChaincodeQuery bool `yaml:"chaincodeQuery"`
+	LedgerQuery    bool `yaml:"ledgerQuery"`
+	EventSource    bool `yaml:"eventSource"`
+}
+
+type organization struct {
+	MSPID string          `yaml:"mspid"`
+	Users map[string]user `yaml:"users,omitempty"`
+	Peers []string        `yaml:"peers,omitempty"`
+}
+
+type user struct {
+	Cert *pemValue `yaml:"cert"`
+	Key  *pemValue `yaml:"key,omitempty"`
+}
+
+type node struct {
+	URL         string      `yaml:"url"`
+	GRPCOptions grpcOptions `yaml:"grpcOptions"`
+	TLSCACerts  *pemValue   `yaml:"tlsCACerts,omitempty"`
+}
+
+type grpcOptions struct {
+	ServerName    string `yaml:"ssl-target-name-override"`
+	FailFast      bool   `yaml:"fail-fast"`
+	AllowInsecure bool   `yaml:"allow-insecure"`
+}
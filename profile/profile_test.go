@@ -0,0 +1,221 @@
+package profile
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	fabImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab"
+)
+
+func TestBuild(t *testing.T) {
+	tlsCA, _ := newCert(t, "tlsca.example.com")
+	adminCert, adminKey := newCert(t, "Admin@org1.example.com")
+	spec := &Spec{
+		Channel: "mychannel",
+		Organizations: []Organization{
+			{
+				Name:  "org1",
+				MSPID: "Org1MSP",
+				Peers: []Node{{Name: "peer0.org1.example.com", URL: "grpcs://192.168.7.12:7051", TLSCACert: tlsCA}},
+				Users: []User{{Name: "Admin", Certificate: adminCert, PrivateKey: adminKey}},
+			},
+			{
+				Name:     "ordererorg",
+				MSPID:    "OrdererMSP",
+				Orderers: []Node{{Name: "orderer.example.com", URL: "orderer.example.com:7050"}},
+			},
+		},
+	}
+	data, err := Build(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 生成的连接配置由 SDK 解析
+	backends, err := config.FromRaw(data, "yaml")()
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoints, err := fabImpl.ConfigFromBackend(backends...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	network := endpoints.NetworkConfig()
+	if org := network.Organizations["org1"]; org.MSPID != "Org1MSP" || len(org.Peers) != 1 {
+		t.Errorf("org1 = %+v", org)
+	}
+	if org := network.Organizations["ordererorg"]; org.MSPID != "OrdererMSP" {
+		t.Errorf("ordererorg = %+v", org)
+	}
+	peer, ok := endpoints.PeerConfig("peer0.org1.example.com")
+	if !ok || peer.URL != "grpcs://192.168.7.12:7051" || peer.GRPCOptions["ssl-target-name-override"] != "peer0.org1.example.com" {
+		t.Errorf("peer = %+v", peer)
+	}
+	orderer, ok, _ := endpoints.OrdererConfig("orderer.example.com")
+	if !ok || orderer.URL != "grpc://orderer.example.com:7050" || orderer.GRPCOptions["allow-insecure"] != true {
+		t.Errorf("orderer = %+v", orderer)
+	}
+	if peers := endpoints.ChannelPeers("mychannel"); len(peers) != 1 || !peers[0].EndorsingPeer {
+		t.Errorf("channel peers = %+v", peers)
+	}
+	if !strings.Contains(string(data), "organization: org1") {
+		t.Errorf("client organization not set:\n%s", data)
+	}
+}
+
+func TestBuildProblems(t *testing.T) {
+	cert, key := newCert(t, "Admin@org1.example.com")
+	_, otherKey := newCert(t, "User1@org1.example.com")
+	spec := &Spec{
+		Organizations: []Organization{
+			{
+				Name:  "org1",
+				MSPID: "orgMSP",
+				Peers: []Node{{Name: "peer0", URL: "grpcs://peer0:7051"}},
+				Users: []User{{Name: "Admin", Certificate: cert, PrivateKey: key}, {Name: "User1", Certificate: cert, PrivateKey: otherKey}},
+			},
+			{Name: "ordererorg", MSPID: "orgMSP", Orderers: []Node{{Name: "orderer", URL: "orderer"}}},
+		},
+	}
+	_, err := Build(spec)
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("err = %v", err)
+	}
+	for _, want := range []string{
+		"channel is required",
+		"organization ordererorg reuses mspId orgMSP of organization org1",
+		"peer peer0 tlsCACert is required for grpcs url",
+		"orderer orderer url orderer must be host:port",
+		"organization org1 user User1: invalid identity: private key does not match certificate",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "user Admin") {
+		t.Errorf("unexpected problem for Admin: %v", err)
+	}
+}
+
+func TestFromArchive(t *testing.T) {
+	peerTLS, _ := newCert(t, "tlsca.org1.example.com")
+	ordererTLS, _ := newCert(t, "tlsca.example.com")
+	adminCert, adminKey := newCert(t, "Admin@org1.example.com")
+	userCert, userKey := newCert(t, "User1@org1.example.com")
+	_, staleKey := newCert(t, "User1@org1.example.com")
+	ordererAdmin, ordererAdminKey := newCert(t, "Admin@example.com")
+
+	archive := newArchive(t, map[string]string{
+		"organizations/peerOrganizations/org1.example.com/peers/peer0.org1.example.com/tls/ca.crt":                                    peerTLS,
+		"organizations/peerOrganizations/org1.example.com/peers/peer0.org1.example.com/tls/server.key":                                "ignored",
+		"organizations/peerOrganizations/org1.example.com/peers/peer1.org1.example.com/msp/config.yaml":                               "NodeOUs:",
+		"organizations/peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem":                                      peerTLS,
+		"organizations/peerOrganizations/org1.example.com/users/User1@org1.example.com/msp/signcerts/cert.pem":                        userCert,
+		"organizations/peerOrganizations/org1.example.com/users/User1@org1.example.com/msp/keystore/a_sk":                             staleKey,
+		"organizations/peerOrganizations/org1.example.com/users/User1@org1.example.com/msp/keystore/b_sk":                             userKey,
+		"organizations/peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp/signcerts/Admin@org1.example.com-cert.pem": adminCert,
+		"organizations/peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp/keystore/priv_sk":                          adminKey,
+		"organizations/ordererOrganizations/example.com/orderers/orderer.example.com/tls/ca.crt":                                      ordererTLS,
+		"organizations/ordererOrganizations/example.com/users/Admin@example.com/msp/signcerts/cert.pem":                               ordererAdmin,
+		"organizations/ordererOrganizations/example.com/users/Admin@example.com/msp/keystore/priv_sk":                                 ordererAdminKey,
+	})
+	spec, err := FromArchive(bytes.NewReader(archive), ArchiveOptions{
+		Channel:   "mychannel",
+		Endpoints: map[string]string{"orderer.example.com": "grpcs://10.0.0.1:7050"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.Organizations) != 2 {
+		t.Fatalf("organizations = %+v", spec.Organizations)
+	}
+	org1, orderer := spec.Organizations[0], spec.Organizations[1]
+	if org1.Name != "org1" || org1.MSPID != "Org1MSP" || len(org1.Peers) != 1 || org1.Peers[0].URL != "peer0.org1.example.com:7051" {
+		t.Errorf("org1 = %+v", org1)
+	}
+	// peer1 没有 tls/ca.crt，不是节点目录
+	if len(org1.Users) != 2 || org1.Users[0].Name != "Admin" || org1.Users[1].Name != "User1" || org1.Users[1].PrivateKey != userKey {
+		t.Errorf("org1 users = %+v", org1.Users)
+	}
+	if orderer.Name != "example" || orderer.MSPID != "OrdererMSP" || len(orderer.Orderers) != 1 || orderer.Orderers[0].URL != "grpcs://10.0.0.1:7050" {
+		t.Errorf("orderer org = %+v", orderer)
+	}
+	if _, err := Build(spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := FromArchive(bytes.NewReader(archive[:len(archive)/2]), ArchiveOptions{}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("truncated archive err = %v", err)
+	}
+	empty := newArchive(t, map[string]string{"crypto/README": "nothing"})
+	if _, err := FromArchive(bytes.NewReader(empty), ArchiveOptions{}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("empty archive err = %v", err)
+	}
+}
+
+// newCert 生成 ECDSA 自签名证书与 PKCS#8 私钥（PEM）
+func newCert(t *testing.T, cn string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+}
+
+// newArchive 生成 tar.gz 归档
+func newArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,54 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/qctc/fabric2-api-server/identity"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// Registered 已加入连接池的连接配置，后续请求以 SdkConfig 或 SdkId 引用
+type Registered struct {
+	SdkId     string `json:"sdkId"`
+	SdkConfig string `json:"sdkConfig"`
+	// StoredIdentities 私钥已保存到身份存储的用户（<MSP ID>/<用户>），连接配置中只保留其证书
+	StoredIdentities []string `json:"storedIdentities,omitempty"`
+}
+
+// Register 生成连接配置并初始化 SDK 加入连接池。启用身份存储时用户私钥保存到身份存储，不写入连接配置
+func Register(spec *Spec, gm, sm3 bool) (*Registered, error) {
+	if _, err := Build(spec); err != nil {
+		return nil, err
+	}
+	result := &Registered{}
+	if identity.Default != nil {
+		stored := *spec
+		stored.Organizations = make([]Organization, len(spec.Organizations))
+		for i, org := range spec.Organizations {
+			org.Users = append([]User(nil), org.Users...)
+			for j, u := range org.Users {
+				if u.PrivateKey == "" {
+					continue
+				}
+				if _, err := identity.Default.Put(org.MSPID, u.Name, []byte(u.Certificate), []byte(u.PrivateKey)); err != nil {
+					return nil, fmt.Errorf("store identity %s/%s: %w", org.MSPID, u.Name, err)
+				}
+				org.Users[j].PrivateKey = ""
+				result.StoredIdentities = append(result.StoredIdentities, org.MSPID+"/"+u.Name)
+			}
+			stored.Organizations[i] = org
+		}
+		spec = &stored
+	}
+
+	config, err := Build(spec)
+	if err != nil {
+		return nil, err
+	}
+	result.SdkConfig = string(config)
+	result.SdkId = utils.SdkId(result.SdkConfig)
+	if err, _ := utils.InitializeSDKBySdkId(result.SdkConfig, gm, sm3); err != nil {
+		return nil, fmt.Errorf("%w: sdk initialize error %v", ErrInvalid, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,294 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/define"
+	"golang.org/x/time/rate"
+)
+
+// Dimension 限流维度
+type Dimension string
+
+const (
+	// DimensionCaller 调用方
+	DimensionCaller Dimension = "caller"
+	// DimensionProfile 连接配置（sdkId）
+	DimensionProfile Dimension = "profile"
+	// DimensionChaincode 连接配置下的合约
+	DimensionChaincode Dimension = "chaincode"
+)
+
+const (
+	// inFlightRetryAfter 等待并发名额超时后建议的重试间隔，无法预知名额何时释放
+	inFlightRetryAfter = time.Second
+	// idleBucketTTL 配额空闲超过该时间后回收，避免调用方与配置过多时无限增长
+	idleBucketTTL = 10 * time.Minute
+)
+
+// Default 服务使用的限流器，为 nil 时不限流；订阅补齐历史区块时与接口共用连接配置的配额
+var Default *Limiter
+
+// Key 限流对象，同一维度下按 Value 分别计算配额
+type Key struct {
+	Dimension Dimension
+	Value     string
+}
+
+// ProfileKey 连接配置的限流对象
+func ProfileKey(sdkId string) Key {
+	return Key{Dimension: DimensionProfile, Value: sdkId}
+}
+
+// ChaincodeKey 连接配置下合约的限流对象
+func ChaincodeKey(sdkId, chaincode string) Key {
+	return Key{Dimension: DimensionChaincode, Value: sdkId + "/" + chaincode}
+}
+
+// LimitError 排队时间内无法获得令牌或并发名额
+type LimitError struct {
+	Key        Key
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s %s", e.Key.Dimension, e.Key.Value)
+}
+
+var errQueueTimeout = errors.New("queue timeout")
+
+type bucket struct {
+	tokens   *rate.Limiter // 为 nil 时不限速率
+	slots    chan struct{} // 为 nil 时不限并发
+	lastUsed time.Time
+}
+
+// Limiter 按维度分别维护令牌桶与并发名额，为 nil 时不限流
+type Limiter struct {
+	queueTimeout time.Duration
+	limits       map[Dimension]define.LimitConfig
+
+	mu        sync.Mutex
+	buckets   map[Key]*bucket
+	lastSweep time.Time
+}
+
+// New 按配置构造限流器，未启用时返回 nil
+func New(cfg define.RateLimitConfig) (*Limiter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	limits, err := parseLimits(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Limiter{
+		queueTimeout: cfg.QueueTimeout,
+		limits:       limits,
+		buckets:      make(map[Key]*bucket),
+	}, nil
+}
+
+// Reload 按新配置替换配额，已有的令牌桶与并发名额被丢弃，进行中的请求仍归还到原来的名额；
+// 新配置未启用时不再限流。限流器为 nil（启动时未启用）时需重启才能启用
+func (l *Limiter) Reload(cfg define.RateLimitConfig) error {
+	if l == nil {
+		if cfg.Enabled {
+			return errors.New("rate limiting was disabled at startup, restart to enable it")
+		}
+		return nil
+	}
+	limits := map[Dimension]define.LimitConfig{}
+	if cfg.Enabled {
+		var err error
+		if limits, err = parseLimits(cfg); err != nil {
+			return err
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queueTimeout = cfg.QueueTimeout
+	l.limits = limits
+	l.buckets = make(map[Key]*bucket)
+	return nil
+}
+
+// parseLimits 校验配置并返回各维度的配额，未指定 burst 时取 rate 向上取整
+func parseLimits(cfg define.RateLimitConfig) (map[Dimension]define.LimitConfig, error) {
+	if cfg.QueueTimeout < 0 {
+		return nil, errors.New("queueTimeout must not be negative")
+	}
+	limits := map[Dimension]define.LimitConfig{
+		DimensionCaller:    cfg.Caller,
+		DimensionProfile:   cfg.Profile,
+		DimensionChaincode: cfg.Chaincode,
+	}
+	for d, limit := range limits {
+		if limit.Rate < 0 || limit.Burst < 0 || limit.MaxInFlight < 0 {
+			return nil, fmt.Errorf("rate limit %s: rate, burst and maxInFlight must not be negative", d)
+		}
+		if limit.Rate > 0 && limit.Burst == 0 {
+			limit.Burst = int(math.Ceil(limit.Rate))
+			limits[d] = limit
+		}
+	}
+	return limits, nil
+}
+
+// Acquire 获取全部维度的令牌与并发名额，最多排队 queueTimeout；成功后调用方必须调用 release 归还并发名额
+func (l *Limiter) Acquire(ctx context.Context, keys ...Key) (release func(), err error) {
+	return l.acquire(ctx, keys, true, true)
+}
+
+// Take 只获取令牌不占用并发名额，用于长连接的事件流
+func (l *Limiter) Take(ctx context.Context, keys ...Key) error {
+	_, err := l.acquire(ctx, keys, true, false)
+	return err
+}
+
+// Wait 与 Acquire 相同但不限排队时间，直到获得名额或 ctx 结束，用于后台任务
+func (l *Limiter) Wait(ctx context.Context, keys ...Key) (release func(), err error) {
+	return l.acquire(ctx, keys, false, true)
+}
+
+func (l *Limiter) acquire(ctx context.Context, keys []Key, bounded, hold bool) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	now := time.Now()
+	buckets, queueTimeout := l.lookup(keys, now)
+
+	// 先预约全部维度的令牌，任一维度等待时间超过排队时间则取消已有预约
+	var reservations []*rate.Reservation
+	cancel := func() {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+	var delay time.Duration
+	for i, b := range buckets {
+		if b == nil || b.tokens == nil {
+			continue
+		}
+		r := b.tokens.ReserveN(now, 1)
+		reservations = append(reservations, r)
+		d := r.DelayFrom(now)
+		if bounded && d > queueTimeout {
+			cancel()
+			return nil, &LimitError{Key: keys[i], RetryAfter: d}
+		}
+		if d > delay {
+			delay = d
+		}
+	}
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		}
+	}
+	if !hold {
+		return func() {}, nil
+	}
+
+	// 再按固定顺序占用并发名额，避免相互等待
+	deadline := now.Add(queueTimeout)
+	var held []*bucket
+	release := func() {
+		for _, b := range held {
+			<-b.slots
+		}
+	}
+	for i, b := range buckets {
+		if b == nil || b.slots == nil {
+			continue
+		}
+		if err := take(ctx, b.slots, bounded, deadline); err != nil {
+			release()
+			if errors.Is(err, errQueueTimeout) {
+				return nil, &LimitError{Key: keys[i], RetryAfter: inFlightRetryAfter}
+			}
+			return nil, err
+		}
+		held = append(held, b)
+	}
+	var once sync.Once
+	return func() { once.Do(release) }, nil
+}
+
+// take 占用一个并发名额，bounded 时最多等待到 deadline
+func take(ctx context.Context, slots chan struct{}, bounded bool, deadline time.Time) error {
+	select {
+	case slots <- struct{}{}:
+		return nil
+	default:
+	}
+	var timeout <-chan time.Time
+	if bounded {
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return errQueueTimeout
+		}
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return errQueueTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// lookup 返回各限流对象的配额与当前的排队时间，未配置限制的维度或值为空时对应位置为 nil
+func (l *Limiter) lookup(keys []Key, now time.Time) ([]*bucket, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	buckets := make([]*bucket, len(keys))
+	for i, key := range keys {
+		limit := l.limits[key.Dimension]
+		if key.Value == "" || (limit.Rate == 0 && limit.MaxInFlight == 0) {
+			continue
+		}
+		b, ok := l.buckets[key]
+		if !ok {
+			b = &bucket{}
+			if limit.Rate > 0 {
+				b.tokens = rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)
+			}
+			if limit.MaxInFlight > 0 {
+				b.slots = make(chan struct{}, limit.MaxInFlight)
+			}
+			l.buckets[key] = b
+		}
+		b.lastUsed = now
+		buckets[i] = b
+	}
+	return buckets, l.queueTimeout
+}
+
+// sweep 回收空闲且没有进行中请求的配额，每分钟最多执行一次
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.lastUsed) > idleBucketTTL && len(b.slots) == 0 {
+			delete(l.buckets, key)
+		}
+	}
+}
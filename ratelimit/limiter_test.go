@@ -0,0 +1,179 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+func TestAcquireRate(t *testing.T) {
+	l, err := New(define.RateLimitConfig{
+		Enabled: true,
+		Caller:  define.LimitConfig{Rate: 1, Burst: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := Key{Dimension: DimensionCaller, Value: "alice"}
+	for i := 0; i < 2; i++ {
+		if _, err := l.Acquire(context.Background(), key); err != nil {
+			t.Fatalf("request %d within burst: %v", i, err)
+		}
+	}
+	_, err = l.Acquire(context.Background(), key)
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Acquire() error = %v, want LimitError", err)
+	}
+	if limitErr.RetryAfter <= 0 || limitErr.RetryAfter > time.Second {
+		t.Errorf("RetryAfter = %s", limitErr.RetryAfter)
+	}
+	// 其他调用方不受影响
+	if _, err := l.Acquire(context.Background(), Key{Dimension: DimensionCaller, Value: "bob"}); err != nil {
+		t.Errorf("other caller limited: %v", err)
+	}
+}
+
+func TestAcquireInFlight(t *testing.T) {
+	l, err := New(define.RateLimitConfig{
+		Enabled:      true,
+		QueueTimeout: 50 * time.Millisecond,
+		Profile:      define.LimitConfig{MaxInFlight: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := ProfileKey("sdk")
+	release, err := l.Acquire(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 排队超时
+	start := time.Now()
+	if _, err := l.Acquire(context.Background(), key); !errors.As(err, new(*LimitError)) {
+		t.Fatalf("Acquire() error = %v, want LimitError", err)
+	}
+	if waited := time.Since(start); waited < 50*time.Millisecond {
+		t.Errorf("rejected after %s, want to queue for queueTimeout", waited)
+	}
+
+	// 排队期间释放名额
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	next, err := l.Acquire(context.Background(), key)
+	if err != nil {
+		t.Fatalf("queued request not admitted: %v", err)
+	}
+	next()
+	next()
+
+	// 后台任务不受排队时间限制，ctx 结束时返回
+	hold, _ := l.Acquire(context.Background(), key)
+	defer hold()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := l.Wait(ctx, key); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want context deadline", err)
+	}
+}
+
+func TestLimitMiddleware(t *testing.T) {
+	l, err := New(define.RateLimitConfig{
+		Enabled:   true,
+		Chaincode: define.LimitConfig{Rate: 0.5, Burst: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := l.Limit(nil, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	call := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/contract/call", strings.NewReader(body)))
+		return w
+	}
+
+	if w := call(`{"sdkId":"a","chaincodeName":"basic"}`); w.Code != http.StatusOK {
+		t.Fatalf("first request status = %d", w.Code)
+	}
+	w := call(`{"sdkId":"a","chaincodeName":"basic"}`)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+	if !strings.Contains(w.Body.String(), `"RATE_LIMITED"`) {
+		t.Errorf("body = %s", w.Body.String())
+	}
+	if w := call(`{"sdkId":"a","chaincodeName":"other"}`); w.Code != http.StatusOK {
+		t.Errorf("other chaincode status = %d", w.Code)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	l, err := New(define.RateLimitConfig{})
+	if err != nil || l != nil {
+		t.Fatalf("New() = %v, %v, want nil limiter", l, err)
+	}
+	release, err := l.Wait(context.Background(), ProfileKey("sdk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+}
+
+func TestReload(t *testing.T) {
+	l, err := New(define.RateLimitConfig{
+		Enabled: true,
+		Caller:  define.LimitConfig{Rate: 1, Burst: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := Key{Dimension: DimensionCaller, Value: "alice"}
+	if _, err := l.Acquire(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Acquire(context.Background(), key); !errors.As(err, new(*LimitError)) {
+		t.Fatalf("Acquire() error = %v, want LimitError", err)
+	}
+
+	// 提高配额后立即生效
+	if err := l.Reload(define.RateLimitConfig{Enabled: true, Caller: define.LimitConfig{Rate: 10, Burst: 5}}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := l.Acquire(context.Background(), key); err != nil {
+			t.Fatalf("request %d after reload: %v", i, err)
+		}
+	}
+
+	// 关闭后不再限流
+	if err := l.Reload(define.RateLimitConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := l.Acquire(context.Background(), key); err != nil {
+			t.Fatalf("request %d after disabling: %v", i, err)
+		}
+	}
+
+	if err := l.Reload(define.RateLimitConfig{Enabled: true, QueueTimeout: -time.Second}); err == nil {
+		t.Error("invalid config accepted")
+	}
+	var disabled *Limiter
+	if err := disabled.Reload(define.RateLimitConfig{Enabled: true}); err == nil {
+		t.Error("enabling a limiter created disabled should require restart")
+	}
+}
@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// CallerFunc 识别请求的调用方，返回空字符串时按客户端地址区分
+type CallerFunc func(r *http.Request) string
+
+// requestFields 请求中与限流维度相关的字段
+type requestFields struct {
+	SdkConfig     string `json:"sdkConfig"`
+	SdkId         string `json:"sdkId"`
+	ChaincodeName string `json:"chaincodeName"`
+}
+
+// Limit 请求处理期间占用调用方、连接配置与合约的并发名额，超出配额且排队超时时返回 429
+func (l *Limiter) Limit(caller CallerFunc, next http.HandlerFunc) http.Handler {
+	return l.middleware(caller, next, true)
+}
+
+// Stream 事件流只在建立连接时消耗令牌，连接期间不占用并发名额；补齐历史区块由订阅按连接配置排队
+func (l *Limiter) Stream(caller CallerFunc, next http.HandlerFunc) http.Handler {
+	return l.middleware(caller, next, false)
+}
+
+func (l *Limiter) middleware(caller CallerFunc, next http.HandlerFunc, hold bool) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys := requestKeys(r, caller)
+		release, err := l.acquire(r.Context(), keys, true, hold)
+		if err != nil {
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) {
+				// 排队期间请求超时时返回 504，客户端断开时无需响应
+				if errors.Is(err, context.DeadlineExceeded) {
+					utils.Error(w, http.StatusGatewayTimeout, "request timed out while queued", nil)
+				}
+				return
+			}
+			slog.WarnContext(r.Context(), "rate limited", "dimension", limitErr.Key.Dimension, "key", limitErr.Key.Value,
+				"path", r.URL.Path, "retry_after", limitErr.RetryAfter.String())
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(limitErr.RetryAfter)))
+			utils.Error(w, http.StatusTooManyRequests, err.Error(), nil)
+			return
+		}
+		defer release()
+		next(w, r)
+	})
+}
+
+// requestKeys 从认证身份、请求体与查询参数中确定限流对象，请求体无法解析时只按调用方限流
+func requestKeys(r *http.Request, caller CallerFunc) []Key {
+	name := ""
+	if caller != nil {
+		name = caller(r)
+	}
+	if name == "" {
+		name = clientAddr(r)
+	}
+	var fields requestFields
+	if err := utils.PeekJSON(r, &fields); err != nil {
+		return Keys(name, "", "")
+	}
+	q := r.URL.Query()
+	if fields.SdkId == "" {
+		fields.SdkId = q.Get("sdkId")
+	}
+	if fields.ChaincodeName == "" {
+		fields.ChaincodeName = q.Get("chaincodeName")
+	}
+	profile := fields.SdkId
+	if fields.SdkConfig != "" {
+		profile = utils.SdkId(fields.SdkConfig)
+	}
+	return Keys(name, profile, fields.ChaincodeName)
+}
+
+// Keys 按调用方、连接配置（sdkId）与合约确定限流对象，值为空的维度不限流
+func Keys(caller, profile, chaincode string) []Key {
+	keys := []Key{{Dimension: DimensionCaller, Value: caller}}
+	if profile == "" {
+		return keys
+	}
+	keys = append(keys, ProfileKey(profile))
+	if chaincode != "" {
+		keys = append(keys, ChaincodeKey(profile, chaincode))
+	}
+	return keys
+}
+
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// retryAfterSeconds Retry-After 以秒为单位，向上取整且至少为 1
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -1,42 +1,132 @@
 package router
 
 import (
+	"log"
+	"net/http"
+
 	"github.com/gorilla/mux"
 
+	"github.com/qctc/fabric2-api-server/api"
+	"github.com/qctc/fabric2-api-server/auth"
 	"github.com/qctc/fabric2-api-server/controller"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/logging"
+	"github.com/qctc/fabric2-api-server/metrics"
+	"github.com/qctc/fabric2-api-server/middleware"
+	"github.com/qctc/fabric2-api-server/ratelimit"
+	"github.com/qctc/fabric2-api-server/tracing"
 )
 
-func SetUpRouter() *mux.Router {
+// SetUpRouter 注册 REST 接口，guard 为 nil 时不认证
+func SetUpRouter(guard *auth.Guard) *mux.Router {
 	router := mux.NewRouter()
 
+	// 链路追踪在最外层，请求日志可以带上 trace_id；请求字段在校验之前记录，校验失败的请求也能定位
+	router.Use(tracing.Middleware, logging.Middleware, metrics.Middleware, middleware.LogRequestFields)
+
+	// 按 OpenAPI 文档校验请求
+	validate, err := middleware.ValidateRequests(api.Spec)
+	if err != nil {
+		log.Fatalf("加载接口文档失败: %v", err)
+	}
+	router.Use(validate)
+
+	// 限流与并发配额，ratelimit.Default 为 nil 时不限流；在认证之后执行，以便按调用方计算配额
+	limiter := ratelimit.Default
+	limit := func(next http.HandlerFunc) http.HandlerFunc {
+		return limiter.Limit(auth.Caller, next).ServeHTTP
+	}
+	stream := func(next http.HandlerFunc) http.HandlerFunc {
+		return limiter.Stream(auth.Caller, next).ServeHTTP
+	}
+	// 请求超时在认证与排队之前设置，截止时间覆盖整个请求；事件流等长连接不设置
+	serverConfig := define.GlobalConfig.Server
+	deadline := middleware.Timeout(serverConfig.RequestTimeout, serverConfig.MaxRequestTimeout)
+
+	// 接口文档
+	router.HandleFunc("/api/v1/openapi.yaml", controller.GetOpenAPI).Methods("GET")
+	// 存活与就绪检查，供编排系统探测，不需要认证；就绪检查的详细结果需要 manage 权限
+	router.HandleFunc("/healthz", controller.Healthz).Methods("GET")
+	router.Handle("/readyz", guard.Optional(controller.Readyz)).Methods("GET")
+	// Prometheus 指标
+	router.Handle("/metrics", guard.Require(auth.ActionManage, metrics.Handler().ServeHTTP)).Methods("GET")
+
 	// 配置相关
 	//router.HandleFunc("/api/v1/config/init", controller.InitSdkConfig).Methods("POST")
 	//router.HandleFunc("/api/v1/service/instantiate", controller.InstantiateService).Methods("POST")
 
 	// 连接相关
-	router.HandleFunc("/api/v1/connect/test", controller.TestConnection).Methods("POST")
+	router.Handle("/api/v1/connect/test", deadline(guard.Require(auth.ActionRead, limit(controller.TestConnection)))).Methods("POST")
+	// 生成连接配置
+	router.Handle("/api/v1/profiles", deadline(guard.Require(auth.ActionManage, controller.CreateProfile))).Methods("POST")
+	router.Handle("/api/v1/profiles/import", deadline(guard.Require(auth.ActionManage, controller.ImportProfile))).Methods("POST")
+	router.Handle("/api/v1/profiles/diagnose", deadline(guard.Require(auth.ActionRead, limit(controller.DiagnoseProfile)))).Methods("POST")
 	// 合约相关
-	router.HandleFunc("/api/v1/contract/list", controller.GetContractList).Methods("POST")
+	router.Handle("/api/v1/contract/list", deadline(guard.Require(auth.ActionRead, limit(controller.GetContractList)))).Methods("POST")
 	// 调用智能合约
-	router.HandleFunc("/api/v1/contract/sendTransaction", controller.InvokeContract).Methods("POST")
+	router.Handle("/api/v1/contract/sendTransaction", deadline(guard.Require(auth.ActionInvoke, limit(controller.InvokeContract)))).Methods("POST")
 
 	// 查询智能合约
-	router.HandleFunc("/api/v1/contract/call", controller.QueryContract).Methods("POST")
+	router.Handle("/api/v1/contract/call", deadline(guard.Require(auth.ActionQuery, limit(controller.QueryContract)))).Methods("POST")
+
+	// 离线签名：调用方持有私钥，依次创建提案、提交签名收集背书、提交签名后的交易
+	router.Handle("/api/v1/contract/proposal", deadline(guard.Require(auth.ActionInvoke, limit(controller.CreateProposal)))).Methods("POST")
+	// 背书与提交的合约和方法在提案或交易中，由处理函数解析后授权
+	router.Handle("/api/v1/contract/endorse", deadline(guard.Authenticated(limit(controller.EndorseProposal)))).Methods("POST")
+	router.Handle("/api/v1/contract/submit", deadline(guard.Authenticated(limit(controller.SubmitTransaction)))).Methods("POST")
 
 	//获取合约信息
-	router.HandleFunc("/api/v1/contract/info", controller.GetContractInfo).Methods("POST")
+	router.Handle("/api/v1/contract/info", deadline(guard.Require(auth.ActionRead, limit(controller.GetContractInfo)))).Methods("POST")
 
 	//获取区块信息
-	router.HandleFunc("/api/v1/block/info", controller.GetBlockInfo).Methods("POST")
+	router.Handle("/api/v1/block/info", deadline(guard.Require(auth.ActionRead, limit(controller.GetBlockInfo)))).Methods("POST")
 
 	//获取交易信息
-	router.HandleFunc("/api/v1/transaction/info", controller.GetTransactionInfo).Methods("POST")
+	router.Handle("/api/v1/transaction/info", deadline(guard.Require(auth.ActionRead, limit(controller.GetTransactionInfo)))).Methods("POST")
 
 	//订阅合约事件
-	router.HandleFunc("/api/v1/contract/subscribe", controller.SubscribeContractEvent).Methods("POST")
+	router.Handle("/api/v1/contract/subscribe", deadline(guard.Require(auth.ActionSubscribe, limit(controller.SubscribeContractEvent)))).Methods("POST")
 
 	//取消订阅合约事件
-	router.HandleFunc("/api/v1/contract/unsubscribe", controller.UnsubscribeContractEvent).Methods("POST")
+	router.Handle("/api/v1/contract/unsubscribe", deadline(guard.Require(auth.ActionSubscribe, controller.UnsubscribeContractEvent))).Methods("POST")
+
+	// 事件流推送
+	router.Handle("/api/v1/events/stream", guard.Require(auth.ActionSubscribe, stream(controller.StreamEventsSSE))).Methods("GET", "POST")
+	router.Handle("/api/v1/events/ws", guard.Authenticated(stream(controller.StreamEventsWS))).Methods("GET")
+
+	// 订阅管理
+	router.Handle("/api/v1/subscriptions", deadline(guard.Require(auth.ActionManage, controller.ListSubscriptions))).Methods("GET")
+	router.Handle("/api/v1/subscriptions/{id}", deadline(guard.Require(auth.ActionManage, controller.GetSubscription))).Methods("GET")
+	router.Handle("/api/v1/subscriptions/{id}/pause", deadline(guard.Require(auth.ActionManage, controller.PauseSubscription))).Methods("POST")
+	router.Handle("/api/v1/subscriptions/{id}/resume", deadline(guard.Require(auth.ActionManage, controller.ResumeSubscription))).Methods("POST")
+
+	// 身份管理
+	router.Handle("/api/v1/identities", deadline(guard.Require(auth.ActionManage, controller.ListIdentities))).Methods("GET")
+	router.Handle("/api/v1/identities", deadline(guard.Require(auth.ActionManage, controller.PutIdentity))).Methods("POST")
+	router.Handle("/api/v1/identities/{mspId}/{name}", deadline(guard.Require(auth.ActionManage, controller.GetIdentity))).Methods("GET")
+	router.Handle("/api/v1/identities/{mspId}/{name}", deadline(guard.Require(auth.ActionManage, controller.DeleteIdentity))).Methods("DELETE")
+	// 证书到期时间
+	router.Handle("/api/v1/certificates", deadline(guard.Require(auth.ActionManage, controller.ListCertificates))).Methods("GET")
+
+	// Fabric CA：注册、登记、吊销与身份、机构管理
+	ca := func(next http.HandlerFunc) http.Handler {
+		return deadline(guard.Require(auth.ActionManage, next))
+	}
+	router.Handle("/api/v1/ca/info", ca(controller.GetCAInfo)).Methods("POST")
+	router.Handle("/api/v1/ca/register", ca(controller.RegisterCAUser)).Methods("POST")
+	router.Handle("/api/v1/ca/enroll", ca(controller.EnrollCAUser)).Methods("POST")
+	router.Handle("/api/v1/ca/reenroll", ca(controller.ReenrollCAUser)).Methods("POST")
+	router.Handle("/api/v1/ca/revoke", ca(controller.RevokeCAUser)).Methods("POST")
+	router.Handle("/api/v1/ca/identity/list", ca(controller.ListCAIdentities)).Methods("POST")
+	router.Handle("/api/v1/ca/identity/info", ca(controller.GetCAIdentity)).Methods("POST")
+	router.Handle("/api/v1/ca/identity/create", ca(controller.CreateCAIdentity)).Methods("POST")
+	router.Handle("/api/v1/ca/identity/modify", ca(controller.ModifyCAIdentity)).Methods("POST")
+	router.Handle("/api/v1/ca/identity/remove", ca(controller.RemoveCAIdentity)).Methods("POST")
+	router.Handle("/api/v1/ca/affiliation/list", ca(controller.ListCAAffiliations)).Methods("POST")
+	router.Handle("/api/v1/ca/affiliation/info", ca(controller.GetCAAffiliation)).Methods("POST")
+	router.Handle("/api/v1/ca/affiliation/add", ca(controller.AddCAAffiliation)).Methods("POST")
+	router.Handle("/api/v1/ca/affiliation/modify", ca(controller.ModifyCAAffiliation)).Methods("POST")
+	router.Handle("/api/v1/ca/affiliation/remove", ca(controller.RemoveCAAffiliation)).Methods("POST")
 
 	return router
 }
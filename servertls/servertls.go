@@ -0,0 +1,248 @@
+// Package servertls 为服务自身的 HTTP 与 gRPC 监听器提供标准 TLS 与国密 TLS（SM2/SM4/SM3，双证书），
+// 并可校验客户端证书。国密 TLS 连接不是 crypto/tls 连接，net/http 不会填写 Request.TLS，
+// 客户端证书链通过 auth.WithPeerCertificates 随请求上下文传给认证。
+package servertls
+
+import (
+	"context"
+	"crypto/tls"
+	stdx509 "crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	gmtls "gitee.com/china_uni/tjfoc-gm/tls"
+	"gitee.com/china_uni/tjfoc-gm/tls/gmcredentials"
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/qctc/fabric2-api-server/auth"
+	"github.com/qctc/fabric2-api-server/define"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// 传输加密方式
+const (
+	ModeNone  = "none"
+	ModeTLS   = "tls"
+	ModeGMTLS = "gmtls"
+)
+
+// 客户端证书校验策略
+const (
+	ClientAuthNone    = "none"
+	ClientAuthRequest = "request"
+	ClientAuthRequire = "require"
+)
+
+// Config 监听器的 TLS 配置，为 nil 时不加密
+type Config struct {
+	mode string
+	std  *tls.Config
+	gm   *gmtls.Config
+}
+
+// New 按配置加载证书，未启用时返回 nil
+func New(cfg define.ServerTLSConfig) (*Config, error) {
+	switch cfg.Mode {
+	case "", ModeNone:
+		return nil, nil
+	case ModeTLS:
+		std, err := stdConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &Config{mode: ModeTLS, std: std}, nil
+	case ModeGMTLS:
+		gm, err := gmConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &Config{mode: ModeGMTLS, gm: gm}, nil
+	default:
+		return nil, fmt.Errorf("unsupported tls mode %q", cfg.Mode)
+	}
+}
+
+// clientAuth 返回客户端证书校验策略，配置了 CA 而未指定策略时要求客户端证书
+func clientAuth(cfg define.ServerTLSConfig) (string, error) {
+	switch cfg.ClientAuth {
+	case "":
+		if cfg.ClientCAFile == "" {
+			return ClientAuthNone, nil
+		}
+		return ClientAuthRequire, nil
+	case ClientAuthNone:
+		return ClientAuthNone, nil
+	case ClientAuthRequest, ClientAuthRequire:
+		if cfg.ClientCAFile == "" {
+			return "", fmt.Errorf("clientCAFile is required when clientAuth is %s", cfg.ClientAuth)
+		}
+		return cfg.ClientAuth, nil
+	default:
+		return "", fmt.Errorf("unsupported clientAuth %q", cfg.ClientAuth)
+	}
+}
+
+func stdConfig(cfg define.ServerTLSConfig) (*tls.Config, error) {
+	if cfg.EncCertFile != "" || cfg.EncKeyFile != "" {
+		return nil, fmt.Errorf("encCertFile and encKeyFile are only used with mode %s", ModeGMTLS)
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load server certificate: %w", err)
+	}
+	c := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	switch cfg.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		c.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported minVersion %q", cfg.MinVersion)
+	}
+
+	policy, err := clientAuth(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if policy == ClientAuthNone {
+		return c, nil
+	}
+	caPEM, err := os.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client ca file: %w", err)
+	}
+	c.ClientCAs = stdx509.NewCertPool()
+	if !c.ClientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", cfg.ClientCAFile)
+	}
+	c.ClientAuth = tls.VerifyClientCertIfGiven
+	if policy == ClientAuthRequire {
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return c, nil
+}
+
+func gmConfig(cfg define.ServerTLSConfig) (*gmtls.Config, error) {
+	if cfg.MinVersion != "" {
+		return nil, fmt.Errorf("minVersion is not used with mode %s", ModeGMTLS)
+	}
+	var cert gmtls.Certificate
+	var err error
+	switch {
+	case cfg.EncCertFile == "" && cfg.EncKeyFile == "":
+		cert, err = gmtls.LoadGMX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	case cfg.EncCertFile != "" && cfg.EncKeyFile != "":
+		cert, err = gmtls.LoadGMX509KeyPairs(cfg.CertFile, cfg.KeyFile, cfg.EncCertFile, cfg.EncKeyFile)
+	default:
+		return nil, fmt.Errorf("encCertFile and encKeyFile must be set together")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load server sm2 certificate: %w", err)
+	}
+	c := &gmtls.Config{
+		GMSupport:    &gmtls.GMSupport{},
+		Certificates: []gmtls.Certificate{cert},
+		MinVersion:   gmtls.VersionGMSSL,
+	}
+
+	policy, err := clientAuth(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if policy == ClientAuthNone {
+		return c, nil
+	}
+	caPEM, err := os.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client ca file: %w", err)
+	}
+	c.ClientCAs = x509.NewCertPool()
+	if !c.ClientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", cfg.ClientCAFile)
+	}
+	c.ClientAuth = gmtls.VerifyClientCertIfGiven
+	if policy == ClientAuthRequire {
+		c.ClientAuth = gmtls.RequireAndVerifyClientCert
+	}
+	return c, nil
+}
+
+// Mode 返回传输加密方式
+func (c *Config) Mode() string {
+	if c == nil {
+		return ModeNone
+	}
+	return c.mode
+}
+
+// Listener 包装监听器，接受的连接在首次读写时完成握手；c 为 nil 时原样返回
+func (c *Config) Listener(inner net.Listener) net.Listener {
+	switch {
+	case c == nil:
+		return inner
+	case c.gm != nil:
+		return &gmListener{Listener: inner, config: c.gm}
+	default:
+		return tls.NewListener(inner, c.std)
+	}
+}
+
+// gmListener 国密 TLS 监听器。gmtls.Server 会修改传入的配置，每个连接使用配置的副本，避免并发握手时共享同一配置
+type gmListener struct {
+	net.Listener
+	config *gmtls.Config
+}
+
+func (l *gmListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return gmtls.Server(conn, l.config.Clone()), nil
+}
+
+type gmConnKey struct{}
+
+// ConnContext 作为 http.Server.ConnContext，记录国密 TLS 连接供 Handler 读取客户端证书。
+// 此处在接受连接的循环中调用，不能等待握手
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	if gm, ok := conn.(*gmtls.Conn); ok {
+		return context.WithValue(ctx, gmConnKey{}, gm)
+	}
+	return ctx
+}
+
+// Handler 将国密 TLS 连接上的客户端证书链记录到请求上下文。读取请求时握手已经完成
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conn, ok := r.Context().Value(gmConnKey{}).(*gmtls.Conn); ok {
+			if certs := conn.ConnectionState().PeerCertificates; len(certs) > 0 {
+				raw := make([][]byte, 0, len(certs))
+				for _, cert := range certs {
+					raw = append(raw, cert.Raw)
+				}
+				r = r.WithContext(auth.WithPeerCertificates(r.Context(), raw))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ServerOption 返回 gRPC 服务的传输凭证选项，c 为 nil 时返回 nil
+func (c *Config) ServerOption() grpc.ServerOption {
+	switch {
+	case c == nil:
+		return nil
+	case c.gm != nil:
+		return grpc.Creds(gmcredentials.NewTLS(c.gm))
+	default:
+		std := c.std.Clone()
+		std.NextProtos = nil
+		return grpc.Creds(credentials.NewTLS(std))
+	}
+}
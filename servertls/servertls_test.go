@@ -0,0 +1,297 @@
+package servertls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitee.com/china_uni/tjfoc-gm/sm2"
+	gmtls "gitee.com/china_uni/tjfoc-gm/tls"
+	"gitee.com/china_uni/tjfoc-gm/x509"
+	"github.com/qctc/fabric2-api-server/auth"
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+func TestNewDisabled(t *testing.T) {
+	for _, mode := range []string{"", ModeNone} {
+		c, err := New(define.ServerTLSConfig{Mode: mode})
+		if err != nil || c != nil {
+			t.Errorf("New(%q) = %v, %v", mode, c, err)
+		}
+		if c.Mode() != ModeNone || c.ServerOption() != nil {
+			t.Errorf("nil config mode = %q", c.Mode())
+		}
+	}
+	if _, err := New(define.ServerTLSConfig{Mode: "ssl"}); err == nil {
+		t.Error("expected error for unsupported mode")
+	}
+}
+
+func TestClientAuth(t *testing.T) {
+	cases := []struct {
+		cfg     define.ServerTLSConfig
+		want    string
+		wantErr bool
+	}{
+		{define.ServerTLSConfig{}, ClientAuthNone, false},
+		{define.ServerTLSConfig{ClientCAFile: "ca.pem"}, ClientAuthRequire, false},
+		{define.ServerTLSConfig{ClientCAFile: "ca.pem", ClientAuth: "request"}, ClientAuthRequest, false},
+		{define.ServerTLSConfig{ClientAuth: "require"}, "", true},
+		{define.ServerTLSConfig{ClientAuth: "optional"}, "", true},
+	}
+	for _, c := range cases {
+		got, err := clientAuth(c.cfg)
+		if got != c.want || (err != nil) != c.wantErr {
+			t.Errorf("clientAuth(%+v) = %q, %v", c.cfg, got, err)
+		}
+	}
+}
+
+func TestStandardTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newECDSACA(t)
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", ca.Raw)
+	issueECDSA(t, dir, "server", ca, caKey, stdx509.ExtKeyUsageServerAuth)
+	clientCert := issueECDSA(t, dir, "client", ca, caKey, stdx509.ExtKeyUsageClientAuth)
+
+	c, err := New(define.ServerTLSConfig{
+		Mode:         ModeTLS,
+		CertFile:     filepath.Join(dir, "server.pem"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := serve(t, c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+
+	roots := stdx509.NewCertPool()
+	roots.AddCert(ca)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		ServerName:   "localhost",
+		Certificates: []tls.Certificate{clientCert},
+	}}}
+	if got := get(t, client, "https://"+addr); got != "client" {
+		t.Errorf("client common name = %q", got)
+	}
+
+	// 要求客户端证书时，不提供证书的握手失败
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, ServerName: "localhost"}}}
+	if resp, err := anonymous.Get("https://" + addr); err == nil {
+		resp.Body.Close()
+		t.Error("expected handshake failure without client certificate")
+	}
+}
+
+func TestGMTLS(t *testing.T) {
+	dir := t.TempDir()
+	caKey, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := issueSM2(t, dir, "ca", &x509.Certificate{
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, caKey, caKey)
+	issueSM2(t, dir, "server", &x509.Certificate{
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:    []string{"localhost"},
+	}, ca, caKey, nil)
+	issueSM2(t, dir, "client", &x509.Certificate{
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey, nil)
+
+	c, err := New(define.ServerTLSConfig{
+		Mode:         ModeGMTLS,
+		CertFile:     filepath.Join(dir, "server.pem"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 国密连接上的客户端证书经 Handler 传给认证
+	authenticator, err := auth.NewCertAuthenticator(define.MTLSConfig{Enabled: true, ClientCAFile: filepath.Join(dir, "ca.pem")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := serve(t, c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := authenticator.Authenticate(r)
+		if err != nil || principal == nil {
+			http.Error(w, "unauthenticated", http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, principal.Name)
+	}))
+
+	clientCert, err := gmtls.LoadGMX509KeyPair(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	client := &http.Client{Transport: &http.Transport{
+		DialTLSContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return gmtls.Dial(network, address, &gmtls.Config{
+				GMSupport:    &gmtls.GMSupport{},
+				RootCAs:      roots,
+				ServerName:   "localhost",
+				Certificates: []gmtls.Certificate{clientCert},
+			})
+		},
+	}}
+	if got := get(t, client, "https://"+addr); got != "client" {
+		t.Errorf("principal = %q", got)
+	}
+}
+
+// serve 在本地端口上以 c 提供 handler，测试结束时关闭
+func serve(t *testing.T, c *Config, handler http.Handler) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: Handler(handler), ConnContext: ConnContext}
+	go server.Serve(c.Listener(l))
+	t.Cleanup(func() { server.Close() })
+	return l.Addr().String()
+}
+
+func get(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d: %s", resp.StatusCode, body)
+	}
+	return string(body)
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newECDSACA(t *testing.T) (*stdx509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &stdx509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              stdx509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := stdx509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := stdx509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca, key
+}
+
+// issueECDSA 签发证书并写入 <name>.pem 与 <name>.key
+func issueECDSA(t *testing.T, dir, name string, ca *stdx509.Certificate, caKey *ecdsa.PrivateKey, usage stdx509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &stdx509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     stdx509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []stdx509.ExtKeyUsage{usage},
+	}
+	der, err := stdx509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := stdx509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, name+".pem"), "CERTIFICATE", der)
+	writePEM(t, filepath.Join(dir, name+".key"), "EC PRIVATE KEY", keyDER)
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, name+".pem"), filepath.Join(dir, name+".key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// issueSM2 签发 SM2 证书并写入 <name>.pem 与 <name>.key；parent 为 nil 时自签名，key 为 nil 时生成新密钥
+func issueSM2(t *testing.T, dir, name string, tmpl, parent *x509.Certificate, parentKey, key *sm2.PrivateKey) *x509.Certificate {
+	t.Helper()
+	if key == nil {
+		var err error
+		if key, err = sm2.GenerateKey(rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if parent == nil {
+		parent = tmpl
+	}
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.Subject = pkix.Name{CommonName: name}
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	tmpl.SignatureAlgorithm = x509.SM2WithSM3
+	certPEM, err := x509.CreateCertificateToMem(tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, err := x509.WritePrivateKeytoPem(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".pem"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
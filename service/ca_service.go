@@ -0,0 +1,273 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	mspclient "github.com/hyperledger/fabric-sdk-go/pkg/client/msp"
+	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/qctc/fabric2-api-server/hsm"
+	"github.com/qctc/fabric2-api-server/identity"
+	"github.com/qctc/fabric2-api-server/model/vo"
+)
+
+// 登记后私钥的保存位置
+const (
+	KeyStorageIdentityStore = "identityStore"
+	KeyStorageHSM           = "hsm"
+)
+
+// CAClient 组织的 Fabric CA 客户端。注册、吊销与身份、机构管理以连接配置中 CA 的 registrar 身份执行
+type CAClient struct {
+	client   *mspclient.Client
+	ctx      contextApi.Client
+	mspID    string
+	keystore string
+}
+
+// CAClient 创建 CA 客户端，orgName 为空时使用连接配置的 client.organization，caID 为空时使用组织的第一个 CA
+func (s *Fabric2Service) CAClient(orgName, caID string) (*CAClient, error) {
+	if orgName == "" {
+		name, err := s.getOrgName()
+		if err != nil {
+			return nil, err
+		}
+		orgName = name
+	}
+	ctx, err := s.sdk.Context()()
+	if err != nil {
+		return nil, err
+	}
+	org, ok := ctx.EndpointConfig().NetworkConfig().Organizations[strings.ToLower(orgName)]
+	if !ok {
+		return nil, fmt.Errorf("non-existent organization: '%s'", orgName)
+	}
+	client, err := mspclient.New(s.sdk.Context(), mspclient.WithOrg(orgName), mspclient.WithCAInstance(caID))
+	if err != nil {
+		return nil, err
+	}
+	backend, err := s.sdk.Config()
+	if err != nil {
+		return nil, err
+	}
+	return &CAClient{
+		client:   client,
+		ctx:      ctx,
+		mspID:    org.MSPID,
+		keystore: cryptosuite.ConfigFromBackend(backend).KeyStorePath(),
+	}, nil
+}
+
+// Info 获取 CA 名称、证书链与版本
+func (c *CAClient) Info() (*vo.CAInfoVO, error) {
+	info, err := c.client.GetCAInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &vo.CAInfoVO{CAName: info.CAName, CAChain: string(info.CAChain), Version: info.Version}, nil
+}
+
+// Register 注册用户，返回登记密码
+func (c *CAClient) Register(req *mspclient.RegistrationRequest) (string, error) {
+	return c.client.Register(req)
+}
+
+// Enroll 登记用户并保存证书与私钥。启用 HSM 时私钥在 HSM 中生成，证书保存在 SDK 的用户存储；
+// 否则私钥与证书保存到身份存储，两者都未启用时不登记，避免私钥以明文留在服务器上
+func (c *CAClient) Enroll(name string, opts ...mspclient.EnrollmentOption) (*vo.EnrollmentVO, error) {
+	storage, err := c.keyStorage(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.client.Enroll(name, opts...); err != nil {
+		return nil, err
+	}
+	return c.saveEnrollment(name, storage)
+}
+
+// Reenroll 以用户当前的证书重新登记，获取新的证书与私钥，用于证书到期前续期
+func (c *CAClient) Reenroll(name string, opts ...mspclient.EnrollmentOption) (*vo.EnrollmentVO, error) {
+	storage, err := c.keyStorage(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.client.Reenroll(name, opts...); err != nil {
+		return nil, err
+	}
+	return c.saveEnrollment(name, storage)
+}
+
+// keyStorage 在联系 CA 之前确定私钥的保存位置
+func (c *CAClient) keyStorage(name string) (string, error) {
+	if hsm.Default != nil {
+		return KeyStorageHSM, nil
+	}
+	if identity.Default == nil {
+		return "", identity.ErrDisabled
+	}
+	if err := identity.CheckName(c.mspID, name); err != nil {
+		return "", err
+	}
+	return KeyStorageIdentityStore, nil
+}
+
+// saveEnrollment 从 SDK 用户存储读取新证书，私钥保存到身份存储时同时导入证书
+func (c *CAClient) saveEnrollment(name, storage string) (*vo.EnrollmentVO, error) {
+	user, err := c.ctx.UserStore().Load(msp.IdentityIdentifier{MSPID: c.mspID, ID: name})
+	if err != nil {
+		return nil, fmt.Errorf("load enrolled certificate of %s: %w", name, err)
+	}
+	if storage == KeyStorageIdentityStore {
+		if _, err := identity.Default.ImportEnrollment(c.mspID, name, user.EnrollmentCertificate, c.keystore); err != nil {
+			return nil, err
+		}
+	}
+	return &vo.EnrollmentVO{
+		Name:        name,
+		MSPID:       c.mspID,
+		Certificate: string(user.EnrollmentCertificate),
+		KeyStorage:  storage,
+	}, nil
+}
+
+// Revoke 吊销用户的全部证书或指定证书，genCRL 为 true 时返回 CA 生成的证书吊销列表
+func (c *CAClient) Revoke(req *mspclient.RevocationRequest) (*vo.RevocationVO, error) {
+	resp, err := c.client.Revoke(req)
+	if err != nil {
+		return nil, err
+	}
+	result := &vo.RevocationVO{RevokedCerts: make([]vo.RevokedCertVO, 0, len(resp.RevokedCerts)), CRL: string(resp.CRL)}
+	for _, cert := range resp.RevokedCerts {
+		result.RevokedCerts = append(result.RevokedCerts, vo.RevokedCertVO{Serial: cert.Serial, AKI: cert.AKI})
+	}
+	return result, nil
+}
+
+// Identities 获取 registrar 可见的全部身份
+func (c *CAClient) Identities() ([]vo.CAIdentityVO, error) {
+	list, err := c.client.GetAllIdentities()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]vo.CAIdentityVO, 0, len(list))
+	for _, id := range list {
+		result = append(result, identityVO(id))
+	}
+	return result, nil
+}
+
+// Identity 获取身份
+func (c *CAClient) Identity(id string) (*vo.CAIdentityVO, error) {
+	resp, err := c.client.GetIdentity(id)
+	if err != nil {
+		return nil, err
+	}
+	result := identityVO(resp)
+	return &result, nil
+}
+
+// CreateIdentity 创建身份，返回的 secret 为登记密码
+func (c *CAClient) CreateIdentity(req *mspclient.IdentityRequest) (*vo.CAIdentityVO, string, error) {
+	resp, err := c.client.CreateIdentity(req)
+	if err != nil {
+		return nil, "", err
+	}
+	result := identityVO(resp)
+	return &result, resp.Secret, nil
+}
+
+// ModifyIdentity 修改身份的类型、机构、属性、登记次数或密码
+func (c *CAClient) ModifyIdentity(req *mspclient.IdentityRequest) (*vo.CAIdentityVO, error) {
+	resp, err := c.client.ModifyIdentity(req)
+	if err != nil {
+		return nil, err
+	}
+	result := identityVO(resp)
+	return &result, nil
+}
+
+// RemoveIdentity 删除身份，CA 需开启 cfg.identities.allowremove
+func (c *CAClient) RemoveIdentity(req *mspclient.RemoveIdentityRequest) (*vo.CAIdentityVO, error) {
+	resp, err := c.client.RemoveIdentity(req)
+	if err != nil {
+		return nil, err
+	}
+	result := identityVO(resp)
+	return &result, nil
+}
+
+// Affiliations 获取全部机构
+func (c *CAClient) Affiliations() (*vo.CAAffiliationVO, error) {
+	return affiliationResult(c.client.GetAllAffiliations())
+}
+
+// Affiliation 获取机构及其下级机构
+func (c *CAClient) Affiliation(name string) (*vo.CAAffiliationVO, error) {
+	return affiliationResult(c.client.GetAffiliation(name))
+}
+
+// AddAffiliation 添加机构，force 为 true 时同时创建不存在的上级机构
+func (c *CAClient) AddAffiliation(req *mspclient.AffiliationRequest) (*vo.CAAffiliationVO, error) {
+	return affiliationResult(c.client.AddAffiliation(req))
+}
+
+// ModifyAffiliation 重命名机构，force 为 true 时同时修改机构下身份的机构
+func (c *CAClient) ModifyAffiliation(req *mspclient.ModifyAffiliationRequest) (*vo.CAAffiliationVO, error) {
+	return affiliationResult(c.client.ModifyAffiliation(req))
+}
+
+// RemoveAffiliation 删除机构，CA 需开启 cfg.affiliations.allowremove；force 为 true 时同时删除下级机构与身份
+func (c *CAClient) RemoveAffiliation(req *mspclient.AffiliationRequest) (*vo.CAAffiliationVO, error) {
+	return affiliationResult(c.client.RemoveAffiliation(req))
+}
+
+func identityVO(resp *mspclient.IdentityResponse) vo.CAIdentityVO {
+	return vo.CAIdentityVO{
+		ID:             resp.ID,
+		Type:           resp.Type,
+		Affiliation:    resp.Affiliation,
+		Attributes:     attributeVOs(resp.Attributes),
+		MaxEnrollments: resp.MaxEnrollments,
+		CAName:         resp.CAName,
+	}
+}
+
+func attributeVOs(attrs []mspclient.Attribute) []vo.CAAttributeVO {
+	result := make([]vo.CAAttributeVO, 0, len(attrs))
+	for _, attr := range attrs {
+		result = append(result, vo.CAAttributeVO{Name: attr.Name, Value: attr.Value, ECert: attr.ECert})
+	}
+	return result
+}
+
+func affiliationResult(resp *mspclient.AffiliationResponse, err error) (*vo.CAAffiliationVO, error) {
+	if err != nil {
+		return nil, err
+	}
+	result := affiliationVO(resp.AffiliationInfo)
+	result.CAName = resp.CAName
+	return &result, nil
+}
+
+func affiliationVO(info mspclient.AffiliationInfo) vo.CAAffiliationVO {
+	result := vo.CAAffiliationVO{
+		Name:         info.Name,
+		Affiliations: make([]vo.CAAffiliationVO, 0, len(info.Affiliations)),
+		Identities:   make([]vo.CAIdentityVO, 0, len(info.Identities)),
+	}
+	for _, child := range info.Affiliations {
+		result.Affiliations = append(result.Affiliations, affiliationVO(child))
+	}
+	for _, id := range info.Identities {
+		result.Identities = append(result.Identities, vo.CAIdentityVO{
+			ID:             id.ID,
+			Type:           id.Type,
+			Affiliation:    id.Affiliation,
+			Attributes:     attributeVOs(id.Attributes),
+			MaxEnrollments: id.MaxEnrollments,
+		})
+	}
+	return result
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	mspclient "github.com/hyperledger/fabric-sdk-go/pkg/client/msp"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/identity"
+)
+
+// fakeCA 模拟 Fabric CA 的 cainfo、register 与 enroll 接口，登记时按注册的 ecert 属性签发 ECDSA 证书
+type fakeCA struct {
+	*httptest.Server
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+	pem  []byte
+
+	mu         sync.Mutex
+	secrets    map[string]string
+	attrs      map[string]map[string]string
+	registered []string
+}
+
+func newFakeCA(t *testing.T) *fakeCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca.org1"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &fakeCA{
+		key:     key,
+		cert:    cert,
+		pem:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		secrets: map[string]string{"admin": "adminpw"},
+		attrs:   map[string]map[string]string{"admin": {}},
+	}
+	mux := http.NewServeMux()
+	// SDK 的 CA 客户端直接在 url 后拼接接口名，不带 /api/v1 前缀
+	mux.HandleFunc("/cainfo", func(w http.ResponseWriter, r *http.Request) {
+		ca.reply(w, ca.info())
+	})
+	mux.HandleFunc("/register", ca.register)
+	mux.HandleFunc("/enroll", ca.enroll)
+	ca.Server = httptest.NewServer(mux)
+	t.Cleanup(ca.Close)
+	return ca
+}
+
+func (ca *fakeCA) info() map[string]string {
+	return map[string]string{
+		"CAName":  "ca.org1",
+		"CAChain": base64.StdEncoding.EncodeToString(ca.pem),
+		"Version": "1.5.0",
+	}
+}
+
+func (ca *fakeCA) reply(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "result": result, "errors": []string{}, "messages": []string{}})
+}
+
+func (ca *fakeCA) fail(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false, "result": nil, "messages": []string{},
+		"errors": []map[string]interface{}{{"code": code, "message": msg}},
+	})
+}
+
+func (ca *fakeCA) register(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     string `json:"id"`
+		Secret string `json:"secret"`
+		Attrs  []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+			ECert bool   `json:"ecert"`
+		} `json:"attrs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		ca.fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if _, ok := ca.secrets[req.ID]; ok {
+		ca.fail(w, http.StatusConflict, fmt.Sprintf("Identity '%s' is already registered", req.ID))
+		return
+	}
+	if req.Secret == "" {
+		req.Secret = "generated"
+	}
+	attrs := map[string]string{}
+	for _, attr := range req.Attrs {
+		if attr.ECert {
+			attrs[attr.Name] = attr.Value
+		}
+	}
+	ca.secrets[req.ID] = req.Secret
+	ca.attrs[req.ID] = attrs
+	ca.registered = append(ca.registered, req.ID)
+	ca.reply(w, map[string]string{"secret": req.Secret})
+}
+
+func (ca *fakeCA) enroll(w http.ResponseWriter, r *http.Request) {
+	name, secret, ok := r.BasicAuth()
+	ca.mu.Lock()
+	want, registered := ca.secrets[name]
+	attrs := ca.attrs[name]
+	ca.mu.Unlock()
+	if !ok || !registered || secret != want {
+		ca.fail(w, http.StatusUnauthorized, "Authentication failure")
+		return
+	}
+	var req struct {
+		Request string `json:"certificate_request"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		ca.fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	block, _ := pem.Decode([]byte(req.Request))
+	if block == nil {
+		ca.fail(w, http.StatusBadRequest, "invalid certificate request")
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		ca.fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if len(attrs) > 0 {
+		value, _ := json.Marshal(map[string]map[string]string{"attrs": attrs})
+		tmpl.ExtraExtensions = []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}, Value: value}}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, csr.PublicKey, ca.key)
+	if err != nil {
+		ca.fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	ca.reply(w, map[string]interface{}{
+		"Cert":       base64.StdEncoding.EncodeToString(cert),
+		"ServerInfo": ca.info(),
+	})
+}
+
+// newCAService 以指向 ca 的连接配置创建 SDK 实例，并启用临时身份存储，测试结束时恢复
+func newCAService(t *testing.T, ca *fakeCA) *Fabric2Service {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := identity.New(define.IdentityStoreConfig{
+		Enabled:   true,
+		File:      filepath.Join(dir, "identities.json"),
+		MasterKey: hex.EncodeToString(make([]byte, 16)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := identity.Default
+	identity.Default = store
+	t.Cleanup(func() { identity.Default = prev })
+
+	if err := os.WriteFile(filepath.Join(dir, "ca.pem"), ca.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	profile := fmt.Sprintf(`
+version: 1.0.0
+client:
+  organization: org1
+  credentialStore:
+    path: %[1]s/state
+    cryptoStore:
+      path: %[1]s/msp
+organizations:
+  org1:
+    mspid: Org1MSP
+    cryptoPath: %[1]s/users/{username}/msp
+    certificateAuthorities: [ca.org1]
+certificateAuthorities:
+  ca.org1:
+    url: %[2]s
+    caName: ca.org1
+    tlsCACerts:
+      path: %[1]s/ca.pem
+    registrar:
+      enrollId: admin
+      enrollSecret: adminpw
+`, filepath.ToSlash(dir), ca.URL)
+	sdkId := "ca-test-" + t.Name()
+	if err := InitFabric2Service(profile, sdkId, false, false); err != nil {
+		t.Fatal(err)
+	}
+	s := GetFabric2Service(sdkId)
+	t.Cleanup(func() {
+		poolMutex.Lock()
+		delete(Fabric2ServicePool, sdkId)
+		poolMutex.Unlock()
+		s.sdk.Close()
+	})
+	return s
+}
+
+func TestCAClientEnroll(t *testing.T) {
+	ca := newFakeCA(t)
+	s := newCAService(t, ca)
+
+	client, err := s.CAClient("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := client.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.CAName != "ca.org1" || info.CAChain != string(ca.pem) || info.Version != "1.5.0" {
+		t.Errorf("Info() = %+v", info)
+	}
+
+	secret, err := client.Register(&mspclient.RegistrationRequest{
+		Name:       "user1",
+		Type:       "client",
+		Secret:     "user1pw",
+		Attributes: []mspclient.Attribute{{Name: "role", Value: "auditor", ECert: true}},
+	})
+	if err != nil || secret != "user1pw" {
+		t.Fatalf("Register() = %q, %v", secret, err)
+	}
+	result, err := client.Enroll("user1", mspclient.WithSecret(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Name != "user1" || result.MSPID != "Org1MSP" || result.KeyStorage != KeyStorageIdentityStore {
+		t.Errorf("Enroll() = %+v", result)
+	}
+	if value, ok, err := identity.CertificateAttribute([]byte(result.Certificate), "role"); err != nil || !ok || value != "auditor" {
+		t.Errorf("enrolled certificate attribute = %q, %v, %v", value, ok, err)
+	}
+	// 私钥与证书导入身份存储
+	stored, err := identity.Default.Get("Org1MSP", "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Certificate != result.Certificate {
+		t.Errorf("stored certificate differs from enrolled certificate")
+	}
+
+	if _, err := client.Enroll("user1", mspclient.WithSecret("wrong")); err == nil {
+		t.Errorf("Enroll() with wrong secret succeeded")
+	}
+}
+
+func TestCAClientEnrollWithoutKeyStorage(t *testing.T) {
+	ca := newFakeCA(t)
+	s := newCAService(t, ca)
+	client, err := s.CAClient("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity.Default = nil
+	if _, err := client.Enroll("admin", mspclient.WithSecret("adminpw")); !errors.Is(err, identity.ErrDisabled) {
+		t.Errorf("Enroll() without identity store = %v, want ErrDisabled", err)
+	}
+}
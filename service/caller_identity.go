@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	mspclient "github.com/hyperledger/fabric-sdk-go/pkg/client/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/hsm"
+	"github.com/qctc/fabric2-api-server/identity"
+)
+
+// ErrCallerIdentity 调用方没有可用的 Fabric 身份
+var ErrCallerIdentity = errors.New("no fabric identity for caller")
+
+// CallerAttribute 调用方身份证书中的属性，值为调用方标识 <method>:<name>，链码可通过 cid 读取。自动登记时写入，
+// 预先导入的身份须在注册时设置该属性（ecert 为 true）
+const CallerAttribute = "api.caller"
+
+// CallerIdentities 调用方身份，为 nil 时合约调用与查询以组织管理员身份签名
+var CallerIdentities *CallerIdentityResolver
+
+// CallerIdentityResolver 将已认证的调用方映射为连接配置组织中各自的 Fabric 身份，
+// 身份名称由 identity.CallerName 从调用方标识得出，证书须带有值为调用方标识的 api.caller 属性。
+// 身份可以预先导入身份存储，也可以在首次调用时通过组织的 CA 登记
+type CallerIdentityResolver struct {
+	cfg define.UserIdentitiesConfig
+	// mu 串行化首次登记，同一调用方的并发请求只注册一次
+	mu sync.Mutex
+}
+
+// NewCallerIdentityResolver 按配置创建调用方身份映射，未启用时返回 nil
+func NewCallerIdentityResolver(cfg define.UserIdentitiesConfig) (*CallerIdentityResolver, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.AutoEnroll && identity.Default == nil && hsm.Default == nil {
+		return nil, errors.New("userIdentities.autoEnroll requires identityStore or hsm to keep enrolled private keys")
+	}
+	return &CallerIdentityResolver{cfg: cfg}, nil
+}
+
+// CallerContext 返回以调用方身份签名合约调用与查询的 ctx，未启用调用方身份时原样返回 ctx
+func (s *Fabric2Service) CallerContext(ctx context.Context, caller string) (context.Context, error) {
+	r := CallerIdentities
+	if r == nil {
+		return ctx, nil
+	}
+	if caller == "" {
+		return nil, fmt.Errorf("%w: caller is not authenticated", ErrCallerIdentity)
+	}
+	name := identity.CallerName(caller)
+	if err := r.ensure(ctx, s, caller, name); err != nil {
+		return nil, err
+	}
+	return withSigner(ctx, name), nil
+}
+
+// ensure 确认组织中存在调用方身份，不存在且启用自动登记时注册并登记。身份证书的 api.caller 属性必须是该调用方，
+// 避免预先导入或其他途径登记的同名身份被用来代替调用方签名
+func (r *CallerIdentityResolver) ensure(ctx context.Context, s *Fabric2Service, caller, name string) error {
+	orgName, err := s.getOrgName()
+	if err != nil {
+		return err
+	}
+	cert, err := s.signingCertificate(orgName, name)
+	if err != nil {
+		return err
+	}
+	if cert != nil {
+		return checkCaller(cert, caller, name)
+	}
+	if !r.cfg.AutoEnroll {
+		return fmt.Errorf("%w: %s is not enrolled", ErrCallerIdentity, name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cert, err := s.signingCertificate(orgName, name); err != nil || cert != nil {
+		if err != nil {
+			return err
+		}
+		return checkCaller(cert, caller, name)
+	}
+	ca, err := s.CAClient(orgName, r.cfg.CAID)
+	if err != nil {
+		return err
+	}
+	// 登记密码只使用一次，不保存
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	_, err = ca.Register(&mspclient.RegistrationRequest{
+		Name:        name,
+		Type:        r.cfg.Type,
+		Affiliation: r.cfg.Affiliation,
+		Secret:      hex.EncodeToString(secret),
+		Attributes:  []mspclient.Attribute{{Name: CallerAttribute, Value: caller, ECert: true}},
+	})
+	if err != nil {
+		return fmt.Errorf("register identity %s: %w", name, err)
+	}
+	result, err := ca.Enroll(name, mspclient.WithSecret(hex.EncodeToString(secret)))
+	if err != nil {
+		return fmt.Errorf("enroll identity %s: %w", name, err)
+	}
+	slog.InfoContext(ctx, "caller identity enrolled", "caller", caller, "mspId", result.MSPID, "name", name, "keyStorage", result.KeyStorage)
+	return checkCaller([]byte(result.Certificate), caller, name)
+}
+
+// checkCaller 校验身份证书的 api.caller 属性与调用方一致
+func checkCaller(cert []byte, caller, name string) error {
+	value, ok, err := identity.CertificateAttribute(cert, CallerAttribute)
+	if err != nil {
+		return fmt.Errorf("%w: certificate of %s: %v", ErrCallerIdentity, name, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: certificate of %s has no %s attribute", ErrCallerIdentity, name, CallerAttribute)
+	}
+	if value != caller {
+		return fmt.Errorf("%w: identity %s belongs to another caller", ErrCallerIdentity, name)
+	}
+	return nil
+}
+
+// signingCertificate 按 SDK 加载签名身份的顺序（身份存储、SDK 用户存储、连接配置）查找身份，返回其证书，
+// 身份不存在时返回 nil
+func (s *Fabric2Service) signingCertificate(orgName, name string) ([]byte, error) {
+	ctx, err := s.sdk.Context()()
+	if err != nil {
+		return nil, err
+	}
+	mgr, ok := ctx.IdentityManager(orgName)
+	if !ok {
+		return nil, fmt.Errorf("identity manager of organization %s not found", orgName)
+	}
+	id, err := mgr.GetSigningIdentity(name)
+	if err != nil {
+		if errors.Is(err, msp.ErrUserNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return id.EnrollmentCertificate(), nil
+}
+
+type signerKey struct{}
+
+// withSigner 指定 ctx 下合约调用与查询的签名身份
+func withSigner(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, signerKey{}, name)
+}
+
+// signerFromContext 返回 ctx 指定的签名身份，未指定时为空
+func signerFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(signerKey{}).(string)
+	return name
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mspclient "github.com/hyperledger/fabric-sdk-go/pkg/client/msp"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/identity"
+)
+
+func useCallerIdentities(t *testing.T, cfg define.UserIdentitiesConfig) {
+	t.Helper()
+	r, err := NewCallerIdentityResolver(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := CallerIdentities
+	CallerIdentities = r
+	t.Cleanup(func() { CallerIdentities = prev })
+}
+
+func TestCallerContextAutoEnroll(t *testing.T) {
+	ca := newFakeCA(t)
+	s := newCAService(t, ca)
+	useCallerIdentities(t, define.UserIdentitiesConfig{Enabled: true, AutoEnroll: true, Type: "client"})
+
+	ctx, err := s.CallerContext(context.Background(), "jwt:alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := identity.CallerName("jwt:alice")
+	if got := signerFromContext(ctx); got != name {
+		t.Errorf("signer = %q, want %q", got, name)
+	}
+	stored, err := identity.Default.Get("Org1MSP", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok, _ := identity.CertificateAttribute([]byte(stored.Certificate), CallerAttribute); !ok || value != "jwt:alice" {
+		t.Errorf("%s = %q, %v", CallerAttribute, value, ok)
+	}
+
+	// 已登记的调用方不再注册；仅大小写不同的调用方使用各自的身份
+	if _, err := s.CallerContext(context.Background(), "jwt:alice"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, err = s.CallerContext(context.Background(), "jwt:Alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signerFromContext(ctx) == name {
+		t.Errorf("jwt:Alice signs as jwt:alice")
+	}
+	ca.mu.Lock()
+	registered := append([]string(nil), ca.registered...)
+	ca.mu.Unlock()
+	if len(registered) != 2 || registered[0] != name || registered[1] != identity.CallerName("jwt:Alice") {
+		t.Errorf("registered = %v", registered)
+	}
+
+	if _, err := s.CallerContext(context.Background(), ""); !errors.Is(err, ErrCallerIdentity) {
+		t.Errorf("anonymous caller = %v, want ErrCallerIdentity", err)
+	}
+}
+
+func TestCallerContextRejectsOtherCallersIdentity(t *testing.T) {
+	ca := newFakeCA(t)
+	s := newCAService(t, ca)
+	useCallerIdentities(t, define.UserIdentitiesConfig{Enabled: true, AutoEnroll: true, Type: "client"})
+
+	// 以 jwt:bob 的身份名称预先登记另一个调用方的证书
+	client, err := s.CAClient("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := identity.CallerName("jwt:bob")
+	secret, err := client.Register(&mspclient.RegistrationRequest{
+		Name:       name,
+		Type:       "client",
+		Attributes: []mspclient.Attribute{{Name: CallerAttribute, Value: "jwt:mallory", ECert: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Enroll(name, mspclient.WithSecret(secret)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CallerContext(context.Background(), "jwt:bob"); !errors.Is(err, ErrCallerIdentity) {
+		t.Errorf("CallerContext() = %v, want ErrCallerIdentity", err)
+	}
+
+	// 没有 api.caller 属性的身份同样拒绝
+	name = identity.CallerName("jwt:carol")
+	if secret, err = client.Register(&mspclient.RegistrationRequest{Name: name, Type: "client"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Enroll(name, mspclient.WithSecret(secret)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CallerContext(context.Background(), "jwt:carol"); !errors.Is(err, ErrCallerIdentity) {
+		t.Errorf("CallerContext() without attribute = %v, want ErrCallerIdentity", err)
+	}
+}
+
+func TestCallerContextWithoutAutoEnroll(t *testing.T) {
+	ca := newFakeCA(t)
+	s := newCAService(t, ca)
+	useCallerIdentities(t, define.UserIdentitiesConfig{Enabled: true})
+
+	if _, err := s.CallerContext(context.Background(), "jwt:dave"); !errors.Is(err, ErrCallerIdentity) {
+		t.Errorf("CallerContext() = %v, want ErrCallerIdentity", err)
+	}
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if len(ca.registered) != 0 {
+		t.Errorf("registered = %v without autoEnroll", ca.registered)
+	}
+}
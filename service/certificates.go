@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/pathvar"
+)
+
+// 连接配置引用的证书类型
+const (
+	CertKindUser         = "user"         // 组织用户的签名证书
+	CertKindClientTLS    = "clientTLS"    // 连接 Peer 与 Orderer 的 TLS 客户端证书
+	CertKindPeerTLSCA    = "peerTLSCA"    // Peer 的 TLS CA 证书
+	CertKindOrdererTLSCA = "ordererTLSCA" // Orderer 的 TLS CA 证书
+	CertKindCATLSCA      = "caTLSCA"      // Fabric CA 的 TLS CA 证书
+	CertKindCAClientTLS  = "caClientTLS"  // 连接 Fabric CA 的 TLS 客户端证书
+)
+
+// CertificateRef 连接配置引用的证书，Err 不为空表示证书文件无法读取
+type CertificateRef struct {
+	Kind   string
+	Owner  string // 所属的组织用户（<组织>/<用户>）、节点或 CA
+	Source string // 证书文件路径，内嵌在连接配置中时为 pem
+	PEM    []byte
+	Err    error
+}
+
+// CertificateRefs 返回连接配置中引用的全部证书：组织用户的签名证书、TLS 客户端证书以及 Peer、Orderer 与 CA 的 TLS CA 证书，
+// 按类型与所属排序。用户证书只出现在 cryptoPath 目录中时不包含在内
+func (s *Fabric2Service) CertificateRefs() ([]CertificateRef, error) {
+	backend, err := s.sdk.Config()
+	if err != nil {
+		return nil, err
+	}
+	section := func(key string) map[string]interface{} {
+		value, ok := backend.Lookup(key)
+		if !ok {
+			return nil
+		}
+		m, _ := value.(map[string]interface{})
+		return m
+	}
+
+	var refs []CertificateRef
+	for orgName, org := range section("organizations") {
+		users, _ := child(org, "users").(map[string]interface{})
+		for userName, user := range users {
+			refs = append(refs, certificateRefs(CertKindUser, orgName+"/"+userName, child(user, "cert"))...)
+		}
+	}
+	refs = append(refs, certificateRefs(CertKindClientTLS, "client", child(child(section("client"), "tlsCerts"), "client", "cert"))...)
+	for name, peer := range section("peers") {
+		refs = append(refs, certificateRefs(CertKindPeerTLSCA, name, child(peer, "tlsCACerts"))...)
+	}
+	for name, orderer := range section("orderers") {
+		refs = append(refs, certificateRefs(CertKindOrdererTLSCA, name, child(orderer, "tlsCACerts"))...)
+	}
+	for name, ca := range section("certificateAuthorities") {
+		refs = append(refs, certificateRefs(CertKindCATLSCA, name, child(ca, "tlsCACerts"))...)
+		refs = append(refs, certificateRefs(CertKindCAClientTLS, name, child(ca, "tlsCACerts", "client", "cert"))...)
+	}
+	sort.SliceStable(refs, func(i, j int) bool {
+		if refs[i].Kind != refs[j].Kind {
+			return refs[i].Kind < refs[j].Kind
+		}
+		return refs[i].Owner < refs[j].Owner
+	})
+	return refs, nil
+}
+
+// child 按路径读取连接配置中的嵌套节点，不存在时返回 nil
+func child(node interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[key]
+	}
+	return node
+}
+
+// certificateRefs 读取证书节点的 pem 与 path，两者都可以是字符串或列表
+func certificateRefs(kind, owner string, node interface{}) []CertificateRef {
+	var refs []CertificateRef
+	for _, pem := range stringValues(child(node, "pem")) {
+		refs = append(refs, CertificateRef{Kind: kind, Owner: owner, Source: "pem", PEM: []byte(pem)})
+	}
+	for _, path := range stringValues(child(node, "path")) {
+		path = pathvar.Subst(path)
+		ref := CertificateRef{Kind: kind, Owner: owner, Source: path}
+		ref.PEM, ref.Err = os.ReadFile(path)
+		if errors.Is(ref.Err, os.ErrNotExist) {
+			ref.Err = fmt.Errorf("certificate file %s not found", path)
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func stringValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
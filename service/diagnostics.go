@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	mspproto "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/qctc/fabric2-api-server/model/vo"
+)
+
+// ChannelMSP 通道配置中的组织 MSP
+type ChannelMSP struct {
+	ID                string
+	RootCerts         [][]byte // PEM
+	IntermediateCerts [][]byte // PEM
+}
+
+// resourceClient 以组织管理员身份创建资源管理客户端
+func (s *Fabric2Service) resourceClient() (*resmgmt.Client, error) {
+	orgName, err := s.getOrgName()
+	if err != nil {
+		return nil, err
+	}
+	orgAdmin, err := s.getOrgAdmin(orgName)
+	if err != nil {
+		return nil, err
+	}
+	return resmgmt.New(s.sdk.Context(fabsdk.WithUser(orgAdmin), fabsdk.WithOrg(orgName)))
+}
+
+// QueryPeerChannels 查询 Peer 已加入的通道，Peer 通常只允许本组织的管理员查询
+func (s *Fabric2Service) QueryPeerChannels(ctx context.Context, peer string) ([]string, error) {
+	client, err := s.resourceClient()
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.QueryChannels(append(resmgmtOptions(ctx), resmgmt.WithTargetEndpoints(peer))...)
+	if err != nil {
+		return nil, err
+	}
+	channels := make([]string, 0, len(response.Channels))
+	for _, ch := range response.Channels {
+		channels = append(channels, ch.ChannelId)
+	}
+	return channels, nil
+}
+
+// QueryCommittedChaincodes 查询 Peer 上连接配置通道中已提交的链码
+func (s *Fabric2Service) QueryCommittedChaincodes(ctx context.Context, peer string) ([]vo.ContractVO, error) {
+	client, err := s.resourceClient()
+	if err != nil {
+		return nil, err
+	}
+	channelID, err := s.getChannelID()
+	if err != nil {
+		return nil, err
+	}
+	committed, err := client.LifecycleQueryCommittedCC(channelID, resmgmt.LifecycleQueryCommittedCCRequest{},
+		append(resmgmtOptions(ctx), resmgmt.WithTargetEndpoints(peer))...)
+	if err != nil {
+		return nil, err
+	}
+	contracts := make([]vo.ContractVO, 0, len(committed))
+	for _, cc := range committed {
+		contracts = append(contracts, vo.ContractVO{Name: cc.Name, Version: cc.Version, Sequence: cc.Sequence})
+	}
+	return contracts, nil
+}
+
+// ChannelMSPs 查询通道配置中各组织的 MSP ID 与根证书
+func (s *Fabric2Service) ChannelMSPs(ctx context.Context) ([]ChannelMSP, error) {
+	provider, err := s.channelContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client, err := ledger.New(provider)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := client.QueryConfig(ledgerOptions(ctx)...)
+	if err != nil {
+		return nil, err
+	}
+	var msps []ChannelMSP
+	for _, m := range cfg.MSPs() {
+		var fabricMSP mspproto.FabricMSPConfig
+		if err := proto.Unmarshal(m.Config, &fabricMSP); err != nil {
+			return nil, fmt.Errorf("invalid msp config in channel %s: %w", cfg.ID(), err)
+		}
+		msps = append(msps, ChannelMSP{
+			ID:                fabricMSP.Name,
+			RootCerts:         fabricMSP.RootCerts,
+			IntermediateCerts: fabricMSP.IntermediateCerts,
+		})
+	}
+	return msps, nil
+}
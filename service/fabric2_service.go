@@ -1,52 +1,149 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/hyperledger/fabric-protos-go/common"
 	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/filter"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	fabImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/qctc/fabric2-api-server/hsm"
+	"github.com/qctc/fabric2-api-server/identity"
+	"github.com/qctc/fabric2-api-server/logging"
+	"github.com/qctc/fabric2-api-server/metrics"
 	"github.com/qctc/fabric2-api-server/model/vo"
+	"gopkg.in/yaml.v3"
 )
 
 type Fabric2Service struct {
+	id  string
 	sdk *fabsdk.FabricSDK
 }
 
 var fabric2ServiceInstance *Fabric2Service
 var Fabric2ServicePool map[string]*Fabric2Service
+var poolMutex sync.RWMutex
 
 func InitFabric2Service(configString string, sdkId string, gmTls, SM3 bool) error {
-	sdk, err := fabsdk.New(
-		//config.FromFile(configPath),
-		config.FromRaw([]byte(configString), "yaml"),
-		fabsdk.WithGMTLS(gmTls),
-		fabsdk.WithSM3(SM3),
-		fabsdk.WithTxTimeStamp(false))
+	sdk, err := newSDK(configString, gmTls, SM3)
 	if err != nil {
 		return err
 	}
-	fabric2ServiceInstance = &Fabric2Service{sdk: sdk}
+
+	poolMutex.Lock()
+	defer poolMutex.Unlock()
 	if Fabric2ServicePool == nil {
 		Fabric2ServicePool = make(map[string]*Fabric2Service)
 	}
-	Fabric2ServicePool[sdkId] = &Fabric2Service{sdk: sdk}
+	// 并发初始化同一配置时保留先完成的实例
+	if _, ok := Fabric2ServicePool[sdkId]; ok {
+		sdk.Close()
+		return nil
+	}
+	fabric2ServiceInstance = &Fabric2Service{id: sdkId, sdk: sdk}
+	Fabric2ServicePool[sdkId] = fabric2ServiceInstance
+	metrics.SDKPoolSize.Set(float64(len(Fabric2ServicePool)))
+	if added := OnPoolAdd; added != nil {
+		go added(fabric2ServiceInstance)
+	}
 	return nil
 }
 
+// OnPoolAdd 连接配置加入连接池后在新的协程中调用，用于恢复等待该连接配置的订阅，为 nil 时不调用
+var OnPoolAdd func(s *Fabric2Service)
+
+// NewFabric2Service 创建不加入连接池的 SDK 实例，用于连接配置诊断等一次性检查，用完后须调用 Close
+func NewFabric2Service(configString string, gmTls, SM3 bool) (*Fabric2Service, error) {
+	sdk, err := newSDK(configString, gmTls, SM3)
+	if err != nil {
+		return nil, err
+	}
+	return &Fabric2Service{sdk: sdk}, nil
+}
+
+// Close 关闭由 NewFabric2Service 创建的 SDK 实例，连接池中的实例由 CloseAll 关闭
+func (s *Fabric2Service) Close() {
+	s.sdk.Close()
+}
+
+// newSDK 按连接配置创建 SDK，启用身份存储或 HSM 时使用相应的 MSP 与密钥实现
+func newSDK(configString string, gmTls, SM3 bool) (*fabsdk.FabricSDK, error) {
+	opts := []fabsdk.Option{
+		fabsdk.WithGMTLS(gmTls),
+		fabsdk.WithSM3(SM3),
+		fabsdk.WithTxTimeStamp(false),
+		fabsdk.WithLoggerPkg(logging.SDKLoggerProvider()),
+	}
+	// 启用托管身份存储时，组织用户优先从存储加载，连接配置中的用户可以不引用证书与私钥文件
+	if identity.Default != nil {
+		opts = append(opts, fabsdk.WithMSPPkg(identity.NewMSPProviderFactory(identity.Default)))
+	}
+	// 启用 HSM 时私钥在 HSM 中查找与签名，连接配置中的用户只需提供证书
+	if hsm.Default != nil {
+		opts = append(opts, fabsdk.WithCorePkg(hsm.Default.CoreProviderFactory()))
+	}
+	sdk, err := fabsdk.New(
+		//config.FromFile(configPath),
+		config.FromRaw([]byte(configString), "yaml"),
+		opts...)
+	if err != nil {
+		return nil, err
+	}
+	return sdk, nil
+}
+
 func GetFabric2Service(chainName string) *Fabric2Service {
+	poolMutex.RLock()
+	defer poolMutex.RUnlock()
 	return Fabric2ServicePool[chainName]
 }
 
+// List 返回连接池中的全部 SDK 实例
+func List() []*Fabric2Service {
+	poolMutex.RLock()
+	defer poolMutex.RUnlock()
+	services := make([]*Fabric2Service, 0, len(Fabric2ServicePool))
+	for _, s := range Fabric2ServicePool {
+		services = append(services, s)
+	}
+	return services
+}
+
+// CloseAll 关闭连接池中的全部 SDK 实例并清空连接池
+func CloseAll() {
+	poolMutex.Lock()
+	defer poolMutex.Unlock()
+	for sdkId, s := range Fabric2ServicePool {
+		s.sdk.Close()
+		delete(Fabric2ServicePool, sdkId)
+		slog.Info("SDK 已关闭", "profile", sdkId)
+	}
+	metrics.SDKPoolSize.Set(0)
+}
+
+// Id 连接池中的 sdkId
+func (s *Fabric2Service) Id() string {
+	return s.id
+}
+
 func (s *Fabric2Service) getOrgName() (string, error) {
 	sdkConfig, err := s.sdk.Config()
 	if err != nil {
@@ -58,12 +155,15 @@ func (s *Fabric2Service) getOrgName() (string, error) {
 		return "", errors.New("client configuration not found")
 	}
 
-	clientConfigMap := clientConfig.(map[string]interface{})
-	orgName := clientConfigMap["organization"].(string)
+	orgName, _ := child(clientConfig, "organization").(string)
+	if orgName == "" {
+		return "", errors.New("client.organization not found")
+	}
 
 	return orgName, nil
 }
 
+// getOrgAdmin 返回组织用户中按名称排序的第一个用户，作为默认的签名身份
 func (s *Fabric2Service) getOrgAdmin(orgName string) (string, error) {
 	sdkConfig, err := s.sdk.Config()
 	if err != nil {
@@ -75,15 +175,32 @@ func (s *Fabric2Service) getOrgAdmin(orgName string) (string, error) {
 		return "", errors.New("organizations configuration not found")
 	}
 
-	orgsMap := organizations.(map[string]interface{})
-	usersMap := orgsMap[orgName].(map[string]interface{})["users"].(map[string]interface{})
-	var orgAdmin string
+	orgsMap, ok := organizations.(map[string]interface{})
+	if !ok {
+		return "", errors.New("invalid organizations configuration")
+	}
+	// 连接配置的键不区分大小写
+	var org interface{}
+	for name, value := range orgsMap {
+		if strings.EqualFold(name, orgName) {
+			org = value
+			break
+		}
+	}
+	if org == nil {
+		return "", fmt.Errorf("organization %s not found", orgName)
+	}
+	usersMap, _ := child(org, "users").(map[string]interface{})
+	if len(usersMap) == 0 {
+		return "", fmt.Errorf("organization %s has no users", orgName)
+	}
+	users := make([]string, 0, len(usersMap))
 	for userName := range usersMap {
-		orgAdmin = userName
-		break
+		users = append(users, userName)
 	}
+	sort.Strings(users)
 
-	return orgAdmin, nil
+	return users[0], nil
 }
 
 func (s *Fabric2Service) getChannelID() (string, error) {
@@ -98,7 +215,10 @@ func (s *Fabric2Service) getChannelID() (string, error) {
 		return "", errors.New("channels configuration not found")
 	}
 
-	channelsMap := channelsSection.(map[string]interface{})
+	channelsMap, ok := channelsSection.(map[string]interface{})
+	if !ok {
+		return "", errors.New("invalid channels configuration")
+	}
 
 	// 遍历找到第一个 channel 名称
 	for channelID := range channelsMap {
@@ -108,6 +228,80 @@ func (s *Fabric2Service) getChannelID() (string, error) {
 	return "", errors.New("no channel found in configuration")
 }
 
+// Channels 返回连接配置中的全部通道名称
+func (s *Fabric2Service) Channels() ([]string, error) {
+	sdkConfig, err := s.sdk.Config()
+	if err != nil {
+		return nil, err
+	}
+	channelsSection, ok := sdkConfig.Lookup("channels")
+	if !ok {
+		return nil, errors.New("channels configuration not found")
+	}
+	channelsMap, ok := channelsSection.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid channels configuration")
+	}
+	channels := make([]string, 0, len(channelsMap))
+	for channelID := range channelsMap {
+		channels = append(channels, channelID)
+	}
+	return channels, nil
+}
+
+// ProfileChannels 解析连接配置中的通道名称，无需初始化 SDK
+func ProfileChannels(sdkConfig string) ([]string, error) {
+	var profile struct {
+		Channels map[string]interface{} `yaml:"channels"`
+	}
+	if err := yaml.Unmarshal([]byte(sdkConfig), &profile); err != nil {
+		return nil, err
+	}
+	channels := make([]string, 0, len(profile.Channels))
+	for channelID := range profile.Channels {
+		channels = append(channels, channelID)
+	}
+	return channels, nil
+}
+
+// Endpoints 返回连接配置中通道的 Peer 与 Orderer 地址（已按 entityMatchers 替换）
+func (s *Fabric2Service) Endpoints() (peers []string, orderers []string, err error) {
+	backend, err := s.sdk.Config()
+	if err != nil {
+		return nil, nil, err
+	}
+	endpointConfig, err := fabImpl.ConfigFromBackend(backend)
+	if err != nil {
+		return nil, nil, err
+	}
+	channelID, err := s.getChannelID()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range endpointConfig.ChannelPeers(channelID) {
+		peers = append(peers, p.URL)
+	}
+	// 通道未列出节点时使用连接配置中的全部节点
+	if len(peers) == 0 {
+		for _, p := range endpointConfig.NetworkPeers() {
+			peers = append(peers, p.URL)
+		}
+	}
+	ordererConfigs := endpointConfig.ChannelOrderers(channelID)
+	if len(ordererConfigs) == 0 {
+		ordererConfigs = endpointConfig.OrderersConfig()
+	}
+	for _, o := range ordererConfigs {
+		orderers = append(orderers, o.URL)
+	}
+	return peers, orderers, nil
+}
+
+// ChannelID 返回连接配置中的通道名称
+func (s *Fabric2Service) ChannelID() (string, error) {
+	return s.getChannelID()
+}
+
 func (s *Fabric2Service) getPeers() ([]string, error) {
 	sdkConfig, err := s.sdk.Config()
 	if err != nil {
@@ -120,7 +314,10 @@ func (s *Fabric2Service) getPeers() ([]string, error) {
 		return nil, errors.New("peers configuration not found")
 	}
 
-	peersMap := peersSection.(map[string]interface{})
+	peersMap, ok := peersSection.(map[string]interface{})
+	if !ok || len(peersMap) == 0 {
+		return nil, errors.New("no peer found in configuration")
+	}
 
 	// 提取所有 Peer 名称
 	var peerNames []string
@@ -131,56 +328,24 @@ func (s *Fabric2Service) getPeers() ([]string, error) {
 	return peerNames, nil
 }
 
-func (s *Fabric2Service) GetContractList() ([]vo.ContractVO, error) {
-	orgName, err := s.getOrgName()
-	if err != nil {
-		return nil, err
-	}
-
-	// 创建管理用户的上下文
-	orgAdmin, err := s.getOrgAdmin(orgName)
-
-	if err != nil {
-		return nil, err
-	}
-
+func (s *Fabric2Service) GetContractList(ctx context.Context) ([]vo.ContractVO, error) {
 	//获取peer
 	peers, err := s.getPeers()
 	if err != nil {
 		return nil, err
 	}
-	resourceManagerContext := s.sdk.Context(
-		fabsdk.WithUser(orgAdmin),
-		fabsdk.WithOrg(orgName))
-	// 创建 resmgmt client
-	resMgmtClient, err := resmgmt.New(resourceManagerContext)
-	if err != nil {
-		return nil, err
-	}
-	channelID, err := s.getChannelID()
-	if err != nil {
-		return nil, err
-	}
 	// 获取某个通道上已部署的 chaincode 列表
-	installedCC, err := resMgmtClient.LifecycleQueryCommittedCC(channelID, resmgmt.LifecycleQueryCommittedCCRequest{}, resmgmt.WithTargetEndpoints(peers[0]))
+	contractList, err := s.QueryCommittedChaincodes(ctx, peers[0])
 	if err != nil {
-		log.Printf("Failed to query committed chaincodes: %v", err)
+		slog.ErrorContext(ctx, "query committed chaincodes failed", "error", err)
 		return nil, err
 	}
-	contractList := make([]vo.ContractVO, 0)
-	for _, cc := range installedCC {
-		contractList = append(contractList, vo.ContractVO{
-			Name:     cc.Name,
-			Version:  cc.Version,
-			Sequence: cc.Sequence,
-		})
-	}
 
 	return contractList, nil
 }
 
 // GetContractInfo 获取合约信息
-func (s *Fabric2Service) GetContractInfo(chaincodeName string) (map[string]interface{}, error) {
+func (s *Fabric2Service) GetContractInfo(ctx context.Context, chaincodeName string) (map[string]interface{}, error) {
 	orgName, err := s.getOrgName()
 	if err != nil {
 		return nil, err
@@ -211,7 +376,7 @@ func (s *Fabric2Service) GetContractInfo(chaincodeName string) (map[string]inter
 	response, err := channelClient.Query(channel.Request{
 		ChaincodeID: chaincodeName,
 		Fcn:         "GetMetadata",
-	})
+	}, channelOptions(ctx)...)
 	if err != nil {
 		return nil, err
 	}
@@ -226,44 +391,77 @@ func (s *Fabric2Service) GetContractInfo(chaincodeName string) (map[string]inter
 	return metadata, nil
 }
 
-// SubscribeEvent 订阅合约事件
-func (s *Fabric2Service) SubscribeEvent(chaincodeName string, eventName string) (fab.Registration, <-chan *fab.BlockEvent, string, error) {
-	orgName, err := s.getOrgName()
+// EventListener 事件监听器，持有事件客户端以便使用同一客户端取消注册
+type EventListener[T any] struct {
+	client    *event.Client
+	reg       fab.Registration
+	Events    <-chan T
+	ChannelID string
+}
+
+// Close 取消事件注册并关闭事件通道
+func (l *EventListener[T]) Close() {
+	l.client.Unregister(l.reg)
+}
+
+// BlockEventListener 完整区块事件监听器
+type BlockEventListener = EventListener[*fab.BlockEvent]
+
+// FilteredBlockEventListener 过滤区块事件监听器
+type FilteredBlockEventListener = EventListener[*fab.FilteredBlockEvent]
+
+// TxStatusEventListener 交易状态事件监听器
+type TxStatusEventListener = EventListener[*fab.TxStatusEvent]
+
+// SubscribeEvent 订阅完整区块事件，需要身份具备读取完整区块的权限
+func (s *Fabric2Service) SubscribeEvent() (*BlockEventListener, error) {
+	eventClient, channelID, err := s.newEventClient(event.WithBlockEvents())
 	if err != nil {
-		return nil, nil, "", err
+		return nil, err
 	}
-
-	orgAdmin, err := s.getOrgAdmin(orgName)
+	// 注册事件监听
+	reg, eventCh, err := eventClient.RegisterBlockEvent()
 	if err != nil {
-		return nil, nil, "", err
+		return nil, err
 	}
 
-	channelID, err := s.getChannelID()
+	// 返回事件监听器，调用方需要负责在不再使用时调用 Close
+	return &BlockEventListener{client: eventClient, reg: reg, Events: eventCh, ChannelID: channelID}, nil
+}
+
+// SubscribeFilteredBlockEvent 订阅过滤区块事件，不要求读取完整区块的权限；from 不为空时从该区块开始接收，为空时只接收新区块
+func (s *Fabric2Service) SubscribeFilteredBlockEvent(from *uint64) (*FilteredBlockEventListener, error) {
+	var opts []event.ClientOption
+	if from != nil {
+		opts = append(opts, event.WithSeekType(seek.FromBlock), event.WithBlockNum(*from))
+	}
+	eventClient, channelID, err := s.newEventClient(opts...)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, err
+	}
+	reg, eventCh, err := eventClient.RegisterFilteredBlockEvent()
+	if err != nil {
+		return nil, err
 	}
 
-	eventContext := s.sdk.ChannelContext(
-		channelID,
-		fabsdk.WithUser(orgAdmin),
-		fabsdk.WithOrg(orgName),
-	)
-	eventClient, err := event.New(eventContext, event.WithBlockEvents())
+	return &FilteredBlockEventListener{client: eventClient, reg: reg, Events: eventCh, ChannelID: channelID}, nil
+}
+
+// SubscribeTxStatusEvent 订阅指定交易的提交状态
+func (s *Fabric2Service) SubscribeTxStatusEvent(txID string) (*TxStatusEventListener, error) {
+	eventClient, channelID, err := s.newEventClient()
 	if err != nil {
-		return nil, nil, "", err
+		return nil, err
 	}
-	// 注册事件监听
-	req, eventCh, err := eventClient.RegisterBlockEvent()
+	reg, eventCh, err := eventClient.RegisterTxStatusEvent(txID)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, err
 	}
 
-	// 返回事件通道，调用方需要负责管理注册和清理
-	return req, eventCh, channelID, nil
+	return &TxStatusEventListener{client: eventClient, reg: reg, Events: eventCh, ChannelID: channelID}, nil
 }
 
-// InvokeContract 执行合约调用
-func (s *Fabric2Service) InvokeContract(chaincodeName, function string, args [][]byte) ([]byte, fab.TransactionID, error) {
+func (s *Fabric2Service) newEventClient(opts ...event.ClientOption) (*event.Client, string, error) {
 	orgName, err := s.getOrgName()
 	if err != nil {
 		return nil, "", err
@@ -279,70 +477,118 @@ func (s *Fabric2Service) InvokeContract(chaincodeName, function string, args [][
 		return nil, "", err
 	}
 
-	channelContext := s.sdk.ChannelContext(
+	eventContext := s.sdk.ChannelContext(
 		channelID,
 		fabsdk.WithUser(orgAdmin),
 		fabsdk.WithOrg(orgName),
 	)
+	eventClient, err := event.New(eventContext, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+	return eventClient, channelID, nil
+}
+
+// InvokeContract 执行合约调用，ctx 结束时中止等待背书与提交；背书与排序提交分别记录为 ctx 下的子 span。
+// 失败时返回的交易 ID 不为空表示交易已进入排序提交阶段，可能已经上链
+func (s *Fabric2Service) InvokeContract(ctx context.Context, chaincodeName, function string, args [][]byte) ([]byte, fab.TransactionID, error) {
+	channelContext, err := s.channelContext(ctx)
+	if err != nil {
+		return nil, "", err
+	}
 	channelClient, err := channel.New(channelContext)
 	if err != nil {
 		return nil, "", err
 	}
-	// 执行链码调用
-	response, err := channelClient.Execute(channel.Request{
+	chCtx, err := channelContext()
+	if err != nil {
+		return nil, "", err
+	}
+	// 执行链码调用，处理链与 channelClient.Execute 相同
+	phases := newPhaseTracer(ctx)
+	handler := phases.handler("fabric.endorse", "endorse",
+		invoke.NewSelectAndEndorseHandler(
+			invoke.NewEndorsementValidationHandler(
+				invoke.NewSignatureValidationHandler(
+					phases.handler("fabric.order", phaseCommit, invoke.NewCommitHandler()),
+				),
+			),
+		),
+	)
+	response, err := channelClient.InvokeHandler(handler, channel.Request{
 		ChaincodeID: chaincodeName,
 		Fcn:         function,
 		Args:        args,
-	})
+	}, append(channelOptions(ctx), channel.WithTargetFilter(filter.NewEndpointFilter(chCtx, filter.EndorsingPeer)))...)
 	if err != nil {
-		log.Printf("execute is error %s", err)
-		return nil, "", err
+		slog.ErrorContext(ctx, "invoke contract failed", "error", err)
+		// 交易已发送排序时仍返回交易 ID，调用方据此判断交易可能已经上链
+		return nil, phases.submittedTxID(), err
 	}
 	return response.Payload, response.TransactionID, nil
 }
 
-// QueryContract 查询合约调用
-func (s *Fabric2Service) QueryContract(chaincodeName, function string, args [][]byte) ([]byte, fab.TransactionID, error) {
-	orgName, err := s.getOrgName()
+// QueryContract 查询合约调用，背书记录为 ctx 下的子 span
+func (s *Fabric2Service) QueryContract(ctx context.Context, chaincodeName, function string, args [][]byte) ([]byte, fab.TransactionID, error) {
+	channelContext, err := s.channelContext(ctx)
 	if err != nil {
 		return nil, "", err
 	}
-
-	orgAdmin, err := s.getOrgAdmin(orgName)
+	channelClient, err := channel.New(channelContext)
 	if err != nil {
 		return nil, "", err
 	}
-
-	channelID, err := s.getChannelID()
+	chCtx, err := channelContext()
 	if err != nil {
 		return nil, "", err
 	}
-
-	channelContext := s.sdk.ChannelContext(
-		channelID,
-		fabsdk.WithUser(orgAdmin),
-		fabsdk.WithOrg(orgName),
-	)
-	channelClient, err := channel.New(channelContext)
-	if err != nil {
-		return nil, "", err
+	// 执行链码调用，与 channelClient.Query 相同：只发送到可查询链码的 Peer，未指定超时时使用连接配置的查询超时
+	opts := append(channelOptions(ctx), channel.WithTargetFilter(filter.NewEndpointFilter(chCtx, filter.ChaincodeQuery)))
+	if remaining(ctx) == 0 {
+		opts = append(opts, channel.WithTimeout(fab.Query, chCtx.EndpointConfig().Timeout(fab.Query)))
 	}
-	// 执行链码调用
-	response, err := channelClient.Query(channel.Request{
+	response, err := channelClient.InvokeHandler(newPhaseTracer(ctx).handler("fabric.endorse", "query", invoke.NewQueryHandler()), channel.Request{
 		ChaincodeID: chaincodeName,
 		Fcn:         function,
 		Args:        args,
-	})
+	}, opts...)
 	if err != nil {
-		log.Printf("execute is error %s", err)
+		slog.ErrorContext(ctx, "query contract failed", "error", err)
 		return nil, "", err
 	}
 
 	return response.Payload, response.TransactionID, nil
 }
 
+// channelContext 创建连接配置中通道的上下文，ctx 指定调用方身份时以该身份签名，否则以组织管理员身份签名
+func (s *Fabric2Service) channelContext(ctx context.Context) (contextApi.ChannelProvider, error) {
+	orgName, err := s.getOrgName()
+	if err != nil {
+		return nil, err
+	}
+
+	orgAdmin := signerFromContext(ctx)
+	if orgAdmin == "" {
+		orgAdmin, err = s.getOrgAdmin(orgName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	channelID, err := s.getChannelID()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sdk.ChannelContext(
+		channelID,
+		fabsdk.WithUser(orgAdmin),
+		fabsdk.WithOrg(orgName),
+	), nil
+}
+
 // GetBlockInfo 获取区块信息
-func (s *Fabric2Service) GetBlockInfo(blockNumber string) (*common.Block, error) {
+func (s *Fabric2Service) GetBlockInfo(ctx context.Context, blockNumber string) (*common.Block, error) {
 	orgName, err := s.getOrgName()
 	if err != nil {
 		return nil, err
@@ -371,7 +617,7 @@ func (s *Fabric2Service) GetBlockInfo(blockNumber string) (*common.Block, error)
 
 	var queryBlockNumber uint64
 	if blockNumber == "latest" {
-		info, err := ledgerClient.QueryInfo()
+		info, err := ledgerClient.QueryInfo(ledgerOptions(ctx)...)
 		if err != nil {
 			return nil, err
 		}
@@ -385,7 +631,7 @@ func (s *Fabric2Service) GetBlockInfo(blockNumber string) (*common.Block, error)
 	}
 
 	// 查询指定区块信息
-	block, err := ledgerClient.QueryBlock(queryBlockNumber)
+	block, err := ledgerClient.QueryBlock(queryBlockNumber, ledgerOptions(ctx)...)
 	if err != nil {
 		return nil, err
 	}
@@ -393,64 +639,32 @@ func (s *Fabric2Service) GetBlockInfo(blockNumber string) (*common.Block, error)
 	return block, nil
 }
 
-// UnsubscribeEvent 取消事件订阅（需要传递注册ID）
-func (s *Fabric2Service) UnsubscribeEvent(regID fab.Registration) error {
+func (s *Fabric2Service) TestConnection(ctx context.Context) error {
 	orgName, err := s.getOrgName()
 	if err != nil {
+		slog.WarnContext(ctx, "test connection: get organization name failed", "error", err)
 		return err
 	}
-
 	orgAdmin, err := s.getOrgAdmin(orgName)
 	if err != nil {
-		return err
-	}
-	channelID, err := s.getChannelID()
-	if err != nil {
-		return err
-	}
-
-	eventContext := s.sdk.ChannelContext(
-		channelID,
-		fabsdk.WithUser(orgAdmin),
-		fabsdk.WithOrg(orgName),
-	)
-
-	eventClient, err := event.New(eventContext)
-	if err != nil {
-		return err
-	}
-
-	// 取消事件注册
-	eventClient.Unregister(regID)
-	return nil
-}
-
-func (s *Fabric2Service) TestConnection() error {
-	orgName, err := s.getOrgName()
-	if err != nil {
-		log.Println("Failed to get organization name")
-		return err
-	}
-	orgAdmin, err := s.getOrgAdmin(orgName)
-	if err != nil {
-		log.Println("Failed to get organization admin")
+		slog.WarnContext(ctx, "test connection: get organization admin failed", "error", err)
 		return err
 	}
 
 	// 创建管理用户上下文
-	ctx := s.sdk.Context(fabsdk.WithUser(orgAdmin), fabsdk.WithOrg(orgName))
+	adminContext := s.sdk.Context(fabsdk.WithUser(orgAdmin), fabsdk.WithOrg(orgName))
 
 	// 创建资源管理客户端（用于与排序节点通信）
-	resMgmtClient, err := resmgmt.New(ctx)
+	resMgmtClient, err := resmgmt.New(adminContext)
 	if err != nil {
-		log.Println("Failed to create resource management client")
+		slog.WarnContext(ctx, "test connection: create resource management client failed", "error", err)
 		return err
 	}
 
 	// 查询通道信息（实际发送请求，验证是否能正常通信）
-	channels, err := resMgmtClient.QueryChannels()
+	channels, err := resMgmtClient.QueryChannels(resmgmtOptions(ctx)...)
 	if err != nil {
-		log.Println("Failed to query channels")
+		slog.WarnContext(ctx, "test connection: query channels failed", "error", err)
 		return err
 	}
 	if len(channels.Channels) > 0 {
@@ -461,7 +675,7 @@ func (s *Fabric2Service) TestConnection() error {
 }
 
 // GetTransactionInfo 获取指定交易的详细信息
-func (s *Fabric2Service) GetTransactionInfo(txID string) (*pb.ProcessedTransaction, error) {
+func (s *Fabric2Service) GetTransactionInfo(ctx context.Context, txID string) (*pb.ProcessedTransaction, error) {
 	orgName, err := s.getOrgName()
 	if err != nil {
 		return nil, err
@@ -488,7 +702,7 @@ func (s *Fabric2Service) GetTransactionInfo(txID string) (*pb.ProcessedTransacti
 	}
 
 	// 查询交易详情
-	tx, err := ledgerClient.QueryTransaction(fab.TransactionID(txID))
+	tx, err := ledgerClient.QueryTransaction(fab.TransactionID(txID), ledgerOptions(ctx)...)
 	if err != nil {
 		return nil, err
 	}
@@ -497,7 +711,7 @@ func (s *Fabric2Service) GetTransactionInfo(txID string) (*pb.ProcessedTransacti
 }
 
 // GetBlockByTxID 获取区块高度
-func (s *Fabric2Service) GetBlockByTxID(txID string) (uint64, error) {
+func (s *Fabric2Service) GetBlockByTxID(ctx context.Context, txID string) (uint64, error) {
 	orgName, err := s.getOrgName()
 	if err != nil {
 		return 0, err
@@ -524,7 +738,7 @@ func (s *Fabric2Service) GetBlockByTxID(txID string) (uint64, error) {
 	}
 
 	// 查询交易详情
-	blockInfo, err := ledgerClient.QueryBlockByTxID(fab.TransactionID(txID))
+	blockInfo, err := ledgerClient.QueryBlockByTxID(fab.TransactionID(txID), ledgerOptions(ctx)...)
 	if err != nil {
 		return 0, err
 	}
@@ -532,7 +746,43 @@ func (s *Fabric2Service) GetBlockByTxID(txID string) (uint64, error) {
 	return blockInfo.GetHeader().GetNumber(), nil
 }
 
-func (s *Fabric2Service) GetBlocks(startNumber string) ([]*common.Block, error) {
+// GetBlockHeight 获取当前账本高度
+func (s *Fabric2Service) GetBlockHeight(ctx context.Context) (uint64, error) {
+	orgName, err := s.getOrgName()
+	if err != nil {
+		return 0, err
+	}
+
+	orgAdmin, err := s.getOrgAdmin(orgName)
+	if err != nil {
+		return 0, err
+	}
+
+	channelID, err := s.getChannelID()
+	if err != nil {
+		return 0, err
+	}
+
+	ledgerContext := s.sdk.ChannelContext(
+		channelID,
+		fabsdk.WithUser(orgAdmin),
+		fabsdk.WithOrg(orgName),
+	)
+
+	ledgerClient, err := ledger.New(ledgerContext)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := ledgerClient.QueryInfo(ledgerOptions(ctx)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.BCI.Height, nil
+}
+
+func (s *Fabric2Service) GetBlocks(ctx context.Context, startNumber string) ([]*common.Block, error) {
 	orgName, err := s.getOrgName()
 	if err != nil {
 		return nil, err
@@ -561,7 +811,7 @@ func (s *Fabric2Service) GetBlocks(startNumber string) ([]*common.Block, error)
 
 	var queryBlockNumber uint64
 	var startNumberUint uint64
-	info, err := ledgerClient.QueryInfo()
+	info, err := ledgerClient.QueryInfo(ledgerOptions(ctx)...)
 	if err != nil {
 		return nil, err
 	}
@@ -579,7 +829,11 @@ func (s *Fabric2Service) GetBlocks(startNumber string) ([]*common.Block, error)
 	// 查询指定区块信息
 	var blocks []*common.Block
 	for queryBlockNumber = startNumberUint; queryBlockNumber <= latestNumberUint; queryBlockNumber++ {
-		block, err := ledgerClient.QueryBlock(queryBlockNumber)
+		// 调用方断开或超时后不再读取剩余区块
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block, err := ledgerClient.QueryBlock(queryBlockNumber, ledgerOptions(ctx)...)
 		if err != nil {
 			return nil, err
 		}
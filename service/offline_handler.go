@@ -0,0 +1,124 @@
+package service
+
+import (
+	reqContext "context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+)
+
+// signedProposalHandler 将调用方签名的提案发送给选定的背书节点，替代 SDK 以上下文身份签名提案的 EndorsementHandler
+type signedProposalHandler struct {
+	proposal *fab.TransactionProposal
+	signed   *pb.SignedProposal
+	next     invoke.Handler
+}
+
+func (h *signedProposalHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	targets := requestContext.Opts.Targets
+	if len(targets) == 0 {
+		requestContext.Error = status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "targets were not provided", nil)
+		return
+	}
+	requestContext.Response.Proposal = h.proposal
+	requestContext.Response.TransactionID = h.proposal.TxnID
+
+	var mu sync.Mutex
+	var responses []*fab.TransactionProposalResponse
+	var errs multi.Errors
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		wg.Add(1)
+		go func(peer fab.Peer) {
+			defer wg.Done()
+			resp, err := peer.ProcessTransactionProposal(requestContext.Ctx, fab.ProcessProposalRequest{SignedProposal: h.signed})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			responses = append(responses, resp)
+		}(target)
+	}
+	wg.Wait()
+	if err := errs.ToError(); err != nil {
+		requestContext.Error = err
+		return
+	}
+
+	requestContext.Response.Responses = responses
+	requestContext.Response.Payload = responses[0].ProposalResponse.GetResponse().Payload
+	requestContext.Response.ChaincodeStatus = responses[0].ChaincodeStatus
+	if h.next != nil {
+		h.next.Handle(requestContext, clientContext)
+	}
+}
+
+// signedEnvelopeHandler 将调用方签名的交易发送排序并等待提交事件，替代 SDK 以上下文身份签名交易的 CommitTxHandler
+type signedEnvelopeHandler struct {
+	txID     fab.TransactionID
+	envelope *fab.SignedEnvelope
+	orderers []fab.Orderer
+	// sent 交易已发送排序；调用超时返回时处理链可能仍在执行，因此使用原子变量
+	sent        atomic.Bool
+	blockNumber uint64
+}
+
+func (h *signedEnvelopeHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	requestContext.Response.TransactionID = h.txID
+	reg, statusNotifier, err := clientContext.EventService.RegisterTxStatusEvent(string(h.txID))
+	if err != nil {
+		requestContext.Error = fmt.Errorf("error registering for TxStatus event: %w", err)
+		return
+	}
+	defer clientContext.EventService.Unregister(reg)
+
+	h.sent.Store(true)
+	if err := h.broadcast(requestContext.Ctx); err != nil {
+		requestContext.Error = err
+		return
+	}
+
+	select {
+	case txStatus := <-statusNotifier:
+		requestContext.Response.TxValidationCode = txStatus.TxValidationCode
+		if txStatus.TxValidationCode != pb.TxValidationCode_VALID {
+			requestContext.Error = status.New(status.EventServerStatus, int32(txStatus.TxValidationCode),
+				"received invalid transaction", nil)
+			return
+		}
+		h.blockNumber = txStatus.BlockNumber
+	case <-requestContext.Ctx.Done():
+		requestContext.Error = status.New(status.ClientStatus, status.Timeout.ToInt32(),
+			"Execute didn't receive block event", nil)
+	}
+}
+
+// broadcast 按随机顺序依次尝试排序节点，直到一个节点接受交易
+func (h *signedEnvelopeHandler) broadcast(reqCtx reqContext.Context) error {
+	client, ok := contextImpl.RequestClientContext(reqCtx)
+	if !ok {
+		return fmt.Errorf("failed get client context from reqContext for broadcast")
+	}
+	var errs multi.Errors
+	for _, i := range rand.Perm(len(h.orderers)) {
+		orderer := h.orderers[i]
+		childCtx, cancel := contextImpl.NewRequest(client, contextImpl.WithTimeoutType(fab.OrdererResponse), contextImpl.WithParent(reqCtx))
+		_, err := orderer.SendBroadcast(childCtx, h.envelope)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("calling orderer '%s' failed: %w", orderer.URL(), err))
+	}
+	return errs.ToError()
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	mspproto "github.com/hyperledger/fabric-protos-go/msp"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/filter"
+	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
+	"github.com/qctc/fabric2-api-server/identity"
+	"github.com/qctc/fabric2-api-server/model/vo"
+)
+
+// ErrInvalidTransaction 调用方提交的提案、交易或签名不合法
+var ErrInvalidTransaction = errors.New("invalid transaction")
+
+// 待签名摘要的哈希算法，与连接配置的 SM3 选项一致
+const (
+	HashSHA256 = "SHA256"
+	HashSM3    = "SM3"
+)
+
+// 离线签名流程：调用方持有私钥，服务只组装提案与交易、转发背书与排序，不接触调用方私钥。
+//  1. CreateProposal 以调用方证书创建未签名的提案，调用方对返回的摘要签名；
+//  2. EndorseProposal 校验提案签名后发送给背书节点，返回未签名的交易，调用方再对交易摘要签名；
+//  3. SubmitTransaction 校验交易签名后发送排序并等待提交。
+// 提案与交易均由调用方原样带回，服务不保存中间状态。背书与排序使用的网络连接仍来自连接配置的组织身份。
+
+// CreateProposal 以调用方证书创建未签名的合约调用提案，mspID 为空时使用连接配置 client.organization 的 MSP ID
+func (s *Fabric2Service) CreateProposal(ctx context.Context, mspID string, certPEM []byte, chaincodeName, function string, args [][]byte) (*vo.OfflineProposalVO, error) {
+	keyType, err := identity.CertificateKeyType(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTransaction, err)
+	}
+	channelContext, err := s.channelContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chCtx, err := channelContext()
+	if err != nil {
+		return nil, err
+	}
+	if mspID == "" {
+		orgName, err := s.getOrgName()
+		if err != nil {
+			return nil, err
+		}
+		org, ok := chCtx.EndpointConfig().NetworkConfig().Organizations[strings.ToLower(orgName)]
+		if !ok {
+			return nil, fmt.Errorf("non-existent organization: '%s'", orgName)
+		}
+		mspID = org.MSPID
+	}
+	creator, err := proto.Marshal(&mspproto.SerializedIdentity{Mspid: mspID, IdBytes: certPEM})
+	if err != nil {
+		return nil, err
+	}
+	txh, err := txn.NewHeader(chCtx, chCtx.ChannelID(), fab.WithCreator(creator))
+	if err != nil {
+		return nil, err
+	}
+	proposal, err := txn.CreateChaincodeInvokeProposal(txh, fab.ChaincodeInvokeRequest{
+		ChaincodeID: chaincodeName,
+		Fcn:         function,
+		Args:        args,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTransaction, err)
+	}
+	proposalBytes, err := proto.Marshal(proposal.Proposal)
+	if err != nil {
+		return nil, err
+	}
+	digest, hashAlgorithm, err := signingDigest(chCtx, proposalBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &vo.OfflineProposalVO{
+		TxHash:        string(proposal.TxnID),
+		Proposal:      proposalBytes,
+		Digest:        digest,
+		HashAlgorithm: hashAlgorithm,
+		KeyType:       keyType,
+	}, nil
+}
+
+// EndorseProposal 校验调用方对提案的签名，发送给背书节点并校验背书结果一致，返回未签名的交易
+func (s *Fabric2Service) EndorseProposal(ctx context.Context, proposalBytes, signature []byte) (*vo.OfflineTransactionVO, error) {
+	proposal := &pb.Proposal{}
+	if err := proto.Unmarshal(proposalBytes, proposal); err != nil {
+		return nil, fmt.Errorf("%w: unmarshal proposal: %v", ErrInvalidTransaction, err)
+	}
+	header := &common.Header{}
+	if err := proto.Unmarshal(proposal.Header, header); err != nil {
+		return nil, fmt.Errorf("%w: unmarshal header: %v", ErrInvalidTransaction, err)
+	}
+	channelHeader, creator, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	chaincodeName, function, err := parseInvocation(proposal.Payload)
+	if err != nil {
+		return nil, err
+	}
+	channelContext, err := s.channelContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chCtx, err := channelContext()
+	if err != nil {
+		return nil, err
+	}
+	if channelHeader.ChannelId != chCtx.ChannelID() {
+		return nil, fmt.Errorf("%w: proposal is for channel %q, profile uses %q", ErrInvalidTransaction, channelHeader.ChannelId, chCtx.ChannelID())
+	}
+	signature, err = verifySignature(chCtx, creator, proposalBytes, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	channelClient, err := channel.New(channelContext)
+	if err != nil {
+		return nil, err
+	}
+	txID := fab.TransactionID(channelHeader.TxId)
+	handler := newPhaseTracer(ctx).handler("fabric.endorse", "endorse",
+		invoke.NewProposalProcessorHandler(
+			&signedProposalHandler{
+				proposal: &fab.TransactionProposal{TxnID: txID, Proposal: proposal},
+				signed:   &pb.SignedProposal{ProposalBytes: proposalBytes, Signature: signature},
+				next:     invoke.NewEndorsementValidationHandler(),
+			},
+		),
+	)
+	response, err := channelClient.InvokeHandler(handler, channel.Request{
+		ChaincodeID: chaincodeName,
+		Fcn:         function,
+	}, append(channelOptions(ctx), channel.WithTargetFilter(filter.NewEndpointFilter(chCtx, filter.EndorsingPeer)))...)
+	if err != nil {
+		slog.ErrorContext(ctx, "endorse signed proposal failed", "txId", txID, "error", err)
+		return nil, err
+	}
+
+	tx, err := txn.New(fab.TransactionRequest{Proposal: response.Proposal, ProposalResponses: response.Responses})
+	if err != nil {
+		return nil, err
+	}
+	txBytes, err := proto.Marshal(tx.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	payloadBytes, err := proto.Marshal(&common.Payload{Header: header, Data: txBytes})
+	if err != nil {
+		return nil, err
+	}
+	digest, hashAlgorithm, err := signingDigest(chCtx, payloadBytes)
+	if err != nil {
+		return nil, err
+	}
+	endorsers := make([]string, 0, len(response.Responses))
+	for _, r := range response.Responses {
+		endorsers = append(endorsers, r.Endorser)
+	}
+	return &vo.OfflineTransactionVO{
+		TxHash:        string(txID),
+		Payload:       payloadBytes,
+		Digest:        digest,
+		HashAlgorithm: hashAlgorithm,
+		Result:        string(response.Payload),
+		Endorsers:     endorsers,
+	}, nil
+}
+
+// SubmitTransaction 校验调用方对交易的签名，发送排序并等待交易提交，返回交易所在的区块。
+// 失败时返回的交易 ID 不为空表示交易已发送排序，可能已经上链；同一交易重复提交时节点判定为重复交易，不会重复上链
+func (s *Fabric2Service) SubmitTransaction(ctx context.Context, payloadBytes, signature []byte) (*vo.OfflineCommitVO, fab.TransactionID, error) {
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(payloadBytes, payload); err != nil {
+		return nil, "", fmt.Errorf("%w: unmarshal payload: %v", ErrInvalidTransaction, err)
+	}
+	if payload.Header == nil {
+		return nil, "", fmt.Errorf("%w: payload header is missing", ErrInvalidTransaction)
+	}
+	channelHeader, creator, err := parseHeader(payload.Header)
+	if err != nil {
+		return nil, "", err
+	}
+	if channelHeader.Type != int32(common.HeaderType_ENDORSER_TRANSACTION) {
+		return nil, "", fmt.Errorf("%w: payload is not an endorser transaction", ErrInvalidTransaction)
+	}
+	chaincodeName, function, err := parseTransactionInvocation(payload.Data)
+	if err != nil {
+		return nil, "", err
+	}
+	channelContext, err := s.channelContext(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	chCtx, err := channelContext()
+	if err != nil {
+		return nil, "", err
+	}
+	if channelHeader.ChannelId != chCtx.ChannelID() {
+		return nil, "", fmt.Errorf("%w: transaction is for channel %q, profile uses %q", ErrInvalidTransaction, channelHeader.ChannelId, chCtx.ChannelID())
+	}
+	signature, err = verifySignature(chCtx, creator, payloadBytes, signature)
+	if err != nil {
+		return nil, "", err
+	}
+	orderers, err := channelOrderers(chCtx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	channelClient, err := channel.New(channelContext)
+	if err != nil {
+		return nil, "", err
+	}
+	txID := fab.TransactionID(channelHeader.TxId)
+	broadcast := &signedEnvelopeHandler{
+		txID:     txID,
+		envelope: &fab.SignedEnvelope{Payload: payloadBytes, Signature: signature},
+		orderers: orderers,
+	}
+	_, err = channelClient.InvokeHandler(newPhaseTracer(ctx).handler("fabric.order", phaseCommit, broadcast), channel.Request{
+		ChaincodeID: chaincodeName,
+		Fcn:         function,
+	}, channelOptions(ctx)...)
+	if err != nil {
+		slog.ErrorContext(ctx, "submit signed transaction failed", "txId", txID, "error", err)
+		if broadcast.sent.Load() {
+			return nil, txID, err
+		}
+		return nil, "", err
+	}
+	return &vo.OfflineCommitVO{
+		TxHash:         string(txID),
+		Height:         broadcast.blockNumber,
+		ValidationCode: pb.TxValidationCode_VALID.String(),
+	}, txID, nil
+}
+
+// signingDigest 计算调用方需要签名的摘要，哈希算法与 SDK 签名一致
+func signingDigest(ctx contextApi.Client, msg []byte) ([]byte, string, error) {
+	opts, name := cryptosuite.GetSHAOpts(), HashSHA256
+	if ctx.InfraProvider().IsSm3() {
+		opts, name = cryptosuite.GetSM3Opts(), HashSM3
+	}
+	digest, err := ctx.CryptoSuite().Hash(msg, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return digest, name, nil
+}
+
+// verifySignature 以签名头中创建者的证书校验签名，返回节点接受的签名
+func verifySignature(ctx contextApi.Client, creator *mspproto.SerializedIdentity, msg, signature []byte) ([]byte, error) {
+	digest, _, err := signingDigest(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	signature, err = identity.VerifySignature(creator.IdBytes, digest, signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTransaction, err)
+	}
+	return signature, nil
+}
+
+// parseHeader 解析提案或交易头部的通道头与签名头中的创建者
+func parseHeader(header *common.Header) (*common.ChannelHeader, *mspproto.SerializedIdentity, error) {
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(header.ChannelHeader, channelHeader); err != nil {
+		return nil, nil, fmt.Errorf("%w: unmarshal channel header: %v", ErrInvalidTransaction, err)
+	}
+	signatureHeader := &common.SignatureHeader{}
+	if err := proto.Unmarshal(header.SignatureHeader, signatureHeader); err != nil {
+		return nil, nil, fmt.Errorf("%w: unmarshal signature header: %v", ErrInvalidTransaction, err)
+	}
+	creator := &mspproto.SerializedIdentity{}
+	if err := proto.Unmarshal(signatureHeader.Creator, creator); err != nil {
+		return nil, nil, fmt.Errorf("%w: unmarshal creator: %v", ErrInvalidTransaction, err)
+	}
+	if channelHeader.TxId == "" || channelHeader.ChannelId == "" {
+		return nil, nil, fmt.Errorf("%w: txId and channel are required in channel header", ErrInvalidTransaction)
+	}
+	return channelHeader, creator, nil
+}
+
+// ProposalInvocation 解析提案调用的合约名称与方法，用于在背书前按调用方授权
+func ProposalInvocation(proposalBytes []byte) (chaincodeName, function string, err error) {
+	proposal := &pb.Proposal{}
+	if err := proto.Unmarshal(proposalBytes, proposal); err != nil {
+		return "", "", fmt.Errorf("%w: unmarshal proposal: %v", ErrInvalidTransaction, err)
+	}
+	return parseInvocation(proposal.Payload)
+}
+
+// TransactionInvocation 解析交易调用的合约名称与方法，用于在排序前按调用方授权
+func TransactionInvocation(payloadBytes []byte) (chaincodeName, function string, err error) {
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(payloadBytes, payload); err != nil {
+		return "", "", fmt.Errorf("%w: unmarshal payload: %v", ErrInvalidTransaction, err)
+	}
+	return parseTransactionInvocation(payload.Data)
+}
+
+// parseInvocation 从提案的 ChaincodeProposalPayload 中解析合约名称与方法
+func parseInvocation(proposalPayload []byte) (chaincodeName, function string, err error) {
+	payload := &pb.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposalPayload, payload); err != nil {
+		return "", "", fmt.Errorf("%w: unmarshal proposal payload: %v", ErrInvalidTransaction, err)
+	}
+	spec := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(payload.Input, spec); err != nil {
+		return "", "", fmt.Errorf("%w: unmarshal chaincode invocation: %v", ErrInvalidTransaction, err)
+	}
+	chaincodeSpec := spec.GetChaincodeSpec()
+	args := chaincodeSpec.GetInput().GetArgs()
+	if chaincodeSpec.GetChaincodeId().GetName() == "" || len(args) == 0 {
+		return "", "", fmt.Errorf("%w: chaincode name and function are required", ErrInvalidTransaction)
+	}
+	return chaincodeSpec.GetChaincodeId().GetName(), string(args[0]), nil
+}
+
+// parseTransactionInvocation 从交易的第一个动作中解析合约名称与方法
+func parseTransactionInvocation(data []byte) (chaincodeName, function string, err error) {
+	tx := &pb.Transaction{}
+	if err := proto.Unmarshal(data, tx); err != nil {
+		return "", "", fmt.Errorf("%w: unmarshal transaction: %v", ErrInvalidTransaction, err)
+	}
+	if len(tx.Actions) == 0 {
+		return "", "", fmt.Errorf("%w: transaction has no actions", ErrInvalidTransaction)
+	}
+	actionPayload := &pb.ChaincodeActionPayload{}
+	if err := proto.Unmarshal(tx.Actions[0].Payload, actionPayload); err != nil {
+		return "", "", fmt.Errorf("%w: unmarshal chaincode action payload: %v", ErrInvalidTransaction, err)
+	}
+	if actionPayload.Action == nil || len(actionPayload.Action.Endorsements) == 0 {
+		return "", "", fmt.Errorf("%w: transaction has no endorsements", ErrInvalidTransaction)
+	}
+	return parseInvocation(actionPayload.ChaincodeProposalPayload)
+}
+
+// channelOrderers 创建连接配置中通道的排序节点，通道未列出排序节点时使用连接配置中的全部排序节点
+func channelOrderers(ctx contextApi.Channel) ([]fab.Orderer, error) {
+	configs := ctx.EndpointConfig().ChannelOrderers(ctx.ChannelID())
+	if len(configs) == 0 {
+		configs = ctx.EndpointConfig().OrderersConfig()
+	}
+	if len(configs) == 0 {
+		return nil, errors.New("no orderers configured")
+	}
+	orderers := make([]fab.Orderer, 0, len(configs))
+	for i := range configs {
+		o, err := ctx.InfraProvider().CreateOrdererFromConfig(ctx.InfraProvider().IsGMTLS(), &configs[i])
+		if err != nil {
+			return nil, fmt.Errorf("create orderer %s: %w", configs[i].URL, err)
+		}
+		orderers = append(orderers, o)
+	}
+	return orderers, nil
+}
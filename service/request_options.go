@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// 以下函数将请求的 ctx 转换为 SDK 的请求选项：ctx 作为 SDK 请求的父上下文，调用方断开或超时时 SDK 中止进行中的调用；
+// ctx 有截止时间时以剩余时间替代连接配置中的默认超时，使调用方指定的超时可以长于或短于默认值。
+
+// remaining 返回 ctx 距截止时间的剩余时间，没有截止时间或已经超时时返回 0，此时使用连接配置中的默认超时
+func remaining(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+	if d := time.Until(deadline); d > 0 {
+		return d
+	}
+	return 0
+}
+
+func channelOptions(ctx context.Context) []channel.RequestOption {
+	opts := []channel.RequestOption{channel.WithParentContext(ctx)}
+	if d := remaining(ctx); d > 0 {
+		opts = append(opts, channel.WithTimeout(fab.Execute, d), channel.WithTimeout(fab.Query, d))
+	}
+	return opts
+}
+
+func ledgerOptions(ctx context.Context) []ledger.RequestOption {
+	opts := []ledger.RequestOption{ledger.WithParentContext(ctx)}
+	if d := remaining(ctx); d > 0 {
+		opts = append(opts, ledger.WithTimeout(fab.PeerResponse, d))
+	}
+	return opts
+}
+
+func resmgmtOptions(ctx context.Context) []resmgmt.RequestOption {
+	opts := []resmgmt.RequestOption{resmgmt.WithParentContext(ctx)}
+	if d := remaining(ctx); d > 0 {
+		opts = append(opts, resmgmt.WithTimeout(fab.ResMgmt, d), resmgmt.WithTimeout(fab.PeerResponse, d))
+	}
+	return opts
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/qctc/fabric2-api-server/metrics"
+	"github.com/qctc/fabric2-api-server/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// phaseTracer 为一次合约调用的各阶段（背书、排序提交）创建首尾相接的 span，并记录各阶段的耗时指标。
+// SDK 在同一协程中依次执行处理链，后一阶段开始即表示前一阶段成功结束；
+// 处理链中途出错时不再调用后续处理器，由最外层阶段返回时以该错误结束当前 span。
+type phaseTracer struct {
+	ctx     context.Context
+	current *phase
+	// submitted 进入排序提交阶段的交易 ID；调用超时返回时处理链可能仍在执行，因此使用原子变量
+	submitted atomic.Value
+}
+
+// phase 进行中的阶段
+type phase struct {
+	name  string
+	span  trace.Span
+	start time.Time
+}
+
+func newPhaseTracer(ctx context.Context) *phaseTracer {
+	return &phaseTracer{ctx: ctx}
+}
+
+// handler 在 next 之前插入阶段，span 名为 spanName，耗时指标的 phase 标签为 name
+func (t *phaseTracer) handler(spanName, name string, next invoke.Handler) invoke.Handler {
+	return &phaseHandler{tracer: t, spanName: spanName, name: name, next: next}
+}
+
+// submittedTxID 返回已进入排序提交阶段的交易 ID，尚未提交时返回空字符串
+func (t *phaseTracer) submittedTxID() fab.TransactionID {
+	txID, _ := t.submitted.Load().(fab.TransactionID)
+	return txID
+}
+
+func (t *phaseTracer) end(requestContext *invoke.RequestContext) {
+	p := t.current
+	if p == nil {
+		return
+	}
+	t.current = nil
+	if txID := requestContext.Response.TransactionID; txID != "" {
+		p.span.SetAttributes(attribute.String("fabric.tx_id", string(txID)))
+	}
+	tracing.End(p.span, requestContext.Error)
+	metrics.ObserveFabric(p.name, requestContext.Request.ChaincodeID, requestContext.Request.Fcn, p.start, requestContext.Error)
+}
+
+type phaseHandler struct {
+	tracer   *phaseTracer
+	spanName string
+	name     string
+	next     invoke.Handler
+}
+
+// phaseCommit 排序提交阶段，开始时交易即可能被发送到排序节点
+const phaseCommit = "commit"
+
+func (h *phaseHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	h.tracer.end(requestContext)
+	if h.name == phaseCommit {
+		h.tracer.submitted.Store(requestContext.Response.TransactionID)
+	}
+	_, span := tracing.Start(h.tracer.ctx, h.spanName,
+		attribute.String("fabric.chaincode", requestContext.Request.ChaincodeID),
+		attribute.String("fabric.function", requestContext.Request.Fcn))
+	h.tracer.current = &phase{name: h.name, span: span, start: time.Now()}
+	h.next.Handle(requestContext, clientContext)
+	h.tracer.end(requestContext)
+}
@@ -0,0 +1,207 @@
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/service"
+)
+
+// Checkpoint 订阅断点，进程重启后从最后投递的区块之后恢复订阅。断点只保存连接配置标识与订阅条件，
+// 不保存连接配置，订阅在同一连接配置加入连接池后恢复
+type Checkpoint struct {
+	SdkId              string  `json:"sdkId"`
+	IsGm               bool    `json:"isGM,omitempty"`
+	IsSM3              bool    `json:"isSM3,omitempty"`
+	Type               string  `json:"type"`
+	ChaincodeName      string  `json:"chaincodeName,omitempty"`
+	EventName          string  `json:"eventName,omitempty"`
+	ChainName          string  `json:"chainName,omitempty"`
+	TxId               string  `json:"txId,omitempty"`
+	Status             Status  `json:"status"`
+	LastDeliveredBlock *uint64 `json:"lastDeliveredBlock,omitempty"`
+}
+
+// checkpoint 返回订阅当前的断点
+func (s *Subscription) checkpoint() Checkpoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cp := Checkpoint{
+		SdkId:         s.sdkId,
+		IsGm:          s.request.IsGm,
+		IsSM3:         s.request.IsSM3,
+		Type:          s.spec.Type,
+		ChaincodeName: s.spec.ChaincodeName,
+		EventName:     s.spec.EventName,
+		ChainName:     s.spec.ChainName,
+		TxId:          s.spec.TxId,
+		Status:        s.status,
+	}
+	if s.hasDelivered {
+		lastDelivered := s.lastDelivered
+		cp.LastDeliveredBlock = &lastDelivered
+	}
+	return cp
+}
+
+// request 由断点生成订阅请求，已投递过区块时从下一个区块开始
+func (cp Checkpoint) request() define.ContractEventSubscribeRequest {
+	req := define.ContractEventSubscribeRequest{
+		IsGm:          cp.IsGm,
+		IsSM3:         cp.IsSM3,
+		Type:          cp.Type,
+		ChaincodeName: cp.ChaincodeName,
+		EventName:     cp.EventName,
+		ChainName:     cp.ChainName,
+		TxId:          cp.TxId,
+	}
+	if cp.LastDeliveredBlock != nil {
+		req.FromBlock = strconv.FormatUint(*cp.LastDeliveredBlock+1, 10)
+	}
+	return req
+}
+
+// Shutdown 停止全部订阅并等待正在进行的投递完成，checkpointFile 不为空时写入订阅断点，
+// 仍在等待连接配置的断点原样写回
+func (m *Manager) Shutdown(ctx context.Context, checkpointFile string) error {
+	checkpoints := m.takePending()
+	var errs []error
+	for _, sub := range m.List() {
+		m.remove(sub.id)
+		if err := sub.stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop subscription %s: %w", sub.id, err))
+		}
+		if sub.currentStatus() != StatusCompleted {
+			checkpoints = append(checkpoints, sub.checkpoint())
+		}
+		slog.Info("已取消订阅", "subscription", sub.id)
+	}
+	if checkpointFile != "" {
+		if err := writeCheckpoints(checkpointFile, checkpoints); err != nil {
+			errs = append(errs, err)
+		} else {
+			slog.Info("已保存订阅断点", "count", len(checkpoints), "file", checkpointFile)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Restore 从断点文件恢复订阅，文件不存在时直接返回。连接配置尚未加入连接池的订阅等待 RestorePending 恢复
+func (m *Manager) Restore(checkpointFile string, sink Sink) error {
+	if checkpointFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(checkpointFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var checkpoints []Checkpoint
+	if err := json.Unmarshal(data, &checkpoints); err != nil {
+		return fmt.Errorf("parse checkpoint file %s: %w", checkpointFile, err)
+	}
+
+	m.mu.Lock()
+	m.sink = sink
+	m.mu.Unlock()
+	var errs []error
+	for _, cp := range checkpoints {
+		sdk := m.loadedOrPending(cp)
+		if sdk == nil {
+			slog.Info("连接配置尚未加载，加入连接池后恢复订阅", "profile", cp.SdkId, "subscription", Key(cp.SdkId, SubscribeSpec(cp.request())))
+			continue
+		}
+		if err := m.restore(cp, sdk, sink); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RestorePending 恢复等待连接配置 sdk 加入连接池的订阅，恢复失败的断点继续等待并在关闭时写回
+func (m *Manager) RestorePending(sdk *service.Fabric2Service) {
+	m.mu.Lock()
+	checkpoints, sink := m.pending[sdk.Id()], m.sink
+	delete(m.pending, sdk.Id())
+	m.mu.Unlock()
+	for _, cp := range checkpoints {
+		if err := m.restore(cp, sdk, sink); err != nil {
+			slog.Error("恢复订阅失败", "profile", sdk.Id(), "error", err)
+			m.mu.Lock()
+			m.pending[cp.SdkId] = append(m.pending[cp.SdkId], cp)
+			m.mu.Unlock()
+		}
+	}
+}
+
+// loadedOrPending 返回断点连接配置在连接池中的 SDK 实例，尚未加入连接池时登记为等待恢复并返回 nil。
+// 持有锁时检查连接池，连接配置同时加入连接池时 RestorePending 在登记之后执行
+func (m *Manager) loadedOrPending(cp Checkpoint) *service.Fabric2Service {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sdk := service.GetFabric2Service(cp.SdkId); sdk != nil {
+		return sdk
+	}
+	m.pending[cp.SdkId] = append(m.pending[cp.SdkId], cp)
+	return nil
+}
+
+// takePending 取出全部等待恢复的断点
+func (m *Manager) takePending() []Checkpoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sdkIds := make([]string, 0, len(m.pending))
+	for sdkId := range m.pending {
+		sdkIds = append(sdkIds, sdkId)
+	}
+	sort.Strings(sdkIds)
+	var checkpoints []Checkpoint
+	for _, sdkId := range sdkIds {
+		checkpoints = append(checkpoints, m.pending[sdkId]...)
+	}
+	m.pending = make(map[string][]Checkpoint)
+	return checkpoints
+}
+
+// restore 以断点的状态恢复订阅，暂停的订阅不打开事件来源
+func (m *Manager) restore(cp Checkpoint, sdk *service.Fabric2Service, sink Sink) error {
+	status := StatusActive
+	if cp.Status == StatusPaused {
+		status = StatusPaused
+	}
+	sub, err := m.subscribe(cp.SdkId, sdk, cp.request(), sink, status)
+	if err != nil {
+		return err
+	}
+	slog.Info("已恢复订阅", "subscription", sub.id)
+	return nil
+}
+
+func writeCheckpoints(checkpointFile string, checkpoints []Checkpoint) error {
+	if checkpoints == nil {
+		checkpoints = []Checkpoint{}
+	}
+	data, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(checkpointFile), 0o700); err != nil {
+		return err
+	}
+	// 先写临时文件再重命名，避免写入中断留下不完整的断点文件
+	tmp := checkpointFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, checkpointFile)
+}
@@ -0,0 +1,159 @@
+package subscription
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/service"
+)
+
+// ErrNotFound 订阅不存在
+var ErrNotFound = errors.New("subscription not found")
+
+// Manager 管理进程内全部事件订阅
+type Manager struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*Subscription
+	// starting 正在打开事件来源的订阅，相同订阅的并发请求等待其结果
+	starting map[string]*startup
+	// pending 按 sdkId 等待连接配置加入连接池后恢复的断点，sink 为恢复订阅使用的投递目标
+	pending map[string][]Checkpoint
+	sink    Sink
+}
+
+// startup 订阅启动的结果，done 关闭后 sub 与 err 可读
+type startup struct {
+	done chan struct{}
+	sub  *Subscription
+	err  error
+}
+
+// DefaultManager 全局订阅管理器
+var DefaultManager = NewManager()
+
+func NewManager() *Manager {
+	return &Manager{
+		subscriptions: make(map[string]*Subscription),
+		starting:      make(map[string]*startup),
+		pending:       make(map[string][]Checkpoint),
+	}
+}
+
+// Subscribe 创建事件订阅并从 fromBlock 开始补发历史区块，相同订阅已存在时直接返回已有订阅
+func (m *Manager) Subscribe(sdkId string, sdk *service.Fabric2Service, req define.ContractEventSubscribeRequest, sink Sink) (*Subscription, error) {
+	return m.subscribe(sdkId, sdk, req, sink, StatusActive)
+}
+
+// subscribe 以 status 创建事件订阅，status 为 StatusPaused 时不打开事件来源，恢复投递时再从 fromBlock 开始
+func (m *Manager) subscribe(sdkId string, sdk *service.Fabric2Service, req define.ContractEventSubscribeRequest, sink Sink, status Status) (*Subscription, error) {
+	spec := SubscribeSpec(req)
+	if err := ValidateSpec(spec, req.FromBlock); err != nil {
+		return nil, err
+	}
+	key := Key(sdkId, spec)
+	if sub, err := m.Get(key); err == nil {
+		return sub, nil
+	}
+
+	channelId, err := sdk.ChannelID()
+	if err != nil {
+		return nil, err
+	}
+	from, _ := parseFromBlock(req.FromBlock)
+	sub := newSubscription(key, spec, channelId, from, sdk, sink)
+	sub.sdkId, sub.request, sub.status = sdkId, req, status
+	return m.add(sub)
+}
+
+// add 登记并启动订阅。持有锁时只预留订阅标识，打开事件来源（可能需要连接节点）时不持有锁，
+// 启动成功后加入订阅列表，失败时撤销预留；相同订阅已存在时返回已有订阅，正在启动时等待其结果
+func (m *Manager) add(sub *Subscription) (*Subscription, error) {
+	m.mu.Lock()
+	if existing, ok := m.subscriptions[sub.id]; ok {
+		m.mu.Unlock()
+		return existing, nil
+	}
+	if s, ok := m.starting[sub.id]; ok {
+		m.mu.Unlock()
+		<-s.done
+		return s.sub, s.err
+	}
+	s := &startup{done: make(chan struct{})}
+	m.starting[sub.id] = s
+	m.mu.Unlock()
+
+	err := sub.start()
+	m.mu.Lock()
+	delete(m.starting, sub.id)
+	if err == nil {
+		m.subscriptions[sub.id] = sub
+		s.sub = sub
+	}
+	s.err = err
+	m.mu.Unlock()
+	close(s.done)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("subscribed to events", "type", sub.spec.Type, "subscription", sub.id)
+	return sub, nil
+}
+
+// Get 按标识获取订阅
+func (m *Manager) Get(id string) (*Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sub, ok := m.subscriptions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return sub, nil
+}
+
+// List 返回按标识排序的全部订阅
+func (m *Manager) List() []*Subscription {
+	m.mu.RLock()
+	subs := make([]*Subscription, 0, len(m.subscriptions))
+	for _, sub := range m.subscriptions {
+		subs = append(subs, sub)
+	}
+	m.mu.RUnlock()
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].id < subs[j].id
+	})
+	return subs
+}
+
+// Unsubscribe 取消订阅并停止监听
+func (m *Manager) Unsubscribe(id string) error {
+	sub := m.remove(id)
+	if sub == nil {
+		return ErrNotFound
+	}
+	sub.close()
+	return nil
+}
+
+// UnsubscribeAll 取消全部订阅
+func (m *Manager) UnsubscribeAll() {
+	for _, sub := range m.List() {
+		if err := m.Unsubscribe(sub.id); err == nil {
+			slog.Info("已取消订阅", "subscription", sub.id)
+		}
+	}
+}
+
+func (m *Manager) remove(id string) *Subscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sub, ok := m.subscriptions[id]
+	if !ok {
+		return nil
+	}
+	delete(m.subscriptions, id)
+	return sub
+}
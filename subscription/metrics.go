@@ -0,0 +1,60 @@
+package subscription
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/qctc/fabric2-api-server/metrics"
+)
+
+// heightQueryTimeout 采集指标时等待查询链上高度的最长时间，超时的订阅不输出投递延迟
+const heightQueryTimeout = 5 * time.Second
+
+var (
+	subscriptionsDesc = prometheus.NewDesc("fabric2_subscriptions",
+		"Event subscriptions by status.", []string{"status"}, nil)
+	deliveredDesc = prometheus.NewDesc("fabric2_subscription_delivered_events_total",
+		"Events delivered to the sink by subscription.", []string{"subscription"}, nil)
+	failedDesc = prometheus.NewDesc("fabric2_subscription_failed_events_total",
+		"Events that failed delivery by subscription.", []string{"subscription"}, nil)
+	reconnectsDesc = prometheus.NewDesc("fabric2_subscription_reconnects_total",
+		"Event source reconnects by subscription.", []string{"subscription"}, nil)
+	lagDesc = prometheus.NewDesc("fabric2_subscription_lag_blocks",
+		"Blocks committed on the channel but not yet delivered, by subscription.", []string{"subscription"}, nil)
+)
+
+// collector 订阅数量、投递计数与投递延迟指标，每次采集时读取订阅的投递进度
+type collector struct {
+	m *Manager
+}
+
+func init() {
+	metrics.Registry.MustRegister(&collector{m: DefaultManager})
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- subscriptionsDesc
+	ch <- deliveredDesc
+	ch <- failedDesc
+	ch <- reconnectsDesc
+	ch <- lagDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	counts := map[Status]int{StatusActive: 0, StatusPaused: 0, StatusCompleted: 0, StatusStopped: 0}
+	ctx, cancel := context.WithTimeout(context.Background(), heightQueryTimeout)
+	defer cancel()
+	for _, p := range c.m.Progress(ctx) {
+		counts[p.Status]++
+		ch <- prometheus.MustNewConstMetric(deliveredDesc, prometheus.CounterValue, float64(p.Delivered), p.Id)
+		ch <- prometheus.MustNewConstMetric(failedDesc, prometheus.CounterValue, float64(p.Failed), p.Id)
+		ch <- prometheus.MustNewConstMetric(reconnectsDesc, prometheus.CounterValue, float64(p.Reconnects), p.Id)
+		if p.Lag != nil {
+			ch <- prometheus.MustNewConstMetric(lagDesc, prometheus.GaugeValue, float64(*p.Lag), p.Id)
+		}
+	}
+	for status, n := range counts {
+		ch <- prometheus.MustNewConstMetric(subscriptionsDesc, prometheus.GaugeValue, float64(n), string(status))
+	}
+}
@@ -0,0 +1,112 @@
+package subscription
+
+import (
+	"context"
+
+	"github.com/qctc/fabric2-api-server/service"
+)
+
+// Progress 订阅的投递进度，用于指标与就绪检查
+type Progress struct {
+	Id         string  `json:"subscribeId"`
+	Status     Status  `json:"status"`
+	Health     Health  `json:"health"`
+	Delivered  uint64  `json:"delivered"`
+	Failed     uint64  `json:"failed"`
+	Reconnects uint64  `json:"reconnects"`
+	Lag        *uint64 `json:"lag"`
+	LastError  string  `json:"lastError,omitempty"`
+}
+
+// Progress 返回全部订阅的投递进度；链上高度按 SDK 实例并发查询一次，
+// ctx 结束前未取得高度或尚未投递过区块的订阅 Lag 为 nil
+func (m *Manager) Progress(ctx context.Context) []Progress {
+	subs := m.List()
+	heights := blockHeights(ctx, sdksOf(subs))
+
+	progress := make([]Progress, 0, len(subs))
+	for _, sub := range subs {
+		sub.mu.RLock()
+		p := Progress{
+			Id:         sub.id,
+			Status:     sub.status,
+			Health:     sub.health,
+			Delivered:  sub.delivered,
+			Failed:     sub.failed,
+			Reconnects: sub.reconnects,
+			LastError:  sub.lastError,
+		}
+		hasDelivered, lastDelivered := sub.hasDelivered, sub.lastDelivered
+		sub.mu.RUnlock()
+		if height, ok := heights[sub.sdk]; ok && hasDelivered {
+			if lag, ok := blockLag(height, lastDelivered); ok {
+				p.Lag = &lag
+			}
+		}
+		progress = append(progress, p)
+	}
+	return progress
+}
+
+// Infos 返回全部订阅的投递状态；与 Progress 相同，链上高度按 SDK 实例并发查询一次，
+// ctx 结束前未取得高度的订阅 ChainHeight 与 Lag 为 nil
+func (m *Manager) Infos(ctx context.Context) []Info {
+	subs := m.List()
+	heights := blockHeights(ctx, sdksOf(subs))
+
+	infos := make([]Info, 0, len(subs))
+	for _, sub := range subs {
+		info := sub.info()
+		if height, ok := heights[sub.sdk]; ok {
+			info.setChainHeight(height)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// sdksOf 订阅使用的 SDK 实例
+func sdksOf(subs []*Subscription) map[*service.Fabric2Service]bool {
+	sdks := make(map[*service.Fabric2Service]bool)
+	for _, sub := range subs {
+		sdks[sub.sdk] = true
+	}
+	return sdks
+}
+
+// blockHeights 并发查询各 SDK 实例的链上高度，查询失败或 ctx 结束前未返回的实例不在结果中
+func blockHeights(ctx context.Context, sdks map[*service.Fabric2Service]bool) map[*service.Fabric2Service]uint64 {
+	type result struct {
+		sdk    *service.Fabric2Service
+		height uint64
+		err    error
+	}
+	results := make(chan result, len(sdks))
+	for sdk := range sdks {
+		go func(sdk *service.Fabric2Service) {
+			height, err := sdk.GetBlockHeight(ctx)
+			results <- result{sdk: sdk, height: height, err: err}
+		}(sdk)
+	}
+
+	heights := make(map[*service.Fabric2Service]uint64, len(sdks))
+	for range sdks {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				heights[r.sdk] = r.height
+			}
+		case <-ctx.Done():
+			return heights
+		}
+	}
+	return heights
+}
+
+// blockLag 链上高度为 height 时最后投递区块之后尚未投递的区块数
+func blockLag(height, lastDelivered uint64) (uint64, bool) {
+	if height <= lastDelivered {
+		return 0, false
+	}
+	return height - 1 - lastDelivered, true
+}
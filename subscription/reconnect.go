@@ -0,0 +1,67 @@
+package subscription
+
+import (
+	"time"
+
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+// Health 事件监听健康状态
+type Health string
+
+const (
+	HealthConnected    Health = "connected"
+	HealthReconnecting Health = "reconnecting"
+	HealthFailed       Health = "failed"
+	HealthIdle         Health = "idle"
+)
+
+const (
+	defaultReconnectInitialDelay = time.Second
+	defaultReconnectMaxDelay     = time.Minute
+)
+
+// ReconnectPolicy 事件来源断开后的重连退避策略
+type ReconnectPolicy struct {
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	// MaxAttempts 最大连续重连次数，0 表示不限
+	MaxAttempts int
+}
+
+// reconnectPolicy 读取全局配置中的重连策略，未配置的项使用默认值
+func reconnectPolicy() ReconnectPolicy {
+	policy := ReconnectPolicy{
+		InitialDelay: defaultReconnectInitialDelay,
+		MaxDelay:     defaultReconnectMaxDelay,
+	}
+	if define.GlobalConfig == nil {
+		return policy
+	}
+	cfg := define.GlobalConfig.Subscription
+	if cfg.ReconnectInitialDelay > 0 {
+		policy.InitialDelay = cfg.ReconnectInitialDelay
+	}
+	if cfg.ReconnectMaxDelay > 0 {
+		policy.MaxDelay = cfg.ReconnectMaxDelay
+	}
+	policy.MaxAttempts = cfg.ReconnectMaxAttempts
+	return policy
+}
+
+// delay 返回第 attempt 次重连前的等待时间，按指数增长且不超过 MaxDelay
+func (p ReconnectPolicy) delay(attempt int) time.Duration {
+	d := p.InitialDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// exhausted 判断是否已超过最大重连次数
+func (p ReconnectPolicy) exhausted(attempt int) bool {
+	return p.MaxAttempts > 0 && attempt > p.MaxAttempts
+}
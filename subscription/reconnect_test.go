@@ -0,0 +1,174 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReconnectPolicyDelay(t *testing.T) {
+	policy := ReconnectPolicy{InitialDelay: time.Second, MaxDelay: 10 * time.Second, MaxAttempts: 3}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, want := range expected {
+		if got := policy.delay(i + 1); got != want {
+			t.Errorf("delay(%d) = %s, want %s", i+1, got, want)
+		}
+	}
+	if policy.exhausted(3) || !policy.exhausted(4) {
+		t.Fatal("expected policy to be exhausted after 3 attempts")
+	}
+	if (ReconnectPolicy{}).exhausted(100) {
+		t.Fatal("expected unlimited attempts when MaxAttempts is 0")
+	}
+}
+
+func TestReconnectPolicyDefaults(t *testing.T) {
+	policy := reconnectPolicy()
+	if policy.InitialDelay != defaultReconnectInitialDelay || policy.MaxDelay != defaultReconnectMaxDelay {
+		t.Fatalf("unexpected default policy %+v", policy)
+	}
+}
+
+// chanSink 将投递的消息发送到通道，供投递协程与测试之间同步
+type chanSink chan []byte
+
+func (s chanSink) Send(_ context.Context, body []byte) error {
+	s <- body
+	return nil
+}
+
+func TestRunReconnectsAndResumes(t *testing.T) {
+	sink := make(chanSink, 10)
+	sub := newTestSubscription(sink)
+	sub.policy = ReconnectPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	var mu sync.Mutex
+	var froms []*uint64
+	sub.open = func(ctx context.Context, from *uint64) (<-chan item, error) {
+		mu.Lock()
+		froms = append(froms, from)
+		opened := len(froms)
+		mu.Unlock()
+		out := make(chan item)
+		go func() {
+			if opened == 1 {
+				// 第一个来源投递区块 1、2 后断开
+				defer close(out)
+				for _, n := range []uint64{1, 2} {
+					if !send(ctx, out, newEventItem(t, n, "evt")) {
+						return
+					}
+				}
+				return
+			}
+			// 重连后的来源从 from 开始投递，之后保持连接
+			if from != nil {
+				send(ctx, out, newEventItem(t, *from, "evt"))
+			}
+			<-ctx.Done()
+			close(out)
+		}()
+		return out, nil
+	}
+	if err := sub.start(); err != nil {
+		t.Fatal(err)
+	}
+	defer sub.close()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-sink:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("delivered %d events, want 3", i)
+		}
+	}
+	// 停止后投递协程已完成对区块 3 的记录
+	sub.close()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(froms) != 2 || froms[0] != nil || froms[1] == nil || *froms[1] != 3 {
+		t.Fatalf("sources opened from %v, want [nil 3]", froms)
+	}
+	sub.mu.RLock()
+	defer sub.mu.RUnlock()
+	if sub.lastDelivered != 3 || sub.reconnects != 1 || sub.lastError != errSourceClosed.Error() {
+		t.Errorf("lastDelivered=%d reconnects=%d lastError=%q", sub.lastDelivered, sub.reconnects, sub.lastError)
+	}
+}
+
+// flakySink 第 failAt 次发送失败，其余发送写入通道
+type flakySink struct {
+	out    chan []byte
+	failAt int
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *flakySink) Send(_ context.Context, body []byte) error {
+	s.mu.Lock()
+	s.calls++
+	fail := s.calls == s.failAt
+	s.mu.Unlock()
+	if fail {
+		return errors.New("mq down")
+	}
+	s.out <- body
+	return nil
+}
+
+func TestRunRetriesFailedBlock(t *testing.T) {
+	sink := &flakySink{out: make(chan []byte, 10), failAt: 2}
+	sub := newTestSubscription(sink)
+	sub.policy = ReconnectPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	var mu sync.Mutex
+	var froms []*uint64
+	sub.open = func(ctx context.Context, from *uint64) (<-chan item, error) {
+		mu.Lock()
+		froms = append(froms, from)
+		mu.Unlock()
+		start := uint64(1)
+		if from != nil {
+			start = *from
+		}
+		out := make(chan item)
+		go func() {
+			defer close(out)
+			for n := start; n <= 2; n++ {
+				if !send(ctx, out, newEventItem(t, n, "evt")) {
+					return
+				}
+			}
+			<-ctx.Done()
+		}()
+		return out, nil
+	}
+	if err := sub.start(); err != nil {
+		t.Fatal(err)
+	}
+	defer sub.close()
+
+	// 区块 2 第一次发送失败，重连后重试成功
+	for i := 0; i < 2; i++ {
+		select {
+		case <-sink.out:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("delivered %d events, want 2", i)
+		}
+	}
+	sub.close()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(froms) != 2 || froms[0] != nil || froms[1] == nil || *froms[1] != 2 {
+		t.Fatalf("sources opened from %v, want [nil 2]", froms)
+	}
+	sub.mu.RLock()
+	defer sub.mu.RUnlock()
+	if sub.lastDelivered != 2 || sub.delivered != 2 || sub.failed != 1 || sub.lastError != "deliver block 2: mq down" {
+		t.Errorf("lastDelivered=%d delivered=%d failed=%d lastError=%q", sub.lastDelivered, sub.delivered, sub.failed, sub.lastError)
+	}
+}
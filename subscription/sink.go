@@ -0,0 +1,140 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apache/rocketmq-clients/golang/v5"
+	"github.com/apache/rocketmq-clients/golang/v5/credentials"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/metrics"
+	"github.com/qctc/fabric2-api-server/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Sink 事件投递目标
+type Sink interface {
+	Send(ctx context.Context, body []byte) error
+}
+
+// errMQNotConfigured 未配置消息队列时投递失败
+var errMQNotConfigured = errors.New("message queue is not configured")
+
+// DefaultSink 订阅共用的 RocketMQ 投递目标，重新加载配置时替换生产者，已有订阅随之使用新的配置
+var DefaultSink = &RocketMQSink{}
+
+// RocketMQSink 将事件投递到 RocketMQ 指定主题
+type RocketMQSink struct {
+	mu       sync.RWMutex
+	cfg      define.MQConfig
+	producer golang.Producer
+}
+
+// Configure 按配置创建并启动生产者替换当前生产者，随后关闭原生产者；配置未变化时不做处理，host 为空时停止投递
+func (s *RocketMQSink) Configure(cfg define.MQConfig) error {
+	s.mu.RLock()
+	unchanged := s.cfg == cfg && (s.producer != nil || cfg.Host == "")
+	s.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	var producer golang.Producer
+	if cfg.Host != "" {
+		var err error
+		if producer, err = newProducer(cfg); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	old := s.producer
+	s.cfg, s.producer = cfg, producer
+	s.mu.Unlock()
+
+	if old != nil {
+		if err := old.GracefulStop(); err != nil {
+			slog.Warn("stop previous rocketmq producer failed", "error", err)
+		}
+	}
+	return nil
+}
+
+// newProducer 创建并启动生产者，userName 与 password 作为访问凭证
+func newProducer(cfg define.MQConfig) (golang.Producer, error) {
+	producer, err := golang.NewProducer(&golang.Config{
+		Endpoint: endpoint(cfg),
+		Credentials: &credentials.SessionCredentials{
+			AccessKey:    cfg.UserName,
+			AccessSecret: cfg.Password,
+		},
+	}, golang.WithTopics(cfg.Topic))
+	if err != nil {
+		return nil, fmt.Errorf("initialize rocketmq producer: %w", err)
+	}
+	if err := producer.Start(); err != nil {
+		return nil, fmt.Errorf("start rocketmq producer: %w", err)
+	}
+	return producer, nil
+}
+
+func endpoint(cfg define.MQConfig) string {
+	if cfg.Host == "" {
+		return ""
+	}
+	return net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+}
+
+// Endpoint 当前消息队列地址，未配置时为空
+func (s *RocketMQSink) Endpoint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return endpoint(s.cfg)
+}
+
+// Ready 生产者是否已启动
+func (s *RocketMQSink) Ready() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.producer != nil
+}
+
+// Close 关闭生产者
+func (s *RocketMQSink) Close() error {
+	s.mu.Lock()
+	producer := s.producer
+	s.producer = nil
+	s.mu.Unlock()
+	if producer == nil {
+		return nil
+	}
+	return producer.GracefulStop()
+}
+
+// Send 发送一条事件消息，发送过程记录为 mq.send span 与发送耗时指标
+func (s *RocketMQSink) Send(ctx context.Context, body []byte) error {
+	s.mu.RLock()
+	producer, topic := s.producer, s.cfg.Topic
+	s.mu.RUnlock()
+	if producer == nil {
+		return errMQNotConfigured
+	}
+
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "mq.send",
+		semconv.MessagingSystemKey.String("rocketmq"),
+		semconv.MessagingDestinationName(topic),
+		semconv.MessagingMessageBodySize(len(body)))
+	_, err := producer.Send(ctx, &golang.Message{
+		Topic: topic,
+		Body:  body,
+	})
+	tracing.End(span, err)
+	metrics.ObserveMQSend(topic, start, err)
+	return err
+}
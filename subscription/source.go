@@ -0,0 +1,373 @@
+package subscription
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/ratelimit"
+	"github.com/qctc/fabric2-api-server/service"
+	"github.com/qctc/fabric2-api-server/utils"
+)
+
+// 事件类型
+const (
+	TypeChaincode     = "chaincode"
+	TypeBlock         = "block"
+	TypeFilteredBlock = "filteredBlock"
+	TypeTxStatus      = "txStatus"
+)
+
+// Spec 事件过滤条件，订阅与事件流共用
+type Spec struct {
+	Type          string
+	ChaincodeName string
+	EventName     string
+	ChainName     string
+	TxId          string
+}
+
+// Message 单条待投递的事件消息，Id 为可用于断点续传的游标
+type Message struct {
+	Id    string          `json:"id"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// item 事件来源按区块顺序产生的投递单元
+type item struct {
+	number   uint64
+	messages []Message
+	// source 产生事件的节点地址，从账本补齐的区块为空
+	source string
+	// final 表示事件来源已完成，不会再产生后续消息
+	final bool
+	err   error
+}
+
+// ErrInvalidSpec 事件过滤条件或起始位置不合法
+var ErrInvalidSpec = errors.New("invalid event filter")
+
+// normalize 补全默认事件类型
+func (s Spec) normalize() Spec {
+	if s.Type == "" {
+		s.Type = TypeChaincode
+	}
+	return s
+}
+
+// ValidateSpec 校验事件过滤条件与起始区块
+func ValidateSpec(spec Spec, fromBlock string) error {
+	spec = spec.normalize()
+	switch spec.Type {
+	case TypeChaincode:
+		if spec.ChaincodeName == "" || spec.EventName == "" {
+			return fmt.Errorf("%w: chaincodeName and eventName are required for chaincode events", ErrInvalidSpec)
+		}
+	case TypeBlock, TypeFilteredBlock, TypeTxStatus:
+	default:
+		return fmt.Errorf("%w: unsupported event type %q", ErrInvalidSpec, spec.Type)
+	}
+	if _, err := parseFromBlock(fromBlock); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseFromBlock 解析起始区块，为空或 latest 时只接收新区块
+func parseFromBlock(fromBlock string) (*uint64, error) {
+	if fromBlock == "" || fromBlock == "latest" {
+		return nil, nil
+	}
+	number, err := strconv.ParseUint(fromBlock, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid fromBlock %q", ErrInvalidSpec, fromBlock)
+	}
+	return &number, nil
+}
+
+// openSource 按事件类型打开事件来源，from 不为空时先从账本补齐历史区块；ctx 结束时关闭来源
+func openSource(ctx context.Context, sdk *service.Fabric2Service, spec Spec, from *uint64) (<-chan item, error) {
+	spec = spec.normalize()
+	switch {
+	case spec.Type == TypeFilteredBlock:
+		return openFilteredBlockSource(ctx, sdk, from)
+	case spec.Type == TypeTxStatus && spec.TxId != "":
+		return openTxStatusSource(ctx, sdk, spec.TxId)
+	default:
+		return openBlockSource(ctx, sdk, spec, from)
+	}
+}
+
+// getBlock 读取历史区块，与接口共用连接配置的限流配额，避免补齐大量区块时压垮节点
+func getBlock(ctx context.Context, sdk *service.Fabric2Service, number uint64) (*common.Block, error) {
+	release, err := ratelimit.Default.Wait(ctx, ratelimit.ProfileKey(sdk.Id()))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return sdk.GetBlockInfo(ctx, strconv.FormatUint(number, 10))
+}
+
+func send(ctx context.Context, out chan<- item, it item) bool {
+	select {
+	case out <- it:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// openBlockSource 基于完整区块事件的来源，补齐历史区块并填补实时事件中缺失的区块
+func openBlockSource(ctx context.Context, sdk *service.Fabric2Service, spec Spec, from *uint64) (<-chan item, error) {
+	listener, err := sdk.SubscribeEvent()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan item)
+	go func() {
+		defer close(out)
+		defer listener.Close()
+
+		var next uint64
+		known := from != nil
+		if known {
+			next = *from
+		}
+		emit := func(block *common.Block, source string) bool {
+			messages, err := buildMessages(spec, listener.ChannelID, block)
+			if err != nil {
+				slog.Error("build messages from block failed", "block", block.GetHeader().GetNumber(), "error", err)
+			}
+			return send(ctx, out, item{number: block.GetHeader().GetNumber(), messages: messages, source: source})
+		}
+		// fetchUpTo 从账本读取 [next, end] 区间的区块
+		fetchUpTo := func(end uint64) bool {
+			for ; next <= end; next++ {
+				block, err := getBlock(ctx, sdk, next)
+				if err != nil {
+					send(ctx, out, item{err: err})
+					return false
+				}
+				if !emit(block, "") {
+					return false
+				}
+			}
+			return true
+		}
+
+		if known {
+			height, err := sdk.GetBlockHeight(ctx)
+			if err != nil {
+				send(ctx, out, item{err: err})
+				return
+			}
+			if height > 0 && !fetchUpTo(height-1) {
+				return
+			}
+		}
+		for {
+			select {
+			case event, ok := <-listener.Events:
+				if !ok {
+					return
+				}
+				if event == nil {
+					continue
+				}
+				number := event.Block.GetHeader().GetNumber()
+				if known && number < next {
+					continue
+				}
+				if known && number > next && !fetchUpTo(number-1) {
+					return
+				}
+				if !emit(event.Block, event.SourceURL) {
+					return
+				}
+				next, known = number+1, true
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// openFilteredBlockSource 基于过滤区块事件的来源，from 不为空时由事件节点从该区块开始发送历史区块
+func openFilteredBlockSource(ctx context.Context, sdk *service.Fabric2Service, from *uint64) (<-chan item, error) {
+	listener, err := sdk.SubscribeFilteredBlockEvent(from)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan item)
+	go func() {
+		defer close(out)
+		defer listener.Close()
+		for {
+			select {
+			case event, ok := <-listener.Events:
+				if !ok {
+					return
+				}
+				if event == nil || event.FilteredBlock == nil {
+					continue
+				}
+				number := event.FilteredBlock.GetNumber()
+				if from != nil && number < *from {
+					continue
+				}
+				message := buildFilteredBlockMessage(listener.ChannelID, event.FilteredBlock)
+				if !send(ctx, out, item{number: number, messages: []Message{message}, source: event.SourceURL}) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// openTxStatusSource 监听单笔交易的提交状态，交易已上链时直接从账本返回结果
+func openTxStatusSource(ctx context.Context, sdk *service.Fabric2Service, txId string) (<-chan item, error) {
+	// 先注册再查询账本，避免两者之间提交的交易被遗漏
+	listener, err := sdk.SubscribeTxStatusEvent(txId)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan item)
+	go func() {
+		defer close(out)
+		defer listener.Close()
+
+		if tx, err := sdk.GetTransactionInfo(ctx, txId); err == nil {
+			number, err := sdk.GetBlockByTxID(ctx, txId)
+			if err != nil {
+				send(ctx, out, item{err: err})
+				return
+			}
+			code := pb.TxValidationCode(tx.GetValidationCode())
+			send(ctx, out, item{number: number, messages: []Message{buildTxStatusMessage(listener.ChannelID, number, txId, code)}, final: true})
+			return
+		}
+
+		select {
+		case event, ok := <-listener.Events:
+			if !ok || event == nil {
+				return
+			}
+			message := buildTxStatusMessage(listener.ChannelID, event.BlockNumber, event.TxID, event.TxValidationCode)
+			send(ctx, out, item{number: event.BlockNumber, messages: []Message{message}, source: event.SourceURL, final: true})
+		case <-ctx.Done():
+		}
+	}()
+	return out, nil
+}
+
+// buildMessages 按事件类型将完整区块转换为事件消息
+func buildMessages(spec Spec, channelId string, block *common.Block) ([]Message, error) {
+	spec = spec.normalize()
+	number := block.GetHeader().GetNumber()
+	txs, err := utils.ParseBlockTransactions(block)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []Message
+	switch spec.Type {
+	case TypeBlock:
+		res := define.BlockEventRes{
+			BlockHeight:  number,
+			ChainId:      channelId,
+			DataHash:     hex.EncodeToString(block.GetHeader().GetDataHash()),
+			PreviousHash: hex.EncodeToString(block.GetHeader().GetPreviousHash()),
+			TxCount:      len(txs),
+			TxIds:        make([]string, 0, len(txs)),
+		}
+		for _, tx := range txs {
+			res.TxIds = append(res.TxIds, tx.TxId)
+		}
+		data, _ := json.Marshal(res)
+		messages = append(messages, Message{Id: Cursor{Block: number, TxIndex: math.MaxInt}.String(), Event: TypeBlock, Data: data})
+	case TypeTxStatus:
+		for _, tx := range txs {
+			if spec.TxId != "" && tx.TxId != spec.TxId {
+				continue
+			}
+			message := buildTxStatusMessage(channelId, number, tx.TxId, pb.TxValidationCode(tx.ValidationCode))
+			message.Id = Cursor{Block: number, TxIndex: tx.Index}.String()
+			messages = append(messages, message)
+		}
+	case TypeChaincode:
+		for _, tx := range txs {
+			for _, v := range tx.Events {
+				if v.ChaincodeId != spec.ChaincodeName || v.EventName != spec.EventName {
+					continue
+				}
+				data, _ := json.Marshal(define.EventRes{
+					BlockHeight:   number,
+					ChainId:       channelId,
+					TxId:          v.TxId,
+					Path:          "cross." + spec.ChainName + "." + spec.ChaincodeName,
+					EventData:     v.Payload,
+					ChaincodeName: v.ChaincodeId,
+					Topic:         v.EventName,
+				})
+				messages = append(messages, Message{Id: Cursor{Block: number, TxIndex: tx.Index}.String(), Event: TypeChaincode, Data: data})
+			}
+		}
+	}
+	return messages, nil
+}
+
+// buildFilteredBlockMessage 将过滤区块转换为事件消息
+func buildFilteredBlockMessage(channelId string, block *pb.FilteredBlock) Message {
+	res := define.FilteredBlockEventRes{
+		BlockHeight: block.GetNumber(),
+		ChainId:     channelId,
+		Txs:         make([]define.FilteredTxRes, 0, len(block.GetFilteredTransactions())),
+	}
+	for _, tx := range block.GetFilteredTransactions() {
+		txRes := define.FilteredTxRes{
+			TxId:           tx.GetTxid(),
+			Type:           tx.GetType().String(),
+			ValidationCode: tx.GetTxValidationCode().String(),
+			Valid:          tx.GetTxValidationCode() == pb.TxValidationCode_VALID,
+		}
+		for _, action := range tx.GetTransactionActions().GetChaincodeActions() {
+			if event := action.GetChaincodeEvent(); event != nil {
+				txRes.ChaincodeEvents = append(txRes.ChaincodeEvents, define.FilteredChaincodeEventRes{
+					ChaincodeName: event.GetChaincodeId(),
+					EventName:     event.GetEventName(),
+				})
+			}
+		}
+		res.Txs = append(res.Txs, txRes)
+	}
+	data, _ := json.Marshal(res)
+	return Message{Id: Cursor{Block: block.GetNumber(), TxIndex: math.MaxInt}.String(), Event: TypeFilteredBlock, Data: data}
+}
+
+// buildTxStatusMessage 构造交易状态事件消息
+func buildTxStatusMessage(channelId string, number uint64, txId string, code pb.TxValidationCode) Message {
+	data, _ := json.Marshal(define.TxStatusEventRes{
+		BlockHeight:    number,
+		ChainId:        channelId,
+		TxId:           txId,
+		ValidationCode: code.String(),
+		Valid:          code == pb.TxValidationCode_VALID,
+	})
+	return Message{Id: Cursor{Block: number, TxIndex: math.MaxInt}.String(), Event: TypeTxStatus, Data: data}
+}
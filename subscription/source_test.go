@@ -0,0 +1,84 @@
+package subscription
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/qctc/fabric2-api-server/define"
+)
+
+func TestValidateSpec(t *testing.T) {
+	cases := []struct {
+		spec      Spec
+		fromBlock string
+		valid     bool
+	}{
+		{Spec{ChaincodeName: "cc", EventName: "evt"}, "0", true},
+		{Spec{Type: TypeChaincode, ChaincodeName: "cc"}, "", false},
+		{Spec{Type: "unknown"}, "", false},
+		{Spec{Type: TypeBlock}, "x", false},
+		{Spec{Type: TypeTxStatus}, "latest", true},
+		{Spec{Type: TypeFilteredBlock}, "latest", true},
+		{Spec{Type: TypeFilteredBlock}, "10", true},
+		{Spec{Type: TypeFilteredBlock}, "x", false},
+	}
+	for _, c := range cases {
+		err := ValidateSpec(c.spec, c.fromBlock)
+		if (err == nil) != c.valid {
+			t.Errorf("ValidateSpec(%+v, %q) = %v, want valid=%v", c.spec, c.fromBlock, err, c.valid)
+		}
+	}
+}
+
+func TestBuildMessages(t *testing.T) {
+	block := newEventBlock(t, 7, "cc", "evt")
+	block.Metadata = &common.BlockMetadata{Metadata: make([][]byte, common.BlockMetadataIndex_TRANSACTIONS_FILTER+1)}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = []byte{byte(pb.TxValidationCode_MVCC_READ_CONFLICT)}
+
+	messages, err := buildMessages(Spec{Type: TypeChaincode, ChaincodeName: "cc", EventName: "evt"}, "mychannel", block)
+	if err != nil || len(messages) != 1 || messages[0].Id != "7:0" {
+		t.Fatalf("unexpected chaincode messages %+v, err %v", messages, err)
+	}
+
+	messages, err = buildMessages(Spec{Type: TypeTxStatus}, "mychannel", block)
+	if err != nil || len(messages) != 1 || messages[0].Id != "7:0" {
+		t.Fatalf("unexpected tx status messages %+v, err %v", messages, err)
+	}
+	var status define.TxStatusEventRes
+	if err := json.Unmarshal(messages[0].Data, &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Valid || status.ValidationCode != "MVCC_READ_CONFLICT" || status.TxId != "tx1" {
+		t.Fatalf("unexpected tx status %+v", status)
+	}
+
+	messages, err = buildMessages(Spec{Type: TypeBlock}, "mychannel", block)
+	if err != nil || len(messages) != 1 || messages[0].Id != "7" {
+		t.Fatalf("unexpected block messages %+v, err %v", messages, err)
+	}
+}
+
+func TestBuildFilteredBlockMessage(t *testing.T) {
+	block := &pb.FilteredBlock{
+		Number: 3,
+		FilteredTransactions: []*pb.FilteredTransaction{{
+			Txid:             "tx1",
+			Type:             common.HeaderType_ENDORSER_TRANSACTION,
+			TxValidationCode: pb.TxValidationCode_VALID,
+			Data: &pb.FilteredTransaction_TransactionActions{TransactionActions: &pb.FilteredTransactionActions{
+				ChaincodeActions: []*pb.FilteredChaincodeAction{{ChaincodeEvent: &pb.ChaincodeEvent{ChaincodeId: "cc", EventName: "evt"}}},
+			}},
+		}},
+	}
+
+	message := buildFilteredBlockMessage("mychannel", block)
+	var res define.FilteredBlockEventRes
+	if err := json.Unmarshal(message.Data, &res); err != nil {
+		t.Fatal(err)
+	}
+	if message.Id != "3" || len(res.Txs) != 1 || !res.Txs[0].Valid || len(res.Txs[0].ChaincodeEvents) != 1 {
+		t.Fatalf("unexpected filtered block message %+v", res)
+	}
+}
@@ -0,0 +1,115 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/service"
+)
+
+// Cursor 事件流游标，格式为 "区块号" 或 "区块号:交易序号"
+type Cursor struct {
+	Block   uint64
+	TxIndex int
+}
+
+// ParseCursor 解析 Last-Event-ID，仅有区块号时表示该区块已全部接收
+func ParseCursor(value string) (Cursor, error) {
+	blockPart, txPart, hasTx := strings.Cut(value, ":")
+	block, err := strconv.ParseUint(blockPart, 10, 64)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: invalid event cursor %q", ErrInvalidSpec, value)
+	}
+	if !hasTx {
+		return Cursor{Block: block, TxIndex: math.MaxInt}, nil
+	}
+	txIndex, err := strconv.Atoi(txPart)
+	if err != nil || txIndex < 0 {
+		return Cursor{}, fmt.Errorf("%w: invalid event cursor %q", ErrInvalidSpec, value)
+	}
+	return Cursor{Block: block, TxIndex: txIndex}, nil
+}
+
+// String 返回游标的文本形式
+func (c Cursor) String() string {
+	if c.TxIndex == math.MaxInt {
+		return strconv.FormatUint(c.Block, 10)
+	}
+	return fmt.Sprintf("%d:%d", c.Block, c.TxIndex)
+}
+
+// covers 判断指定位置是否已在游标之前被接收
+func (c Cursor) covers(block uint64, txIndex int) bool {
+	return block < c.Block || (block == c.Block && txIndex <= c.TxIndex)
+}
+
+// coversId 判断消息标识对应的位置是否已被接收
+func (c Cursor) coversId(id string) bool {
+	position, err := ParseCursor(id)
+	if err != nil {
+		return false
+	}
+	return c.covers(position.Block, position.TxIndex)
+}
+
+// StreamSpec 从事件流请求中提取过滤条件
+func StreamSpec(req define.EventStreamRequest) Spec {
+	return Spec{
+		Type:          req.Type,
+		ChaincodeName: req.ChaincodeName,
+		EventName:     req.EventName,
+		ChainName:     req.ChainName,
+		TxId:          req.TxId,
+	}
+}
+
+// OpenStream 打开事件流，lastEventId 不为空时从该游标之后继续推送；ctx 结束时关闭事件流
+func OpenStream(ctx context.Context, sdk *service.Fabric2Service, req define.EventStreamRequest, lastEventId string) (<-chan Message, error) {
+	spec := StreamSpec(req)
+	if err := ValidateSpec(spec, req.FromBlock); err != nil {
+		return nil, err
+	}
+
+	var cursor *Cursor
+	from, _ := parseFromBlock(req.FromBlock)
+	if lastEventId != "" {
+		c, err := ParseCursor(lastEventId)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &c
+		from = &c.Block
+	}
+
+	items, err := openSource(ctx, sdk, spec, from)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Message)
+	go func() {
+		defer close(out)
+		for it := range items {
+			if it.err != nil {
+				slog.Warn("event stream source failed", "error", it.err)
+				return
+			}
+			for _, m := range it.messages {
+				if cursor != nil && cursor.coversId(m.Id) {
+					continue
+				}
+				select {
+				case out <- m:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,30 @@
+package subscription
+
+import (
+	"testing"
+)
+
+func TestParseCursor(t *testing.T) {
+	c, err := ParseCursor("12:3")
+	if err != nil || c.Block != 12 || c.TxIndex != 3 {
+		t.Fatalf("unexpected cursor %+v, err %v", c, err)
+	}
+	if c.String() != "12:3" {
+		t.Fatalf("unexpected cursor string %s", c.String())
+	}
+
+	whole, err := ParseCursor("12")
+	if err != nil || whole.String() != "12" {
+		t.Fatalf("unexpected cursor %+v, err %v", whole, err)
+	}
+	if !whole.coversId("12:7") || whole.coversId("13:0") {
+		t.Fatal("block cursor should cover the whole block only")
+	}
+	if !c.coversId("12:3") || c.coversId("12:4") {
+		t.Fatal("tx cursor should cover positions up to its tx index")
+	}
+
+	if _, err := ParseCursor("abc"); err == nil {
+		t.Fatal("expected error for invalid cursor")
+	}
+}
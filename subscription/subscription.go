@@ -0,0 +1,440 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/qctc/fabric2-api-server/define"
+	"github.com/qctc/fabric2-api-server/service"
+)
+
+// Status 订阅状态
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusPaused    Status = "paused"
+	StatusCompleted Status = "completed"
+	StatusStopped   Status = "stopped"
+)
+
+// sendTimeout 单个区块内事件投递的超时时间
+const sendTimeout = 30 * time.Second
+
+// errSourceClosed 事件来源意外关闭，通常是事件节点断开连接
+var errSourceClosed = errors.New("event source closed")
+
+// SubscribeSpec 从订阅请求中提取过滤条件
+func SubscribeSpec(req define.ContractEventSubscribeRequest) Spec {
+	return Spec{
+		Type:          req.Type,
+		ChaincodeName: req.ChaincodeName,
+		EventName:     req.EventName,
+		ChainName:     req.ChainName,
+		TxId:          req.TxId,
+	}.normalize()
+}
+
+// Key 生成订阅标识，chainName 参与计算以区分不同业务链的同名事件订阅
+func Key(sdkId string, spec Spec) string {
+	spec = spec.normalize()
+	switch spec.Type {
+	case TypeChaincode:
+		return fmt.Sprintf("%s:%s:%s:%s", sdkId, spec.ChainName, spec.ChaincodeName, spec.EventName)
+	case TypeTxStatus:
+		return fmt.Sprintf("%s:%s:%s:%s", sdkId, spec.ChainName, spec.Type, spec.TxId)
+	default:
+		return fmt.Sprintf("%s:%s:%s", sdkId, spec.ChainName, spec.Type)
+	}
+}
+
+// Info 订阅对外展示的状态信息
+type Info struct {
+	SubscribeId        string    `json:"subscribeId"`
+	Type               string    `json:"type"`
+	ChaincodeName      string    `json:"chaincodeName,omitempty"`
+	EventName          string    `json:"eventName,omitempty"`
+	TxId               string    `json:"txId,omitempty"`
+	ChainName          string    `json:"chainName"`
+	ChannelId          string    `json:"channelId"`
+	Status             Status    `json:"status"`
+	Health             Health    `json:"health"`
+	EventSource        string    `json:"eventSource,omitempty"`
+	Reconnects         uint64    `json:"reconnects"`
+	CreatedAt          time.Time `json:"createdAt"`
+	LastDeliveredBlock *uint64   `json:"lastDeliveredBlock"`
+	Delivered          uint64    `json:"delivered"`
+	Failed             uint64    `json:"failed"`
+	ChainHeight        *uint64   `json:"chainHeight,omitempty"`
+	Lag                *uint64   `json:"lag"`
+	LastError          string    `json:"lastError,omitempty"`
+}
+
+// Subscription 单个事件订阅，负责从事件来源读取消息并投递到 Sink
+type Subscription struct {
+	id        string
+	spec      Spec
+	channelId string
+	createdAt time.Time
+	from      *uint64
+
+	// sdkId 与 request 用于写入断点后恢复订阅
+	sdkId   string
+	request define.ContractEventSubscribeRequest
+
+	sdk  *service.Fabric2Service
+	sink Sink
+	// open 打开从 from 开始的事件来源，policy 为来源断开后的重连策略
+	open   func(ctx context.Context, from *uint64) (<-chan item, error)
+	policy ReconnectPolicy
+
+	mu            sync.RWMutex
+	status        Status
+	health        Health
+	eventSource   string
+	reconnects    uint64
+	lastDelivered uint64
+	hasDelivered  bool
+	delivered     uint64
+	failed        uint64
+	lastError     string
+
+	ctrl   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newSubscription(id string, spec Spec, channelId string, from *uint64, sdk *service.Fabric2Service, sink Sink) *Subscription {
+	return &Subscription{
+		id:        id,
+		spec:      spec,
+		channelId: channelId,
+		createdAt: time.Now(),
+		from:      from,
+		sdk:       sdk,
+		sink:      sink,
+		open: func(ctx context.Context, from *uint64) (<-chan item, error) {
+			return openSource(ctx, sdk, spec, from)
+		},
+		policy: reconnectPolicy(),
+		status: StatusActive,
+		health: HealthConnected,
+		ctrl:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// Id 返回订阅标识
+func (s *Subscription) Id() string {
+	return s.id
+}
+
+// Spec 返回订阅的事件过滤条件
+func (s *Subscription) Spec() Spec {
+	return s.spec
+}
+
+// ChannelId 返回订阅所在的通道
+func (s *Subscription) ChannelId() string {
+	return s.channelId
+}
+
+// Info 返回订阅当前状态，并在 ctx 内查询链上高度计算投递延迟
+func (s *Subscription) Info(ctx context.Context) Info {
+	info := s.info()
+	height, err := s.sdk.GetBlockHeight(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "query block height failed", "subscription", s.id, "error", err)
+		return info
+	}
+	info.setChainHeight(height)
+	return info
+}
+
+// info 订阅的投递状态，不含链上高度与落后区块数
+func (s *Subscription) info() Info {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	info := Info{
+		SubscribeId:   s.id,
+		Type:          s.spec.Type,
+		ChaincodeName: s.spec.ChaincodeName,
+		EventName:     s.spec.EventName,
+		TxId:          s.spec.TxId,
+		ChainName:     s.spec.ChainName,
+		ChannelId:     s.channelId,
+		Status:        s.status,
+		Health:        s.health,
+		EventSource:   s.eventSource,
+		Reconnects:    s.reconnects,
+		CreatedAt:     s.createdAt,
+		Delivered:     s.delivered,
+		Failed:        s.failed,
+		LastError:     s.lastError,
+	}
+	if s.hasDelivered {
+		lastDelivered := s.lastDelivered
+		info.LastDeliveredBlock = &lastDelivered
+	}
+	return info
+}
+
+// setChainHeight 设置链上高度，已投递过区块时同时计算落后区块数
+func (info *Info) setChainHeight(height uint64) {
+	info.ChainHeight = &height
+	if info.LastDeliveredBlock == nil {
+		return
+	}
+	if lag, ok := blockLag(height, *info.LastDeliveredBlock); ok {
+		info.Lag = &lag
+	}
+}
+
+// Pause 暂停投递并关闭事件来源
+func (s *Subscription) Pause() {
+	s.mu.Lock()
+	if s.status == StatusActive {
+		s.status = StatusPaused
+	}
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Resume 恢复投递，从最后投递的区块之后重新打开事件来源
+func (s *Subscription) Resume() {
+	s.mu.Lock()
+	if s.status == StatusPaused {
+		s.status = StatusActive
+	}
+	s.mu.Unlock()
+	s.notify()
+}
+
+func (s *Subscription) notify() {
+	select {
+	case s.ctrl <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Subscription) currentStatus() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+func (s *Subscription) setStatus(status Status) {
+	s.mu.Lock()
+	s.status = status
+	s.mu.Unlock()
+}
+
+func (s *Subscription) setHealth(health Health) {
+	s.mu.Lock()
+	s.health = health
+	s.mu.Unlock()
+}
+
+func (s *Subscription) recordError(err error) {
+	slog.Error("subscription error", "subscription", s.id, "error", err)
+	s.mu.Lock()
+	s.lastError = err.Error()
+	s.mu.Unlock()
+}
+
+// resumeFrom 重新打开事件来源时的起始区块
+func (s *Subscription) resumeFrom() *uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.hasDelivered {
+		next := s.lastDelivered + 1
+		return &next
+	}
+	return s.from
+}
+
+// start 打开事件来源并启动投递协程，已暂停的订阅只启动投递协程，恢复投递时再打开事件来源
+func (s *Subscription) start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	if s.currentStatus() == StatusPaused {
+		s.cancel = cancel
+		go s.run(ctx, nil, func() {})
+		return nil
+	}
+	sourceCtx, cancelSource := context.WithCancel(ctx)
+	items, err := s.open(sourceCtx, s.from)
+	if err != nil {
+		cancelSource()
+		cancel()
+		return err
+	}
+	s.cancel = cancel
+	go s.run(ctx, items, cancelSource)
+	return nil
+}
+
+// run 读取事件来源并投递；来源关闭或出错时按退避策略重连，并从最后投递的区块之后继续
+func (s *Subscription) run(ctx context.Context, items <-chan item, cancelSource context.CancelFunc) {
+	defer close(s.done)
+	defer slog.Info("stopped listener", "subscription", s.id)
+	attempt := 0
+	for {
+		if items == nil {
+			if s.currentStatus() == StatusPaused {
+				s.setHealth(HealthIdle)
+				select {
+				case <-s.ctrl:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+			sourceCtx, cancel := context.WithCancel(ctx)
+			var err error
+			items, err = s.open(sourceCtx, s.resumeFrom())
+			if err != nil {
+				cancel()
+				items = nil
+				s.recordError(err)
+				attempt++
+				if !s.backoff(ctx, s.policy, attempt) {
+					return
+				}
+				continue
+			}
+			cancelSource = cancel
+			s.setHealth(HealthConnected)
+		}
+
+		select {
+		case it, ok := <-items:
+			if !ok || it.err != nil {
+				cancelSource()
+				items = nil
+				if ok {
+					s.recordError(it.err)
+				} else {
+					s.recordError(errSourceClosed)
+				}
+				attempt++
+				if !s.backoff(ctx, s.policy, attempt) {
+					return
+				}
+				continue
+			}
+			if err := s.deliver(ctx, it); err != nil {
+				// 投递失败的区块没有记为已投递，重连后从该区块重试
+				cancelSource()
+				items = nil
+				s.recordError(err)
+				attempt++
+				if !s.backoff(ctx, s.policy, attempt) {
+					return
+				}
+				continue
+			}
+			attempt = 0
+			if it.final {
+				cancelSource()
+				s.mu.Lock()
+				s.status = StatusCompleted
+				s.health = HealthIdle
+				s.mu.Unlock()
+				return
+			}
+		case <-s.ctrl:
+			// 暂停或恢复时关闭当前来源，之后从最后投递的区块重新打开
+			cancelSource()
+			items = nil
+		case <-ctx.Done():
+			cancelSource()
+			return
+		}
+	}
+}
+
+// backoff 等待下一次重连，超过最大重连次数时将订阅标记为失败并返回 false
+func (s *Subscription) backoff(ctx context.Context, policy ReconnectPolicy, attempt int) bool {
+	if policy.exhausted(attempt) {
+		slog.Error("subscription failed after reconnect attempts", "subscription", s.id, "attempts", attempt-1)
+		s.mu.Lock()
+		s.status = StatusStopped
+		s.health = HealthFailed
+		s.mu.Unlock()
+		return false
+	}
+
+	s.mu.Lock()
+	s.health = HealthReconnecting
+	s.reconnects++
+	s.mu.Unlock()
+	delay := policy.delay(attempt)
+	slog.Warn("subscription reconnecting", "subscription", s.id, "delay", delay.String(), "attempt", attempt)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// close 停止投递协程并关闭事件来源
+func (s *Subscription) close() {
+	_ = s.stop(context.Background())
+}
+
+// stop 停止投递协程，等待正在进行的投递完成，ctx 结束时不再等待
+func (s *Subscription) stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deliver 投递一个区块内的事件消息，暂停或已投递过的区块直接跳过。区块内的消息全部发送成功后才记为已投递，
+// 发送失败时停止投递并返回错误，由 run 重新打开事件来源后从该区块重试，已发送的消息会再次发送
+func (s *Subscription) deliver(ctx context.Context, it item) error {
+	s.mu.RLock()
+	skip := s.status == StatusPaused || (s.hasDelivered && it.number <= s.lastDelivered)
+	s.mu.RUnlock()
+	if skip {
+		return nil
+	}
+
+	// 投递不随订阅停止而中断，保证关闭时已取出的消息完成发送
+	sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sendTimeout)
+	defer cancel()
+	for _, m := range it.messages {
+		err := s.sink.Send(sendCtx, m.Data)
+		s.mu.Lock()
+		if err != nil {
+			s.failed++
+			s.mu.Unlock()
+			return fmt.Errorf("deliver block %d: %w", it.number, err)
+		}
+		s.delivered++
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	s.lastDelivered = it.number
+	s.hasDelivered = true
+	if it.source != "" {
+		s.eventSource = it.source
+	}
+	s.mu.Unlock()
+	return nil
+}
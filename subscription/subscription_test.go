@@ -0,0 +1,248 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+type recordSink struct {
+	bodies [][]byte
+	err    error
+}
+
+func (s *recordSink) Send(_ context.Context, body []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.bodies = append(s.bodies, body)
+	return nil
+}
+
+func mustMarshal(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// newEventBlock 构造包含一个合约事件的区块
+func newEventBlock(t *testing.T, number uint64, chaincodeName, eventName string) *common.Block {
+	ccEvent := mustMarshal(t, &pb.ChaincodeEvent{ChaincodeId: chaincodeName, EventName: eventName, TxId: "tx1", Payload: []byte(`["a"]`)})
+	action := mustMarshal(t, &pb.ChaincodeAction{Events: ccEvent})
+	prp := mustMarshal(t, &pb.ProposalResponsePayload{Extension: action})
+	actionPayload := mustMarshal(t, &pb.ChaincodeActionPayload{Action: &pb.ChaincodeEndorsedAction{ProposalResponsePayload: prp}})
+	tx := mustMarshal(t, &pb.Transaction{Actions: []*pb.TransactionAction{{Payload: actionPayload}}})
+	chHeader := mustMarshal(t, &common.ChannelHeader{Type: int32(common.HeaderType_ENDORSER_TRANSACTION), TxId: "tx1"})
+	payload := mustMarshal(t, &common.Payload{Header: &common.Header{ChannelHeader: chHeader}, Data: tx})
+	env := mustMarshal(t, &common.Envelope{Payload: payload})
+	return &common.Block{
+		Header: &common.BlockHeader{Number: number},
+		Data:   &common.BlockData{Data: [][]byte{env}},
+	}
+}
+
+func newTestSubscription(sink Sink) *Subscription {
+	spec := Spec{Type: TypeChaincode, ChaincodeName: "cc", EventName: "evt", ChainName: "chain"}
+	return newSubscription(Key("sdk", spec), spec, "mychannel", nil, nil, sink)
+}
+
+// newEventItem 构造包含一个合约事件的投递单元
+func newEventItem(t *testing.T, number uint64, eventName string) item {
+	messages, err := buildMessages(Spec{Type: TypeChaincode, ChaincodeName: "cc", EventName: "evt"}, "mychannel", newEventBlock(t, number, "cc", eventName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return item{number: number, messages: messages}
+}
+
+func TestKeyDistinguishesChainNameAndType(t *testing.T) {
+	spec := Spec{ChaincodeName: "cc", EventName: "evt", ChainName: "a"}
+	other := spec
+	other.ChainName = "b"
+	if Key("sdk", spec) == Key("sdk", other) {
+		t.Fatal("expected different keys for different chain names")
+	}
+	if Key("sdk", Spec{Type: TypeTxStatus, TxId: "t1"}) == Key("sdk", Spec{Type: TypeTxStatus, TxId: "t2"}) {
+		t.Fatal("expected different keys for different transactions")
+	}
+	if Key("sdk", spec) != "sdk:a:cc:evt" {
+		t.Fatalf("unexpected chaincode key %s", Key("sdk", spec))
+	}
+}
+
+func TestDeliverFiltersAndSkipsDuplicates(t *testing.T) {
+	sink := &recordSink{}
+	sub := newTestSubscription(sink)
+
+	sub.deliver(context.Background(), newEventItem(t, 5, "evt"))
+	sub.deliver(context.Background(), newEventItem(t, 5, "evt"))
+	sub.deliver(context.Background(), newEventItem(t, 6, "other"))
+
+	if len(sink.bodies) != 1 {
+		t.Fatalf("expected 1 delivered event, got %d", len(sink.bodies))
+	}
+	if sub.delivered != 1 || sub.lastDelivered != 6 {
+		t.Fatalf("unexpected state delivered=%d lastDelivered=%d", sub.delivered, sub.lastDelivered)
+	}
+	if next := sub.resumeFrom(); next == nil || *next != 7 {
+		t.Fatalf("expected resume from block 7, got %v", next)
+	}
+}
+
+func TestDeliverSkipsWhilePaused(t *testing.T) {
+	sink := &recordSink{}
+	sub := newTestSubscription(sink)
+
+	sub.Pause()
+	sub.deliver(context.Background(), newEventItem(t, 1, "evt"))
+	if len(sink.bodies) != 0 || sub.hasDelivered {
+		t.Fatal("expected no delivery while paused")
+	}
+
+	sub.Resume()
+	sub.deliver(context.Background(), newEventItem(t, 1, "evt"))
+	if len(sink.bodies) != 1 {
+		t.Fatalf("expected delivery after resume, got %d", len(sink.bodies))
+	}
+}
+
+func TestDeliverRecordsSinkFailure(t *testing.T) {
+	sub := newTestSubscription(&recordSink{err: errors.New("mq down")})
+
+	err := sub.deliver(context.Background(), newEventItem(t, 1, "evt"))
+	if err == nil || sub.failed != 1 {
+		t.Fatalf("unexpected state err=%v failed=%d", err, sub.failed)
+	}
+	// 发送失败的区块不记为已投递，重连后从该区块重试
+	if sub.hasDelivered {
+		t.Fatalf("failed block marked as delivered, next = %v", sub.resumeFrom())
+	}
+}
+
+func TestManagerUnsubscribeUnknown(t *testing.T) {
+	if err := NewManager().Unsubscribe("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestManagerAddStartsWithoutLock(t *testing.T) {
+	m := NewManager()
+	opening, release := make(chan struct{}), make(chan struct{})
+	sub := newTestSubscription(&recordSink{})
+	sub.open = func(ctx context.Context, from *uint64) (<-chan item, error) {
+		close(opening)
+		<-release
+		return make(chan item), nil
+	}
+	type result struct {
+		sub *Subscription
+		err error
+	}
+	results := make(chan result, 2)
+	go func() {
+		added, err := m.add(sub)
+		results <- result{added, err}
+	}()
+	<-opening
+
+	// 打开事件来源期间可以查询订阅，相同订阅的请求等待启动结果
+	if len(m.List()) != 0 {
+		t.Fatal("starting subscription should not be listed")
+	}
+	duplicate := newTestSubscription(&recordSink{})
+	duplicate.open = func(ctx context.Context, from *uint64) (<-chan item, error) {
+		t.Error("duplicate subscription opened its own source")
+		return nil, errors.New("unexpected")
+	}
+	go func() {
+		added, err := m.add(duplicate)
+		results <- result{added, err}
+	}()
+	close(release)
+	for i := 0; i < 2; i++ {
+		if r := <-results; r.err != nil || r.sub != sub {
+			t.Fatalf("add() = %p, %v, want %p", r.sub, r.err, sub)
+		}
+	}
+	defer sub.close()
+
+	// 启动失败时撤销预留，之后可以重新订阅
+	failing := newTestSubscription(&recordSink{})
+	failing.id = "failing"
+	failing.open = func(ctx context.Context, from *uint64) (<-chan item, error) {
+		return nil, errors.New("peer unreachable")
+	}
+	if _, err := m.add(failing); err == nil {
+		t.Fatal("expected start error")
+	}
+	if _, err := m.Get("failing"); !errors.Is(err, ErrNotFound) || len(m.starting) != 0 {
+		t.Fatalf("failed subscription left registered: %v, starting = %d", err, len(m.starting))
+	}
+}
+
+func TestBlockLag(t *testing.T) {
+	// 高度 10 表示最新区块为 9
+	if lag, ok := blockLag(10, 6); !ok || lag != 3 {
+		t.Errorf("blockLag(10, 6) = %d, %v", lag, ok)
+	}
+	if lag, ok := blockLag(10, 9); !ok || lag != 0 {
+		t.Errorf("blockLag(10, 9) = %d, %v", lag, ok)
+	}
+	if _, ok := blockLag(5, 9); ok {
+		t.Error("height behind last delivered block should not report lag")
+	}
+}
+
+func TestInfoSetChainHeight(t *testing.T) {
+	var info Info
+	info.setChainHeight(10)
+	if info.ChainHeight == nil || *info.ChainHeight != 10 || info.Lag != nil {
+		t.Errorf("without delivered block: height = %v, lag = %v", info.ChainHeight, info.Lag)
+	}
+	last := uint64(6)
+	info = Info{LastDeliveredBlock: &last}
+	info.setChainHeight(10)
+	if info.Lag == nil || *info.Lag != 3 {
+		t.Errorf("lag = %v, want 3", info.Lag)
+	}
+}
+
+func TestPausedSubscriptionStartsWithoutSource(t *testing.T) {
+	m := NewManager()
+	sub := newTestSubscription(&recordSink{})
+	from := uint64(8)
+	sub.from, sub.status = &from, StatusPaused
+	opened := make(chan *uint64, 1)
+	sub.open = func(ctx context.Context, from *uint64) (<-chan item, error) {
+		opened <- from
+		return make(chan item), nil
+	}
+	if _, err := m.add(sub); err != nil {
+		t.Fatal(err)
+	}
+	defer sub.close()
+	select {
+	case <-opened:
+		t.Fatal("paused subscription opened its source")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// 恢复投递时从断点之后打开事件来源
+	sub.Resume()
+	select {
+	case got := <-opened:
+		if got == nil || *got != 8 {
+			t.Fatalf("opened from %v, want 8", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("resumed subscription did not open its source")
+	}
+}
@@ -0,0 +1,283 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	grpccodes "google.golang.org/grpc/codes"
+)
+
+// ErrorCode 稳定的机器可读错误码，客户端据此判断是否重试，无需解析错误信息
+type ErrorCode string
+
+const (
+	CodeInvalidRequest           ErrorCode = "INVALID_REQUEST"
+	CodeInvalidProfile           ErrorCode = "INVALID_PROFILE"
+	CodeUnauthenticated          ErrorCode = "UNAUTHENTICATED"
+	CodeNotFound                 ErrorCode = "NOT_FOUND"
+	CodeAccessDenied             ErrorCode = "ACCESS_DENIED"
+	CodeChaincodeError           ErrorCode = "CHAINCODE_ERROR"
+	CodeChaincodeNotFound        ErrorCode = "CHAINCODE_NOT_FOUND"
+	CodeEndorsementMismatch      ErrorCode = "ENDORSEMENT_MISMATCH"
+	CodeEndorsementPolicyFailure ErrorCode = "ENDORSEMENT_POLICY_FAILURE"
+	CodeMVCCReadConflict         ErrorCode = "MVCC_READ_CONFLICT"
+	CodePhantomReadConflict      ErrorCode = "PHANTOM_READ_CONFLICT"
+	CodeTxInvalid                ErrorCode = "TX_INVALID"
+	CodeRateLimited              ErrorCode = "RATE_LIMITED"
+	CodeIdempotencyInProgress    ErrorCode = "IDEMPOTENCY_IN_PROGRESS"
+	CodeIdempotencyKeyReused     ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyUnknown       ErrorCode = "IDEMPOTENCY_OUTCOME_UNKNOWN"
+	CodeTimeout                  ErrorCode = "TIMEOUT"
+	CodePeerUnreachable          ErrorCode = "PEER_UNREACHABLE"
+	CodeNoEndorsers              ErrorCode = "NO_ENDORSERS"
+	CodeFabricError              ErrorCode = "FABRIC_ERROR"
+	CodeInternalError            ErrorCode = "INTERNAL_ERROR"
+)
+
+// ErrorInfo 错误响应中的结构化错误信息
+type ErrorInfo struct {
+	// Status 对应的 HTTP 状态码
+	Status    int             `json:"-"`
+	Code      ErrorCode       `json:"code"`
+	Retryable bool            `json:"retryable"`
+	Fabric    *FabricStatus   `json:"fabric,omitempty"`
+	Chaincode *ChaincodeError `json:"chaincode,omitempty"`
+	Fields    []FieldError    `json:"fields,omitempty"`
+}
+
+// FieldError 请求参数校验失败的字段及原因
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// FabricStatus SDK 返回的原始状态（status.Status 的分组与状态码）
+type FabricStatus struct {
+	Group    string `json:"group"`
+	Code     int32  `json:"code"`
+	CodeName string `json:"codeName"`
+}
+
+// ChaincodeError 合约返回的错误状态与信息
+type ChaincodeError struct {
+	Status  int32  `json:"status"`
+	Message string `json:"message"`
+}
+
+// chaincodeFailurePrefix 节点在合约返回错误时附加的前缀
+const chaincodeFailurePrefix = "transaction returned with failure: "
+
+// NewErrorInfo 按错误码构造错误信息，HTTP 状态码与是否可重试由错误码决定
+func NewErrorInfo(code ErrorCode) *ErrorInfo {
+	info := &ErrorInfo{Code: code}
+	switch code {
+	case CodeInvalidRequest, CodeInvalidProfile:
+		info.Status = http.StatusBadRequest
+	case CodeNotFound, CodeChaincodeNotFound:
+		info.Status = http.StatusNotFound
+	case CodeUnauthenticated:
+		info.Status = http.StatusUnauthorized
+	case CodeAccessDenied:
+		info.Status = http.StatusForbidden
+	case CodeChaincodeError, CodeEndorsementPolicyFailure, CodeTxInvalid, CodeIdempotencyKeyReused:
+		info.Status = http.StatusUnprocessableEntity
+	case CodeEndorsementMismatch, CodeMVCCReadConflict, CodePhantomReadConflict, CodeIdempotencyInProgress:
+		info.Status = http.StatusConflict
+		info.Retryable = true
+	case CodeIdempotencyUnknown:
+		info.Status = http.StatusConflict
+	case CodeRateLimited:
+		info.Status = http.StatusTooManyRequests
+		info.Retryable = true
+	case CodeTimeout:
+		info.Status = http.StatusGatewayTimeout
+		info.Retryable = true
+	case CodePeerUnreachable, CodeNoEndorsers:
+		info.Status = http.StatusServiceUnavailable
+		info.Retryable = true
+	case CodeFabricError:
+		info.Status = http.StatusBadGateway
+	default:
+		info.Status = http.StatusInternalServerError
+	}
+	return info
+}
+
+// codeForStatus 非 SDK 错误按 HTTP 状态码推断错误码
+func codeForStatus(httpStatus int) ErrorCode {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeAccessDenied
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	case http.StatusServiceUnavailable:
+		return CodePeerUnreachable
+	default:
+		return CodeInternalError
+	}
+}
+
+// ClassifyError 将 SDK 返回的错误归类为稳定的错误码
+func ClassifyError(err error) *ErrorInfo {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return NewErrorInfo(CodeTimeout)
+	}
+	s, ok := statusFromError(err)
+	if !ok {
+		return NewErrorInfo(CodeInternalError)
+	}
+	if s.Group == status.ClientStatus && s.Code == status.MultipleErrors.ToInt32() {
+		return classifyMultiple(s)
+	}
+	info := NewErrorInfo(classifyStatus(s))
+	info.Fabric = &FabricStatus{
+		Group:    s.Group.String(),
+		Code:     s.Code,
+		CodeName: statusCodeName(s),
+	}
+	if info.Code == CodeChaincodeError {
+		info.Chaincode = &ChaincodeError{Status: s.Code, Message: chaincodeMessage(s.Message)}
+	}
+	return info
+}
+
+// statusFromError 从错误链中取出 SDK 状态，多个节点的错误合并为 MultipleErrors 状态
+func statusFromError(err error) (*status.Status, bool) {
+	var s *status.Status
+	if errors.As(err, &s) {
+		return s, true
+	}
+	var m multi.Errors
+	if errors.As(err, &m) {
+		return status.FromError(m)
+	}
+	return nil, false
+}
+
+// classifyMultiple 多个节点同时返回错误时，取第一个可识别的错误
+func classifyMultiple(s *status.Status) *ErrorInfo {
+	for _, detail := range s.Details {
+		err, ok := detail.(error)
+		if !ok {
+			continue
+		}
+		if info := ClassifyError(err); info.Code != CodeInternalError {
+			return info
+		}
+	}
+	return NewErrorInfo(CodeFabricError)
+}
+
+func classifyStatus(s *status.Status) ErrorCode {
+	switch s.Group {
+	case status.EventServerStatus:
+		return classifyValidationCode(pb.TxValidationCode(s.Code))
+	case status.GRPCTransportStatus:
+		switch grpccodes.Code(s.Code) {
+		case grpccodes.Unavailable:
+			return CodePeerUnreachable
+		case grpccodes.DeadlineExceeded:
+			return CodeTimeout
+		case grpccodes.PermissionDenied, grpccodes.Unauthenticated:
+			return CodeAccessDenied
+		}
+	case status.ChaincodeStatus, status.EndorserServerStatus:
+		if isAccessDenied(s.Message) {
+			return CodeAccessDenied
+		}
+		if isNotFound(s.Message) {
+			return CodeNotFound
+		}
+		if s.Group == status.ChaincodeStatus {
+			return CodeChaincodeError
+		}
+	case status.EndorserClientStatus, status.OrdererClientStatus, status.ClientStatus, status.DiscoveryServerStatus:
+		switch status.Code(s.Code) {
+		case status.EndorsementMismatch:
+			return CodeEndorsementMismatch
+		case status.ConnectionFailed:
+			return CodePeerUnreachable
+		case status.Timeout:
+			return CodeTimeout
+		case status.NoPeersFound, status.QueryEndorsers:
+			return CodeNoEndorsers
+		case status.ChaincodeNameNotFound:
+			return CodeChaincodeNotFound
+		}
+	case status.OrdererServerStatus:
+		if s.Code == int32(common.Status_SERVICE_UNAVAILABLE) {
+			return CodePeerUnreachable
+		}
+	}
+	return CodeFabricError
+}
+
+// classifyValidationCode 按交易校验码区分读写冲突与背书策略失败
+func classifyValidationCode(code pb.TxValidationCode) ErrorCode {
+	switch code {
+	case pb.TxValidationCode_MVCC_READ_CONFLICT:
+		return CodeMVCCReadConflict
+	case pb.TxValidationCode_PHANTOM_READ_CONFLICT:
+		return CodePhantomReadConflict
+	case pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE:
+		return CodeEndorsementPolicyFailure
+	default:
+		return CodeTxInvalid
+	}
+}
+
+// statusCodeName 返回状态码在所属分组中的名称
+func statusCodeName(s *status.Status) string {
+	switch s.Group {
+	case status.GRPCTransportStatus:
+		return grpccodes.Code(s.Code).String()
+	case status.EndorserServerStatus, status.OrdererServerStatus:
+		return status.ToFabricCommonStatusCode(s.Code).String()
+	case status.EventServerStatus:
+		return pb.TxValidationCode(s.Code).String()
+	case status.EndorserClientStatus, status.OrdererClientStatus, status.ClientStatus, status.DiscoveryServerStatus:
+		return status.ToSDKStatusCode(s.Code).String()
+	default:
+		return ""
+	}
+}
+
+// isAccessDenied 节点拒绝提案时只返回 500 与错误信息，需按信息判断
+func isAccessDenied(message string) bool {
+	return strings.Contains(message, "access denied")
+}
+
+// isNotFound 账本查询不到交易或区块时的错误信息
+func isNotFound(message string) bool {
+	return strings.Contains(message, "Entry not found in index") ||
+		strings.Contains(message, "no such transaction ID") ||
+		strings.Contains(message, "no such block number")
+}
+
+// chaincodeMessage 去掉节点附加的前缀，返回合约原始错误信息
+func chaincodeMessage(message string) string {
+	if i := strings.LastIndex(message, chaincodeFailurePrefix); i >= 0 {
+		return message[i+len(chaincodeFailurePrefix):]
+	}
+	return message
+}
+
+// withStatus 使用调用方指定的 HTTP 状态码
+func (e *ErrorInfo) withStatus(httpStatus int) *ErrorInfo {
+	e.Status = httpStatus
+	return e
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	pkgerrors "github.com/pkg/errors"
+	grpccodes "google.golang.org/grpc/codes"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      ErrorCode
+		status    int
+		retryable bool
+	}{
+		{"endorsement mismatch", status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), "ProposalResponsePayloads do not match", nil), CodeEndorsementMismatch, http.StatusConflict, true},
+		{"chaincode error", status.New(status.ChaincodeStatus, 500, "error in simulation: transaction returned with failure: insufficient balance", nil), CodeChaincodeError, http.StatusUnprocessableEntity, false},
+		{"chaincode not found", status.New(status.EndorserClientStatus, status.ChaincodeNameNotFound.ToInt32(), "make sure the chaincode mycc has been successfully defined", nil), CodeChaincodeNotFound, http.StatusNotFound, false},
+		{"mvcc conflict", status.New(status.EventServerStatus, int32(pb.TxValidationCode_MVCC_READ_CONFLICT), "received invalid transaction", nil), CodeMVCCReadConflict, http.StatusConflict, true},
+		{"phantom read", status.New(status.EventServerStatus, int32(pb.TxValidationCode_PHANTOM_READ_CONFLICT), "received invalid transaction", nil), CodePhantomReadConflict, http.StatusConflict, true},
+		{"policy failure", status.New(status.EventServerStatus, int32(pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE), "received invalid transaction", nil), CodeEndorsementPolicyFailure, http.StatusUnprocessableEntity, false},
+		{"invalid tx", status.New(status.EventServerStatus, int32(pb.TxValidationCode_BAD_PAYLOAD), "received invalid transaction", nil), CodeTxInvalid, http.StatusUnprocessableEntity, false},
+		{"sdk timeout", status.New(status.ClientStatus, status.Timeout.ToInt32(), "request timed out", nil), CodeTimeout, http.StatusGatewayTimeout, true},
+		{"context deadline", pkgerrors.WithMessage(context.DeadlineExceeded, "query failed"), CodeTimeout, http.StatusGatewayTimeout, true},
+		{"connection failed", status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "connection refused", nil), CodePeerUnreachable, http.StatusServiceUnavailable, true},
+		{"grpc unavailable", pkgerrors.WithMessage(status.New(status.GRPCTransportStatus, int32(grpccodes.Unavailable), "transport is closing", nil), "connection failed"), CodePeerUnreachable, http.StatusServiceUnavailable, true},
+		{"access denied", status.New(status.ChaincodeStatus, 500, "access denied: channel [mychannel] creator org [Org1MSP]", nil), CodeAccessDenied, http.StatusForbidden, false},
+		{"no peers", status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "no targets available", nil), CodeNoEndorsers, http.StatusServiceUnavailable, true},
+		{"tx not found", status.New(status.ChaincodeStatus, 500, "Failed to get transaction with id abc, error Entry not found in index", nil), CodeNotFound, http.StatusNotFound, false},
+		{"plain error", errors.New("boom"), CodeInternalError, http.StatusInternalServerError, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := ClassifyError(tt.err)
+			if info.Code != tt.code || info.Status != tt.status || info.Retryable != tt.retryable {
+				t.Fatalf("got %s/%d/%v, want %s/%d/%v", info.Code, info.Status, info.Retryable, tt.code, tt.status, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorDetails(t *testing.T) {
+	info := ClassifyError(status.New(status.ChaincodeStatus, 500, "error in simulation: transaction returned with failure: insufficient balance", nil))
+	if info.Chaincode == nil || info.Chaincode.Status != 500 || info.Chaincode.Message != "insufficient balance" {
+		t.Fatalf("unexpected chaincode error %+v", info.Chaincode)
+	}
+
+	info = ClassifyError(status.New(status.EventServerStatus, int32(pb.TxValidationCode_MVCC_READ_CONFLICT), "received invalid transaction", nil))
+	if info.Fabric == nil || info.Fabric.Group != status.EventServerStatus.String() || info.Fabric.CodeName != "MVCC_READ_CONFLICT" {
+		t.Fatalf("unexpected fabric status %+v", info.Fabric)
+	}
+}
+
+func TestClassifyMultipleErrors(t *testing.T) {
+	err := multi.New(
+		errors.New("first endorser failed"),
+		status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "connection refused", nil),
+	)
+	if info := ClassifyError(pkgerrors.WithMessage(err, "Query failed")); info.Code != CodePeerUnreachable {
+		t.Fatalf("got %s, want %s", info.Code, CodePeerUnreachable)
+	}
+}
+
+func TestResponseStatus(t *testing.T) {
+	rec := httptest.NewRecorder()
+	FabricError(rec, status.New(status.EventServerStatus, int32(pb.TxValidationCode_MVCC_READ_CONFLICT), "received invalid transaction", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("got HTTP %d, want %d", rec.Code, http.StatusConflict)
+	}
+	var resp Response
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ErrorCode != http.StatusConflict || resp.Error == nil || resp.Error.Code != CodeMVCCReadConflict || !resp.Error.Retryable {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	Success(rec, "ok")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got HTTP %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec = httptest.NewRecorder()
+	BadRequest(rec, "Invalid request body")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got HTTP %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -11,8 +11,8 @@ import (
 func InitializeSDKBySdkId(sdkConfig string, gm, sm3 bool) (error, *service.Fabric2Service) {
 	// 获取全局配置中的 Fabric 网络信息
 	//计算sdkConfig的md5
-	sdkId := fmt.Sprintf("%x", MD5Hash(sdkConfig))
-	if sdk, ok := service.Fabric2ServicePool[sdkId]; ok {
+	sdkId := SdkId(sdkConfig)
+	if sdk := service.GetFabric2Service(sdkId); sdk != nil {
 		//log.Printf("SDK already initialized  wait: %s", sdkId)
 		return nil, sdk
 	}
@@ -21,14 +21,38 @@ func InitializeSDKBySdkId(sdkConfig string, gm, sm3 bool) (error, *service.Fabri
 		return err, nil
 	}
 	// 从池中获取刚刚初始化的实例
-	sdk, ok := service.Fabric2ServicePool[sdkId]
-	if !ok {
+	sdk := service.GetFabric2Service(sdkId)
+	if sdk == nil {
 		return errors.New("failed to retrieve initialized service from pool"), nil
 	}
 
 	return nil, sdk
 }
 
+// ErrSDKNotInitialized sdkId 对应的 SDK 尚未初始化
+var ErrSDKNotInitialized = errors.New("sdk not initialized")
+
+// SDKForStream 优先使用 sdkId 引用已初始化的 SDK，否则按 sdkConfig 初始化
+func SDKForStream(sdkId, sdkConfig string, gm, sm3 bool) (*service.Fabric2Service, error) {
+	if sdkId != "" {
+		sdk := service.GetFabric2Service(sdkId)
+		if sdk == nil {
+			return nil, ErrSDKNotInitialized
+		}
+		return sdk, nil
+	}
+	err, sdk := InitializeSDKBySdkId(sdkConfig, gm, sm3)
+	if err != nil {
+		return nil, err
+	}
+	return sdk, nil
+}
+
+// SdkId 连接配置对应的 SDK 标识，用于连接池、订阅标识与授权规则
+func SdkId(sdkConfig string) string {
+	return fmt.Sprintf("%x", MD5Hash(sdkConfig))
+}
+
 func MD5Hash(input string) string {
 	hasher := md5.New()
 	_, _ = io.WriteString(hasher, input)
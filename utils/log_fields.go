@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/qctc/fabric2-api-server/logging"
+	"github.com/qctc/fabric2-api-server/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogRequestFields 将请求涉及的连接配置（sdkId）、通道、合约与方法加入请求日志字段与当前 span，
+// 连接配置本身不会写入日志；sdkConfig 为空时按 sdkId 查找已初始化的 SDK
+func LogRequestFields(ctx context.Context, sdkConfig, sdkId, chaincode, function string) {
+	var channels []string
+	if sdkConfig != "" {
+		sdkId = SdkId(sdkConfig)
+	}
+	if sdk := service.GetFabric2Service(sdkId); sdk != nil {
+		channels, _ = sdk.Channels()
+	} else if sdkConfig != "" {
+		channels, _ = service.ProfileChannels(sdkConfig)
+	}
+
+	var attrs []slog.Attr
+	var spanAttrs []attribute.KeyValue
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, slog.String(key, value))
+			spanAttrs = append(spanAttrs, attribute.String("fabric."+key, value))
+		}
+	}
+	add("profile", sdkId)
+	add("channel", strings.Join(channels, ","))
+	add("chaincode", chaincode)
+	add("function", function)
+	logging.AddFields(ctx, attrs...)
+	trace.SpanFromContext(ctx).SetAttributes(spanAttrs...)
+}
+
+// LogTxId 将交易 ID 加入请求日志字段与当前 span
+func LogTxId(ctx context.Context, txId string) {
+	if txId == "" {
+		return
+	}
+	logging.AddFields(ctx, slog.String("txId", txId))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("fabric.tx_id", txId))
+}
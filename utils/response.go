@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 )
 
@@ -10,6 +12,7 @@ type Response struct {
 	ErrorCode int         `json:"errorCode"`
 	Message   string      `json:"message"`
 	Data      interface{} `json:"data"`
+	Error     *ErrorInfo  `json:"error,omitempty"`
 }
 
 // Success 返回成功响应
@@ -36,18 +39,76 @@ func InternalServerError(w http.ResponseWriter, err error) {
 	ResponseJSON(w, http.StatusInternalServerError, err.Error(), nil)
 }
 
+// InvalidProfile 连接配置无法初始化 SDK 时返回400错误
+func InvalidProfile(w http.ResponseWriter, err error) {
+	writeError(w, NewErrorInfo(CodeInvalidProfile), "sdk Initialize error "+err.Error(), nil)
+}
+
+// ValidationError 请求参数校验失败时返回400错误及字段级别的错误信息
+func ValidationError(w http.ResponseWriter, message string, fields []FieldError) {
+	info := NewErrorInfo(CodeInvalidRequest)
+	info.Fields = fields
+	writeError(w, info, message, nil)
+}
+
+// FabricError 按 SDK 返回的错误状态选择 HTTP 状态码与错误码
+func FabricError(w http.ResponseWriter, err error) {
+	writeError(w, ClassifyError(err), err.Error(), nil)
+}
+
+// ErrorWithInfo 按已分类的错误信息返回错误响应
+func ErrorWithInfo(w http.ResponseWriter, info *ErrorInfo, message string, data interface{}) {
+	writeError(w, info, message, data)
+}
+
 // ResponseJSON 返回JSON响应
 func ResponseJSON(w http.ResponseWriter, code int, message string, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	errCode := 0
 	if code != http.StatusOK {
-		errCode = code
+		writeError(w, NewErrorInfo(codeForStatus(code)).withStatus(code), message, data)
+		return
 	}
-	json.NewEncoder(w).Encode(Response{
+	writeResponse(w, code, Response{
+		Version: "1",
+		Message: message,
+		Data:    data,
+	})
+}
+
+func writeError(w http.ResponseWriter, info *ErrorInfo, message string, data interface{}) {
+	writeResponse(w, info.Status, Response{
 		Version:   "1",
-		ErrorCode: errCode,
+		ErrorCode: info.Status,
 		Message:   message,
 		Data:      data,
+		Error:     info,
 	})
 }
+
+func writeResponse(w http.ResponseWriter, code int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// DecodeJSON 解析 JSON 请求体，拒绝未定义的字段
+func DecodeJSON(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// PeekJSON 解析请求体后恢复请求体，供中间件在处理函数之前读取请求参数；请求体为空时不做解析
+func PeekJSON(r *http.Request, v interface{}) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, v)
+}
@@ -83,27 +83,46 @@ func UnmarshalBlock(txBytes []byte) ([]define.EventData, error) {
 
 }
 
-func GetEventByte(block *common.Block, chainName, chaincodeName, chainId string) ([]define.EventByteData, error) {
-	var eventRes define.EventRes
-	var eventBytes []define.EventByteData
-	eventData, err := UnmarshalBlock(block.Data.Data[0])
-	if err != nil {
-		return nil, err
+// ParseBlockTransactions 解析区块内全部交易的交易ID、验证结果与合约事件
+func ParseBlockTransactions(block *common.Block) ([]define.TxData, error) {
+	var txFilter []byte
+	if metadata := block.GetMetadata().GetMetadata(); len(metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		txFilter = metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
 	}
-	for _, v := range eventData {
-		eventRes.Path = "cross." + chainName + "." + chaincodeName
-		eventRes.EventData = v.Payload
-		eventRes.TxId = v.TxId
-		eventRes.ChaincodeName = v.ChaincodeId
-		eventRes.BlockHeight = block.GetHeader().GetNumber()
-		eventRes.ChainId = chainId
-		eventRes.Topic = v.EventName
-		eventByte, _ := json.Marshal(eventRes)
-		eventBytes = append(eventBytes, define.EventByteData{
-			ChaincodeId: v.ChaincodeId,
-			EventName:   v.EventName,
-			EventByte:   eventByte,
-		})
+
+	txs := make([]define.TxData, 0, len(block.GetData().GetData()))
+	for i, envBytes := range block.GetData().GetData() {
+		env := &common.Envelope{}
+		if err := proto.Unmarshal(envBytes, env); err != nil {
+			return nil, err
+		}
+		payload := &common.Payload{}
+		if err := proto.Unmarshal(env.Payload, payload); err != nil {
+			return nil, err
+		}
+		chHeader := &common.ChannelHeader{}
+		if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), chHeader); err != nil {
+			return nil, err
+		}
+
+		code := pb.TxValidationCode_VALID
+		if i < len(txFilter) {
+			code = pb.TxValidationCode(txFilter[i])
+		}
+		tx := define.TxData{
+			Index:          i,
+			TxId:           chHeader.TxId,
+			ValidationCode: int32(code),
+			ValidationName: code.String(),
+		}
+		if common.HeaderType(chHeader.Type) == common.HeaderType_ENDORSER_TRANSACTION {
+			events, err := UnmarshalBlock(envBytes)
+			if err != nil {
+				return nil, err
+			}
+			tx.Events = events
+		}
+		txs = append(txs, tx)
 	}
-	return eventBytes, nil
+	return txs, nil
 }